@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// AuthController はユーザー登録・ログイン（/api/auth）のHTTPハンドラです。
+type AuthController struct {
+	Auth *usecase.Auth
+}
+
+func NewAuthController(a *usecase.Auth) *AuthController {
+	return &AuthController{Auth: a}
+}
+
+func (c *AuthController) SignUp(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewAuthSignUp(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Auth.SignUp(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewAuthLogin(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Auth.Login(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewAuthLogout(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	if err := c.Auth.Logout(r.Context(), req); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *AuthController) Me(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewAuthMe(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Auth.Me(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *AuthController) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewAuthMeUpdate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Auth.UpdateMe(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -2,12 +2,11 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
-	"github.com/sora-00/booktracker-api/app/domain/repository"
 	"github.com/sora-00/booktracker-api/app/usecase"
 	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
 )
 
 type BookController struct {
@@ -21,12 +20,27 @@ func NewBookController(b *usecase.Book) *BookController {
 func (c *BookController) GetBooks(w http.ResponseWriter, r *http.Request) {
 	req, err := request.NewBookGet(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeRequestError(w, r, err)
 		return
 	}
 	res, err := c.Book.Get(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *BookController) SearchBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookSearch(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.Search(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -36,18 +50,15 @@ func (c *BookController) GetBooks(w http.ResponseWriter, r *http.Request) {
 func (c *BookController) GetBookByID(w http.ResponseWriter, r *http.Request) {
 	req, err := request.NewBookGetByID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeRequestError(w, r, err)
 		return
 	}
 	res, err := c.Book.GetByID(r.Context(), req)
 	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			http.Error(w, "book not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", response.BookETag(res.Book))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
@@ -55,14 +66,15 @@ func (c *BookController) GetBookByID(w http.ResponseWriter, r *http.Request) {
 func (c *BookController) CreateBook(w http.ResponseWriter, r *http.Request) {
 	req, err := request.NewBookCreate(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeRequestError(w, r, err)
 		return
 	}
 	res, err := c.Book.Create(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", response.BookETag(res.Book))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
@@ -70,18 +82,32 @@ func (c *BookController) CreateBook(w http.ResponseWriter, r *http.Request) {
 func (c *BookController) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	req, err := request.NewBookUpdate(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeRequestError(w, r, err)
 		return
 	}
 	res, err := c.Book.Update(r.Context(), req)
 	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			http.Error(w, "book not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", response.BookETag(res.Book))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// PatchBook は Content-Type が application/merge-patch+json か application/json-patch+json の部分更新。
+func (c *BookController) PatchBook(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookPatch(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.Patch(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", response.BookETag(res.Book))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
@@ -89,17 +115,30 @@ func (c *BookController) UpdateBook(w http.ResponseWriter, r *http.Request) {
 func (c *BookController) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	req, err := request.NewBookDelete(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeRequestError(w, r, err)
 		return
 	}
 	_, err = c.Book.Delete(r.Context(), req)
 	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			http.Error(w, "book not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// MergeBooks は sourceId の本を targetId の本にまとめる。
+func (c *BookController) MergeBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookMerge(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.Merge(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", response.BookETag(res.Book))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// BatchCreateBooks は本をまとめて登録する。項目ごとの結果は results に入れ、全体は 200 で返す。
+func (c *BookController) BatchCreateBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookBatchCreate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.BatchCreate(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	writeBookBatch(w, r, res, http.StatusCreated)
+}
+
+// BatchUpdateBooks は本をまとめて更新する。
+func (c *BookController) BatchUpdateBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookBatchUpdate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.BatchUpdate(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	writeBookBatch(w, r, res, http.StatusOK)
+}
+
+// BatchDeleteBooks は本をまとめてゴミ箱に入れる。
+func (c *BookController) BatchDeleteBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookBatchDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.BatchDelete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	writeBookBatch(w, r, res, http.StatusNoContent)
+}
+
+// writeBookBatch は項目ごとの status を埋めて返す。成功した項目は1冊ずつの API と同じ ok、失敗した項目は WriteError と同じ problem にする。
+func writeBookBatch(w http.ResponseWriter, r *http.Request, res *response.BookBatch, ok int) {
+	for i := range res.Results {
+		item := &res.Results[i]
+		if item.Err == nil {
+			item.Status = ok
+			continue
+		}
+		item.Error = itemProblem(r, item.Err)
+		item.Status = item.Error.Status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// itemProblem は一括操作・取り込みの項目ごとのエラーを WriteError と同じ problem にする。
+// 項目のエラーなので instance（リクエストのパス）は付けない。
+func itemProblem(r *http.Request, err error) *response.Problem {
+	p := errorProblem(r, err)
+	p.Instance = ""
+	return p
+}
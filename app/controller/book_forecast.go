@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// BookForecastController は読了見込み（/api/books/{id}/forecast）のHTTPハンドラです。
+type BookForecastController struct {
+	BookForecast *usecase.BookForecast
+}
+
+func NewBookForecastController(f *usecase.BookForecast) *BookForecastController {
+	return &BookForecastController{BookForecast: f}
+}
+
+func (c *BookForecastController) GetForecast(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookForecast(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.BookForecast.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
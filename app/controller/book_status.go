@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// BookStatusController は本の status 遷移（/api/books/{id}/status）と履歴のHTTPハンドラです。
+type BookStatusController struct {
+	BookStatus *usecase.BookStatus
+}
+
+func NewBookStatusController(s *usecase.BookStatus) *BookStatusController {
+	return &BookStatusController{BookStatus: s}
+}
+
+func (c *BookStatusController) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookStatusChange(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.BookStatus.Change(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *BookStatusController) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewBookStatusHistory(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.BookStatus.History(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
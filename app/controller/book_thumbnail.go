@@ -1,143 +1,123 @@
 package controller
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
-	"log"
 	"net/http"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/infra/imaging"
+	"github.com/sora-00/booktracker-api/app/infra/storage"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
 )
 
-// 本の表紙画像の保存先（本の表紙専用であることが分かるように）
-const bookThumbnailDir = "uploads/thumbnails"
+// maxMultipartOverhead は表紙画像のアップロードで、画像のほかに受け付ける multipart の境界・ヘッダーの大きさ。
+const maxMultipartOverhead = 64 << 10
 
 // BookThumbnailController は本の表紙画像アップロード用のHTTPハンドラです。
-// 当面は認証なし。のちにログイン必須に変更する。
-type BookThumbnailController struct{}
+// アップロードはログイン必須。配信は <img> から読めるように認証なし（main.go のルーティングで分けている）。
+// 保存先は ThumbnailStore で差し替える（ローカルディスク / S3 互換ストレージ）。
+// アップロードした画像は Thumbnail として記録し、本の作成・更新で thumbnailId を指定して付ける。
+type BookThumbnailController struct {
+	store      storage.ThumbnailStore
+	thumbnails *service.ThumbnailSvc
+}
 
-func NewBookThumbnailController() *BookThumbnailController {
-	return &BookThumbnailController{}
+func NewBookThumbnailController(store storage.ThumbnailStore, thumbnails *service.ThumbnailSvc) *BookThumbnailController {
+	return &BookThumbnailController{store: store, thumbnails: thumbnails}
 }
 
-// PostThumbnail は本の表紙画像を multipart/form-data で受け取り保存し、{ id, url } を返す。
+// PostThumbnail は本の表紙画像を multipart/form-data で受け取り、サイズ違い（list / detail / original）に
+// 再エンコードして保存し、{ id, url, variants } を返す。url は original の URL で、保存先の ThumbnailStore が決める。
+// 画像形式はファイル名ではなく中身の先頭バイトで判定し、画像でなければ 415 を返す。
+// id は本の thumbnailId に指定する。どの本にも付けないままだと猶予期間の後に GC で消える。
 func (c *BookThumbnailController) PostThumbnail(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		WriteProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	// 10MB 制限
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "failed to parse multipart form", http.StatusBadRequest)
+	// 10MB 制限。multipart の境界やヘッダーの分だけ余裕を持たせ、画像そのものの大きさは imaging.Process で確かめる
+	r.Body = http.MaxBytesReader(w, r.Body, imaging.MaxFileSize+maxMultipartOverhead)
+	if err := r.ParseMultipartForm(imaging.MaxFileSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteProblem(w, r, http.StatusRequestEntityTooLarge, CodeThumbnailTooLarge, "request body is larger than 10MB")
+			return
+		}
+		WriteProblem(w, r, http.StatusBadRequest, CodeMalformedBody, "failed to parse multipart form")
 		return
 	}
 
-	file, header, err := r.FormFile("file")
+	file, _, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "file is required", http.StatusBadRequest)
+		writeRequestError(w, r, request.InvalidField("file", "file is required"))
 		return
 	}
 	defer file.Close()
 
-	ext := filepath.Ext(header.Filename)
-	if ext == "" {
-		ext = ".jpg"
-	}
-	ext = strings.ToLower(ext)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".gif" && ext != ".webp" {
-		ext = ".jpg"
-	}
-
-	id, err := randomID()
-	if err != nil {
-		http.Error(w, "failed to generate id", http.StatusInternalServerError)
-		return
-	}
-	name := id + ext
-
-	if err := os.MkdirAll(bookThumbnailDir, 0755); err != nil {
-		log.Printf("book_thumbnail: mkdir %s: %v", bookThumbnailDir, err)
-		http.Error(w, "failed to create upload dir", http.StatusInternalServerError)
-		return
-	}
-
-	path := filepath.Join(bookThumbnailDir, name)
-	dst, err := os.Create(path)
+	// 画像でない・大きすぎるものは 415 / 413、それ以外は 500
+	thumbnail, variants, err := c.thumbnails.Upload(r.Context(), file)
 	if err != nil {
-		log.Printf("book_thumbnail: create %s: %v", path, err)
-		http.Error(w, "failed to save file", http.StatusInternalServerError)
-		return
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		os.Remove(path)
-		log.Printf("book_thumbnail: copy: %v", err)
-		http.Error(w, "failed to save file", http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
-	scheme := "http"
-	if r.TLS != nil {
-		scheme = "https"
-	}
-	url := scheme + "://" + r.Host + "/api/books/thumbnails/" + name
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"id": id, "url": url})
+	json.NewEncoder(w).Encode(response.NewBookThumbnailUpload(thumbnail.ID, thumbnail.URL, variants))
 }
 
-// GetThumbnail は保存した本の表紙画像を返す。
+// GetThumbnail は保存した本の表紙画像を返す。?size=list / detail / original（既定）でサイズを選ぶ。
+// サイズ違いのない古い画像は original を返す。
 func (c *BookThumbnailController) GetThumbnail(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" || strings.Contains(id, "/") || strings.Contains(id, "..") {
-		http.Error(w, "not found", http.StatusNotFound)
+		WriteError(w, r, storage.ErrNotFound)
 		return
 	}
 
-	path := filepath.Join(bookThumbnailDir, id)
-	f, err := os.Open(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			http.Error(w, "not found", http.StatusNotFound)
+	variant := imaging.VariantOriginal
+	if size := r.URL.Query().Get("size"); size != "" {
+		v, ok := findVariant(size)
+		if !ok {
+			writeRequestError(w, r, request.InvalidField("size", "size must be list, detail, or original"))
 			return
 		}
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
+		variant = v
 	}
-	defer f.Close()
 
-	info, err := f.Stat()
-	if err != nil || info.IsDir() {
-		http.Error(w, "not found", http.StatusNotFound)
+	ext := filepath.Ext(id)
+	name := service.ThumbnailFileName(strings.TrimSuffix(id, ext), variant, ext)
+	f, info, err := c.store.Get(r.Context(), name)
+	if errors.Is(err, storage.ErrNotFound) && name != id {
+		f, info, err = c.store.Get(r.Context(), id)
+	}
+	if err != nil {
+		WriteError(w, r, err)
 		return
 	}
+	defer f.Close()
 
-	ext := strings.ToLower(filepath.Ext(id))
-	switch ext {
-	case ".png":
-		w.Header().Set("Content-Type", "image/png")
-	case ".gif":
-		w.Header().Set("Content-Type", "image/gif")
-	case ".webp":
-		w.Header().Set("Content-Type", "image/webp")
-	default:
-		w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Content-Type", info.ContentType)
+	if info.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
 	}
 	w.Header().Set("Cache-Control", "public, max-age=86400")
 	io.Copy(w, f)
 }
 
-func randomID() (string, error) {
-	b := make([]byte, 16)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
+func findVariant(name string) (imaging.Variant, bool) {
+	for _, v := range imaging.Variants {
+		if v.Name == name {
+			return v, true
+		}
 	}
-	return hex.EncodeToString(b), nil
+	return imaging.Variant{}, false
 }
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/infra/imaging"
+	"github.com/sora-00/booktracker-api/app/infra/lookup"
+	"github.com/sora-00/booktracker-api/app/infra/storage"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// エラーの code。クライアントはメッセージではなくこれで分岐する（一度決めたら変えない）。
+const (
+	CodeValidationFailed        = "validation_failed"
+	CodeMalformedBody           = "malformed_body"
+	CodeInvalidPageToken        = "invalid_page_token"
+	CodeUnauthenticated         = "unauthenticated"
+	CodeInvalidCredentials      = "invalid_credentials"
+	CodeBookNotFound            = "book_not_found"
+	CodeSessionNotFound         = "session_not_found"
+	CodeTrashNotFound           = "trash_not_found"
+	CodeShelfNotFound           = "shelf_not_found"
+	CodeTagNotFound             = "tag_not_found"
+	CodeShelfNameTaken          = "shelf_name_taken"
+	CodeTagNameTaken            = "tag_name_taken"
+	CodeUnknownShelf            = "unknown_shelf"
+	CodeReviewNotFound          = "review_not_found"
+	CodeBookNotCompleted        = "book_not_completed"
+	CodeHighlightNotFound       = "highlight_not_found"
+	CodeHighlightPageOutOfRange = "highlight_page_out_of_range"
+	CodeGoalNotFound            = "goal_not_found"
+	CodeInvalidDateRange        = "invalid_date_range"
+	CodeISBNNotFound            = "isbn_not_found"
+	CodeMetadataUnavailable     = "metadata_unavailable"
+	CodeDuplicateBook           = "duplicate_book"
+	CodeMergeSameBook           = "merge_same_book"
+	CodeBatchAborted            = "batch_aborted"
+	CodeImportJobNotFound       = "import_job_not_found"
+	CodeThumbnailNotFound       = "thumbnail_not_found"
+	CodeRouteNotFound           = "route_not_found"
+	CodeMethodNotAllowed        = "method_not_allowed"
+	CodeEmailTaken              = "email_taken"
+	CodeThumbnailInUse          = "thumbnail_in_use"
+	CodeInvalidTransition       = "invalid_transition"
+	CodePreconditionFailed      = "precondition_failed"
+	CodePreconditionRequired    = "precondition_required"
+	CodePatchTestFailed         = "patch_test_failed"
+	CodeSessionPageOutOfRange   = "session_page_out_of_range"
+	CodeThumbnailTooLarge       = "thumbnail_too_large"
+	CodeUnsupportedMediaType    = "unsupported_media_type"
+	CodeInternal                = "internal_error"
+)
+
+// errorMapping はドメインのエラーと HTTP のステータス・code の対応。上から順に errors.Is で探す。
+// ErrSessionNotFound などは ErrNotFound を包んでいるので、ErrNotFound より前に置く。
+var errorMapping = []struct {
+	err    error
+	status int
+	code   string
+	detail string // 空なら err.Error()
+}{
+	{repository.ErrInvalidPageToken, http.StatusBadRequest, CodeInvalidPageToken, ""},
+	{repository.ErrSessionNotFound, http.StatusNotFound, CodeSessionNotFound, ""},
+	{repository.ErrTrashNotFound, http.StatusNotFound, CodeTrashNotFound, ""},
+	{repository.ErrShelfNotFound, http.StatusNotFound, CodeShelfNotFound, ""},
+	{repository.ErrTagNotFound, http.StatusNotFound, CodeTagNotFound, ""},
+	{repository.ErrReviewNotFound, http.StatusNotFound, CodeReviewNotFound, ""},
+	{repository.ErrHighlightNotFound, http.StatusNotFound, CodeHighlightNotFound, ""},
+	{repository.ErrGoalNotFound, http.StatusNotFound, CodeGoalNotFound, ""},
+	{repository.ErrImportJobNotFound, http.StatusNotFound, CodeImportJobNotFound, ""},
+	{repository.ErrNotFound, http.StatusNotFound, CodeBookNotFound, "book not found"},
+	{repository.ErrEmailTaken, http.StatusConflict, CodeEmailTaken, ""},
+	{repository.ErrThumbnailInUse, http.StatusConflict, CodeThumbnailInUse, ""},
+	{repository.ErrShelfNameTaken, http.StatusConflict, CodeShelfNameTaken, ""},
+	{repository.ErrTagNameTaken, http.StatusConflict, CodeTagNameTaken, ""},
+	{service.ErrUnknownShelf, http.StatusBadRequest, CodeUnknownShelf, ""},
+	{service.ErrBookNotCompleted, http.StatusConflict, CodeBookNotCompleted, ""},
+	{service.ErrSessionPageOutOfRange, http.StatusBadRequest, CodeSessionPageOutOfRange, ""},
+	{service.ErrHighlightPageOutOfRange, http.StatusBadRequest, CodeHighlightPageOutOfRange, ""},
+	{service.ErrInvalidDateRange, http.StatusBadRequest, CodeInvalidDateRange, ""},
+	{service.ErrDuplicateBook, http.StatusConflict, CodeDuplicateBook, ""},
+	{service.ErrMergeSameBook, http.StatusBadRequest, CodeMergeSameBook, ""},
+	{service.ErrBatchAborted, http.StatusFailedDependency, CodeBatchAborted, ""},
+	{service.ErrPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed, ""},
+	{service.ErrPreconditionRequired, http.StatusPreconditionRequired, CodePreconditionRequired, ""},
+	{request.ErrPatchTestFailed, http.StatusConflict, CodePatchTestFailed, ""},
+	{service.ErrInvalidTransition, http.StatusConflict, CodeInvalidTransition, ""},
+	{service.ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials, ""},
+	{service.ErrUnauthenticated, http.StatusUnauthorized, CodeUnauthenticated, ""},
+	{service.ErrThumbnailNotFound, http.StatusBadRequest, CodeThumbnailNotFound, ""},
+	{storage.ErrNotFound, http.StatusNotFound, CodeThumbnailNotFound, ""},
+	{lookup.ErrNotFound, http.StatusNotFound, CodeISBNNotFound, ""},
+	{lookup.ErrUnavailable, http.StatusBadGateway, CodeMetadataUnavailable, ""},
+	{imaging.ErrNotImage, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, ""},
+	{imaging.ErrImageTooLarge, http.StatusRequestEntityTooLarge, CodeThumbnailTooLarge, ""},
+	{imaging.ErrFileTooLarge, http.StatusRequestEntityTooLarge, CodeThumbnailTooLarge, ""},
+}
+
+// WriteError は usecase・service から返ったエラーを problem+json で返す。
+// *service.DuplicateError なら candidates に同じらしい本を付ける。対応表にないエラーは 500 にし、中身はログにだけ出す。
+// PATCH のように入力を今の本に当ててから確かめるときの *request.ValidationError は writeRequestError と同じく 400 にする。
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	p := errorProblem(r, err)
+	if p.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	writeProblem(w, p)
+}
+
+// errorProblem は WriteError で返す problem を作る。一括操作の項目ごとのエラーにも使う。
+func errorProblem(r *http.Request, err error) *response.Problem {
+	var verr *request.ValidationError
+	if errors.As(err, &verr) {
+		return requestProblem(r, err)
+	}
+	var dup *service.DuplicateError
+	if errors.As(err, &dup) {
+		p := newProblem(r, http.StatusConflict, CodeDuplicateBook, err.Error())
+		p.Candidates = response.NewBookDuplicates(dup.Candidates)
+		return p
+	}
+	for _, m := range errorMapping {
+		if errors.Is(err, m.err) {
+			detail := m.detail
+			if detail == "" {
+				detail = err.Error()
+			}
+			return newProblem(r, m.status, m.code, detail)
+		}
+	}
+	log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	return newProblem(r, http.StatusInternalServerError, CodeInternal, "internal server error")
+}
+
+// writeRequestError は request.NewXxx のエラー（入力の不備）を 400 で返す。
+func writeRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, requestProblem(r, err))
+}
+
+// requestProblem は入力の不備の problem を作る。
+// *request.ValidationError なら項目ごとのエラーを付け、JSON として読めなければ malformed_body にする。
+func requestProblem(r *http.Request, err error) *response.Problem {
+	var verr *request.ValidationError
+	if errors.As(err, &verr) {
+		p := newProblem(r, http.StatusBadRequest, CodeValidationFailed, verr.Error())
+		for _, f := range verr.Fields {
+			p.Errors = append(p.Errors, response.ProblemField{Field: f.Field, Message: f.Message})
+		}
+		return p
+	}
+	if errors.Is(err, request.ErrUnsupportedPatch) {
+		return newProblem(r, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, err.Error())
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return newProblem(r, http.StatusBadRequest, CodeMalformedBody, "request body is not valid JSON: "+err.Error())
+	}
+	return newProblem(r, http.StatusBadRequest, CodeValidationFailed, err.Error())
+}
+
+// WriteProblem は code と detail を指定して problem+json を返す。
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblem(w, newProblem(r, status, code, detail))
+}
+
+func newProblem(r *http.Request, status int, code, detail string) *response.Problem {
+	return &response.Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Code:     code,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, p *response.Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// HighlightController は本のハイライト（引用）の HTTP ハンドラ。本ごとの CRUD と、本をまたいだ一覧・日替わりの1件。
+type HighlightController struct {
+	Highlight *usecase.Highlight
+}
+
+func NewHighlightController(u *usecase.Highlight) *HighlightController {
+	return &HighlightController{Highlight: u}
+}
+
+func (c *HighlightController) GetHighlights(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Highlight.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *HighlightController) GetHighlightByID(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightGetByID(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Highlight.GetByID(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *HighlightController) CreateHighlight(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightCreate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Highlight.Create(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *HighlightController) UpdateHighlight(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightUpdate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Highlight.Update(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *HighlightController) DeleteHighlight(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	_, err = c.Highlight.Delete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *HighlightController) GetHighlightFeed(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightFeed(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Highlight.Feed(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *HighlightController) GetDailyHighlight(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewHighlightDaily(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Highlight.Daily(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// ImportCSV は CSV の取り込みを始め、202 と進み具合を見る URL（Location）を返す。行ごとの不備は取り込みの結果で返す。
+func (c *BookController) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewImportCSV(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.ImportCSV(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/api/import/jobs/"+res.ID)
+	writeImportJob(w, r, res, http.StatusAccepted)
+}
+
+// GetImportJob は取り込みの進み具合と、処理した行の結果を返す。
+func (c *BookController) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewImportJobGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.GetImportJob(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	writeImportJob(w, r, res, http.StatusOK)
+}
+
+// writeImportJob は行ごとの status を埋めて返す。登録した行は 201、dryRun で登録できる行は 200 にする。
+func writeImportJob(w http.ResponseWriter, r *http.Request, res *response.ImportJob, status int) {
+	for i := range res.Rows {
+		row := &res.Rows[i]
+		switch {
+		case row.Err != nil:
+			row.Error = itemProblem(r, row.Err)
+			row.Status = row.Error.Status
+		case res.DryRun:
+			row.Status = http.StatusOK
+		default:
+			row.Status = http.StatusCreated
+		}
+	}
+	if res.Err != nil {
+		res.Error = itemProblem(r, res.Err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
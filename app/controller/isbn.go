@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+type ISBNLookupController struct {
+	ISBNLookup *usecase.ISBNLookup
+}
+
+func NewISBNLookupController(u *usecase.ISBNLookup) *ISBNLookupController {
+	return &ISBNLookupController{ISBNLookup: u}
+}
+
+// GetISBN は ISBN（10 / 13 桁、ハイフン可）から書誌情報を引き、本の作成フォームを埋めて返す。
+// ?cover=true なら表紙画像を取り込み、form.thumbnailId に入れる。
+func (c *ISBNLookupController) GetISBN(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewISBNLookup(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ISBNLookup.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if res.CoverError != "" {
+		log.Printf("isbn lookup %s: import cover: %s", req.ISBN, res.CoverError)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,763 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/infra/openapi"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// OpenAPIController は API の仕様（OpenAPI 3.1）を /openapi.json で、その閲覧ページを /docs で返す。
+// 仕様の項目名・型は request / response の構造体から作るので、ここにはルートごとの説明だけを書く。
+// ルートを追加・変更したら apiOperations も直すこと（起動時に CheckRoutes で食い違いをログに出し、openapi_test.go のテストが落ちる）。
+type OpenAPIController struct {
+	spec *openapi.Spec
+	doc  []byte
+}
+
+func NewOpenAPIController() *OpenAPIController {
+	spec := openapi.NewSpec("BookTracker API", "1.0.0")
+	spec.Enum(entity.Status(""), "unread", "reading", "paused", "completed", "abandoned")
+	spec.Enum(entity.Transition(""), "start", "pause", "finish", "abandon", "reread", "progress", "edit", "import")
+	spec.Enum(entity.HighlightColor(""), "yellow", "green", "blue", "pink", "purple")
+	spec.Enum(entity.GoalMetric(""), "books", "pages")
+	spec.Enum(entity.GoalPeriod(""), "year", "month", "custom")
+	spec.ErrorType(response.Problem{}, "application/problem+json")
+	spec.Add(apiOperations()...)
+	doc, err := json.Marshal(spec)
+	if err != nil {
+		log.Fatalf("openapi: %v", err)
+	}
+	return &OpenAPIController{spec: spec, doc: doc}
+}
+
+func (c *OpenAPIController) GetSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(c.doc)
+}
+
+// GetDocs は /openapi.json を読む Swagger UI のページを返す。
+func (c *OpenAPIController) GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsHTML))
+}
+
+// CheckRoutes は router に登録したルートと仕様のルートを突き合わせ、片方にしかないものを返す。
+// /api/books/ と /api/books のような末尾の / の違いは同じルートとして扱う。
+func (c *OpenAPIController) CheckRoutes(routes chi.Routes) []string {
+	registered := map[string]bool{}
+	chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		registered[method+" "+trimRoute(route)] = true
+		return nil
+	})
+	documented := map[string]bool{}
+	for _, op := range c.spec.Operations() {
+		documented[op.Method+" "+trimRoute(op.Path)] = true
+	}
+	var diff []string
+	for k := range registered {
+		if !documented[k] {
+			diff = append(diff, "undocumented route: "+k)
+		}
+	}
+	for k := range documented {
+		if !registered[k] {
+			diff = append(diff, "documented but not registered: "+k)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+func trimRoute(route string) string {
+	if route != "/" {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return route
+}
+
+const docsHTML = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+  <meta charset="utf-8">
+  <title>BookTracker API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });</script>
+</body>
+</html>
+`
+
+// よく使うエラーの説明。先頭はエラーの code
+const (
+	errInvalidBookID = CodeValidationFailed + ": book id が数値でない"
+	errBookNotFound  = CodeBookNotFound + ": 本がない（他のユーザーの本を含む）"
+	errTrashNotFound = CodeTrashNotFound + ": ゴミ箱にその本がない"
+	errBookBatch     = CodeValidationFailed + ": books がない・100 件より多い / " + CodeMalformedBody + ": JSON として読めない（項目ごとの不備は results の error）"
+	errInvalidID     = CodeValidationFailed + ": id が数値でない"
+	errShelfNotFound = CodeShelfNotFound + ": 棚がない"
+	errTagNotFound   = CodeTagNotFound + ": タグがない"
+	errGoalNotFound  = CodeGoalNotFound + ": 目標がない"
+
+	errHighlightNotFound = CodeBookNotFound + ": 本がない / " + CodeHighlightNotFound + ": ハイライトがない"
+
+	errPreconditionFailed   = CodePreconditionFailed + ": If-Match の ETag が今の本と違う（ほかで更新された）"
+	errPreconditionRequired = CodePreconditionRequired + ": If-Match がない（REQUIRE_IF_MATCH=true のとき）"
+)
+
+// apiOperations は main.go で登録しているルートの一覧。
+func apiOperations() []openapi.Operation {
+	bookID := openapi.Param{Name: "id", In: "path", Type: "integer", Description: "本の ID"}
+	shelfID := openapi.Param{Name: "id", In: "path", Type: "integer", Description: "棚の ID"}
+	tagID := openapi.Param{Name: "id", In: "path", Type: "integer", Description: "タグの ID"}
+	goalID := openapi.Param{Name: "id", In: "path", Type: "integer", Description: "目標の ID"}
+	highlightID := openapi.Param{Name: "highlightId", In: "path", Type: "integer", Description: "ハイライトの ID"}
+	ifMatch := openapi.Param{Name: "If-Match", In: "header", Description: "GET /api/books/{id} の ETag。* ならどの version でもよい"}
+	return []openapi.Operation{
+		{
+			Method: "GET", Path: "/", Summary: "API の疎通確認", Tag: "meta", Public: true,
+			Response: map[string]any{},
+		},
+		{
+			Method: "GET", Path: "/health", Summary: "ヘルスチェック", Tag: "meta", Public: true,
+			Response: "", ResponseFormat: "text/plain",
+		},
+		{
+			Method: "GET", Path: "/favicon.ico", Summary: "favicon（常に 204）", Tag: "meta", Public: true,
+			ResponseStatus: http.StatusNoContent,
+		},
+		{
+			Method: "GET", Path: "/openapi.json", Summary: "この API の OpenAPI 3.1 ドキュメント", Tag: "meta", Public: true,
+			Response: map[string]any{},
+		},
+		{
+			Method: "GET", Path: "/docs", Summary: "OpenAPI ドキュメントの閲覧ページ（Swagger UI）", Tag: "meta", Public: true,
+			Response: "", ResponseFormat: "text/html",
+		},
+
+		// 認証
+		{
+			Method: "POST", Path: "/api/auth/signup", Summary: "ユーザー登録（登録と同時にログインする）", Tag: "auth", Public: true,
+			Request: request.AuthSignUpForm{}, Response: response.AuthSignUp{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正（パスワードは 8〜72 バイト）",
+				http.StatusConflict:   CodeEmailTaken + ": メールアドレスが登録済み",
+			},
+		},
+		{
+			Method: "POST", Path: "/api/auth/login", Summary: "ログイン", Tag: "auth", Public: true,
+			Request: request.AuthLoginForm{}, Response: response.AuthLogin{},
+			Errors: map[int]string{
+				http.StatusBadRequest:   CodeValidationFailed + ": 入力が不正",
+				http.StatusUnauthorized: CodeInvalidCredentials + ": メールアドレスかパスワードが違う",
+			},
+		},
+		{
+			Method: "POST", Path: "/api/auth/logout", Summary: "ログアウト（トークンを失効させる）", Tag: "auth",
+			ResponseStatus: http.StatusNoContent,
+			Errors:         map[int]string{http.StatusBadRequest: CodeValidationFailed + ": トークンがない"},
+		},
+		{
+			Method: "GET", Path: "/api/auth/me", Summary: "ログイン中のユーザー", Tag: "auth",
+			Response: response.AuthMe{},
+		},
+		{
+			Method: "PUT", Path: "/api/auth/me", Summary: "名前・設定を変える（送った項目だけ）", Tag: "auth",
+			Description: "timeZone は連続記録の日付とリマインダーの時刻に使う（空文字で UTC）。dailyReminder を true にすると、" +
+				"目標ページ数/日に届いていない読書中の本がある日に、その日の決まった時刻にリマインダーが届く。",
+			Request: request.AuthMeUpdateForm{}, Response: response.AuthMeUpdate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正（errors に項目ごとのエラー）",
+			},
+		},
+
+		// 本
+		{
+			Method: "GET", Path: "/api/books", Summary: "本の一覧", Tag: "books",
+			Params: []openapi.Param{
+				{Name: "status", In: "query", Enum: []string{"unread", "reading", "paused", "completed", "abandoned"}},
+				{Name: "author", In: "query", Description: "完全一致"},
+				{Name: "publisher", In: "query", Description: "完全一致"},
+				{Name: "shelf", In: "query", Description: "棚の名前。その棚に入っている本だけ"},
+				{Name: "tag", In: "query", Description: "タグの名前。そのタグが付いている本だけ"},
+				{Name: "sort", In: "query", Enum: []string{"createdAt", "updatedAt", "targetCompleteDate", "title"}, Description: "既定は createdAt"},
+				{Name: "order", In: "query", Enum: []string{"asc", "desc"}, Description: "既定は asc"},
+				{Name: "limit", In: "query", Type: "integer", Description: "1〜100。既定は 50"},
+				{Name: "pageToken", In: "query", Description: "前ページの nextPageToken"},
+			},
+			Response: response.BookGet{},
+			Errors:   map[int]string{http.StatusBadRequest: CodeValidationFailed + ": クエリパラメータが不正 / " + CodeInvalidPageToken + ": pageToken が解釈できない"},
+		},
+		{
+			Method: "POST", Path: "/api/books", Summary: "本を登録する", Tag: "books",
+			Description: "thumbnailId に POST /api/books/thumbnails の id を指定すると、thumbnailUrl はその画像の URL になる。" +
+				"shelves の棚は先に作っておく。tags のまだないタグは自動で作る。isbn は ISBN-10 でも ISBN-13 にそろえて保存する。" +
+				"同じ isbn の本、isbn がなければタイトル・著者（全角半角・カタカナひらがな・空白を無視）のよく似た本がすでにあると、" +
+				"409 の candidates にそれらを返して登録しない。?allowDuplicate=true なら登録する。",
+			Params:  []openapi.Param{{Name: "allowDuplicate", In: "query", Type: "boolean", Description: "true なら同じらしい本があっても登録する"}},
+			Request: request.BookCreateForm{}, Response: response.BookCreate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正（errors に項目ごとのエラー） / " + CodeThumbnailNotFound + ": thumbnailId の画像がない / " + CodeUnknownShelf + ": shelves にない棚がある",
+				http.StatusConflict:   CodeThumbnailInUse + ": thumbnailId の画像が別の本に付いている / " + CodeDuplicateBook + ": 同じらしい本がある（candidates に候補）",
+			},
+		},
+		{
+			Method: "POST", Path: "/api/books/merge", Summary: "重複した本を1冊にまとめる", Tag: "books",
+			Description: "sourceId の本の読書記録・status の履歴・レビュー・ハイライトを targetId の本に移し、sourceId の本を消す。" +
+				"readPages と status は移した後の読書記録から数え直す。本の項目は target のものを残して空の項目だけ source で埋め、shelves / tags は合わせる。" +
+				"レビューは target にあればそれを残す。source にアップロードした表紙画像は消える。",
+			Request: request.BookMergeForm{}, Response: response.BookMerge{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": targetId / sourceId が不正・同じ",
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/books:batchCreate", Summary: "本をまとめて登録する", Tag: "books",
+			Description: "books（1〜100 件）を1件ずつ POST /api/books と同じく確かめ、results に項目ごとの status（成功は 201）と book / error を返す。" +
+				"全体は項目が失敗しても 200。atomic=true なら1件でも失敗すれば1件も登録せず、ほかの項目は 424（" + CodeBatchAborted + "）になる。" +
+				"allowDuplicate=false（既定）なら登録済みの本と同じらしい本は 409（" + CodeDuplicateBook + "）。",
+			Request: request.BookBatchCreateForm{}, Response: response.BookBatch{},
+			Errors: map[int]string{http.StatusBadRequest: errBookBatch},
+		},
+		{
+			Method: "POST", Path: "/api/books:batchUpdate", Summary: "本をまとめて更新する", Tag: "books",
+			Description: "books の各項目は id と PUT /api/books/{id} と同じ項目で、成功は 200。version を指定すると If-Match と同じく今の version と違えば 412。" +
+				"REQUIRE_IF_MATCH=true なら version がない項目は 428。結果と atomic は batchCreate と同じ。",
+			Request: request.BookBatchUpdateForm{}, Response: response.BookBatch{},
+			Errors: map[int]string{http.StatusBadRequest: errBookBatch},
+		},
+		{
+			Method: "POST", Path: "/api/books:batchDelete", Summary: "本をまとめてゴミ箱に入れる", Tag: "books",
+			Description: "books の各項目は id と任意の version で、DELETE /api/books/{id} と同じくゴミ箱に入れる（成功は 204）。version・結果・atomic は batchUpdate と同じ。",
+			Request:     request.BookBatchDeleteForm{}, Response: response.BookBatch{},
+			Errors: map[int]string{http.StatusBadRequest: errBookBatch},
+		},
+		{
+			Method: "GET", Path: "/api/books/search", Summary: "本の全文検索", Tag: "books",
+			Description: "title / author / publisher / encounterNote から、q のすべての語を含む本をスコアの高い順に返す。" +
+				"日本語は 1〜2 文字ずつに分けて引き、英数字は前方一致。highlights は当たった箇所を <mark> で囲んだ HTML。",
+			Params: []openapi.Param{
+				{Name: "q", In: "query", Required: true, Description: "検索語（空白区切り）"},
+				{Name: "limit", In: "query", Type: "integer", Description: "1〜100。既定は 20"},
+			},
+			Response: response.BookSearch{},
+			Errors:   map[int]string{http.StatusBadRequest: CodeValidationFailed + ": q がない・limit が不正"},
+		},
+		{
+			Method: "GET", Path: "/api/books/{id}", Summary: "本を1冊取得する", Tag: "books",
+			Description: "ratingStats にこの本の評価と、レビューした本全体の件数・平均・分布を付ける。" +
+				"ETag ヘッダーに本の version を返す（PUT / DELETE の If-Match に使う）。",
+			Params:   []openapi.Param{bookID},
+			Response: response.BookGetByID{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/books/{id}", Summary: "本を更新する（送った項目だけ）", Tag: "books",
+			Description: "thumbnailId を付け替える・空文字で外すと、前の画像は消える。shelves / tags は送った一覧に置き換える。" +
+				"If-Match に GET の ETag を付けると、その後ほかで更新されていれば 412 にする。レスポンスの ETag は更新後のもの。",
+			Params:  []openapi.Param{bookID, ifMatch},
+			Request: request.BookUpdateForm{}, Response: response.BookUpdate{},
+			Errors: map[int]string{
+				http.StatusBadRequest:           CodeValidationFailed + ": 入力が不正（errors に項目ごとのエラー） / " + CodeThumbnailNotFound + ": thumbnailId の画像がない / " + CodeUnknownShelf + ": shelves にない棚がある",
+				http.StatusNotFound:             errBookNotFound,
+				http.StatusConflict:             CodeThumbnailInUse + ": thumbnailId の画像が別の本に付いている",
+				http.StatusPreconditionFailed:   errPreconditionFailed,
+				http.StatusPreconditionRequired: errPreconditionRequired,
+			},
+		},
+		{
+			Method: "PATCH", Path: "/api/books/{id}", Summary: "本を部分更新する（merge-patch / json-patch）", Tag: "books",
+			Description: "Content-Type が application/merge-patch+json なら RFC 7396（null で項目を消す）、" +
+				"application/json-patch+json なら RFC 6902 の操作の配列を今の本に当てる。書き換えられるのは POST と同じ項目で、" +
+				"当てた結果を POST と同じ条件（readPages <= totalPages など）で確かめる。status を変えると履歴に edit の遷移を残す。" +
+				"status・readPages を書き換えられるのは登録時の入力の誤りを直すときだけで、読書記録か edit 以外の遷移の履歴がある本では 409（" + CodeInvalidTransition + "）。" +
+				"そうした本の status は POST /api/books/{id}/status で、readPages は読書記録で変える。" +
+				"thumbnailId / If-Match の扱いは PUT と同じ。",
+			Params:        []openapi.Param{bookID, ifMatch},
+			Request:       request.BookCreateForm{},
+			RequestFormat: request.MediaTypeMergePatch,
+			Response:      response.BookPatch{},
+			Errors: map[int]string{
+				http.StatusBadRequest:           CodeValidationFailed + ": 書き換えられない項目・当てた結果が不正（errors に項目ごとのエラー） / " + CodeThumbnailNotFound + ": thumbnailId の画像がない / " + CodeUnknownShelf + ": shelves にない棚がある",
+				http.StatusNotFound:             errBookNotFound,
+				http.StatusConflict:             CodeThumbnailInUse + ": thumbnailId の画像が別の本に付いている / " + CodePatchTestFailed + ": json-patch の test が一致しない / " + CodeInvalidTransition + ": 読書記録・遷移の履歴がある本の status・readPages を変えようとした",
+				http.StatusPreconditionFailed:   errPreconditionFailed,
+				http.StatusUnsupportedMediaType: CodeUnsupportedMediaType + ": Content-Type が merge-patch / json-patch でない",
+				http.StatusPreconditionRequired: errPreconditionRequired,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/books/{id}", Summary: "本をゴミ箱に入れる", Tag: "books",
+			Description: "読書記録・表紙画像などは残り、POST /api/trash/{id}/restore で戻せる。" +
+				"ゴミ箱の本は一覧・取得・検索・集計に出ない。TRASH_RETENTION_DAYS（既定 30）日経つと完全に消える。If-Match は PUT と同じ。",
+			Params:         []openapi.Param{bookID, ifMatch},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest:           errInvalidBookID,
+				http.StatusNotFound:             errBookNotFound,
+				http.StatusPreconditionFailed:   errPreconditionFailed,
+				http.StatusPreconditionRequired: errPreconditionRequired,
+			},
+		},
+
+		// 表紙画像
+		{
+			Method: "POST", Path: "/api/books/thumbnails", Summary: "表紙画像をアップロードする", Tag: "thumbnails",
+			Description: "list / detail / original のサイズに再エンコードして保存する。どの本にも付けないままだと猶予期間の後に消える。",
+			RequestForm: []openapi.FormField{
+				{Name: "file", Binary: true, Required: true, Description: "JPEG / PNG / GIF / WebP（10MB まで）"},
+			},
+			Response: response.BookThumbnailUpload{},
+			Errors: map[int]string{
+				http.StatusBadRequest:            CodeMalformedBody + ": multipart でない / " + CodeValidationFailed + ": file がない",
+				http.StatusRequestEntityTooLarge: CodeThumbnailTooLarge + ": ファイルが 10MB より大きい・画像の画素数が大きすぎる",
+				http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType + ": 画像でない",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/books/thumbnails/{id}", Summary: "表紙画像を取得する", Tag: "thumbnails", Public: true,
+			Params: []openapi.Param{
+				{Name: "id", In: "path", Description: "画像のファイル名（<id>.<ext>）"},
+				{Name: "size", In: "query", Enum: []string{"list", "detail", "original"}, Description: "既定は original"},
+			},
+			Response: "", ResponseFormat: "image/*",
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": size が不正",
+				http.StatusNotFound:   CodeThumbnailNotFound + ": 画像がない",
+			},
+		},
+
+		// 読書記録
+		{
+			Method: "GET", Path: "/api/books/{id}/sessions", Summary: "読書記録の一覧", Tag: "sessions",
+			Params:   []openapi.Param{bookID},
+			Response: response.ReadingSessionGet{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/books/{id}/sessions", Summary: "読書記録を追加する（readPages と status を再計算する）", Tag: "sessions",
+			Params:  []openapi.Param{bookID},
+			Request: request.ReadingSessionCreateForm{}, Response: response.ReadingSessionCreate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正 / " + CodeSessionPageOutOfRange + ": endPage が totalPages を超えている",
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/books/{id}/sessions/{sessionId}", Summary: "読書記録を削除する（readPages と status を再計算する）", Tag: "sessions",
+			Params: []openapi.Param{
+				bookID,
+				{Name: "sessionId", In: "path", Type: "integer", Description: "読書記録の ID"},
+			},
+			Response: response.ReadingSessionDelete{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": book id / session id が数値でない",
+				http.StatusNotFound:   CodeBookNotFound + ": 本がない / " + CodeSessionNotFound + ": 読書記録がない",
+			},
+		},
+
+		// status
+		{
+			Method: "POST", Path: "/api/books/{id}/status", Summary: "status を遷移させる", Tag: "status",
+			Params:  []openapi.Param{bookID},
+			Request: request.BookStatusChangeForm{}, Response: response.BookStatusChange{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": action が不正",
+				http.StatusNotFound:   errBookNotFound,
+				http.StatusConflict:   CodeInvalidTransition + ": 今の status からはその操作ができない",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/books/{id}/status-history", Summary: "status の遷移履歴", Tag: "status",
+			Params:   []openapi.Param{bookID},
+			Response: response.BookStatusHistory{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+
+		// 読了見込み
+		{
+			Method: "GET", Path: "/api/books/{id}/forecast", Summary: "目標日・目標ページ数/日からの読了見込み", Tag: "forecast",
+			Params: []openapi.Param{
+				bookID,
+				{Name: "tz", In: "query", Description: "「今日」を決める IANA タイムゾーン（例: Asia/Tokyo）。既定は UTC"},
+			},
+			Response: response.BookForecast{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": book id / tz が不正",
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+
+		// レビュー
+		{
+			Method: "GET", Path: "/api/books/{id}/review", Summary: "本のレビュー", Tag: "reviews",
+			Params:   []openapi.Param{bookID},
+			Response: response.ReviewGet{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   CodeBookNotFound + ": 本がない / " + CodeReviewNotFound + ": レビューがない",
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/books/{id}/review", Summary: "本のレビューを書く（あれば書き換える）", Tag: "reviews",
+			Description: "completed の本にだけ書ける。finishedOn を省略すると最後に completed になった日になる。",
+			Params: []openapi.Param{
+				bookID,
+				{Name: "force", In: "query", Type: "boolean", Description: "true なら completed でない本にも書く"},
+			},
+			Request: request.ReviewPutForm{}, Response: response.ReviewPut{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正（rating は 0.5 刻みの 0.5〜5）",
+				http.StatusNotFound:   errBookNotFound,
+				http.StatusConflict:   CodeBookNotCompleted + ": 本が completed でない",
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/books/{id}/review", Summary: "本のレビューを削除する", Tag: "reviews",
+			Params:         []openapi.Param{bookID},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   CodeBookNotFound + ": 本がない / " + CodeReviewNotFound + ": レビューがない",
+			},
+		},
+
+		// ハイライト
+		{
+			Method: "GET", Path: "/api/books/{id}/highlights", Summary: "本のハイライトの一覧（ページ順）", Tag: "highlights",
+			Params:   []openapi.Param{bookID},
+			Response: response.HighlightGet{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "POST", Path: "/api/books/{id}/highlights", Summary: "ハイライトを追加する", Tag: "highlights",
+			Params:  []openapi.Param{bookID},
+			Request: request.HighlightCreateForm{}, Response: response.HighlightCreate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正 / " + CodeHighlightPageOutOfRange + ": page が totalPages を超えている",
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/books/{id}/highlights/{highlightId}", Summary: "ハイライトを1件取得する", Tag: "highlights",
+			Params:   []openapi.Param{bookID, highlightID},
+			Response: response.HighlightGetByID{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": book id / highlight id が数値でない",
+				http.StatusNotFound:   errHighlightNotFound,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/books/{id}/highlights/{highlightId}", Summary: "ハイライトを更新する（送った項目だけ）", Tag: "highlights",
+			Params:  []openapi.Param{bookID, highlightID},
+			Request: request.HighlightUpdateForm{}, Response: response.HighlightUpdate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正 / " + CodeHighlightPageOutOfRange + ": page が totalPages を超えている",
+				http.StatusNotFound:   errHighlightNotFound,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/books/{id}/highlights/{highlightId}", Summary: "ハイライトを削除する", Tag: "highlights",
+			Params:         []openapi.Param{bookID, highlightID},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": book id / highlight id が数値でない",
+				http.StatusNotFound:   errHighlightNotFound,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/highlights", Summary: "本をまたいだハイライトの一覧（新しい順）", Tag: "highlights",
+			Params: []openapi.Param{
+				{Name: "bookId", In: "query", Type: "integer", Description: "この本のハイライトだけ"},
+				{Name: "color", In: "query", Enum: []string{"yellow", "green", "blue", "pink", "purple"}},
+				{Name: "category", In: "query", Description: "完全一致"},
+				{Name: "limit", In: "query", Type: "integer", Description: "1〜100。既定は 50"},
+				{Name: "pageToken", In: "query", Description: "前ページの nextPageToken"},
+			},
+			Response: response.HighlightFeed{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": クエリパラメータが不正 / " + CodeInvalidPageToken + ": pageToken が解釈できない",
+				http.StatusNotFound:   errBookNotFound,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/highlights/daily", Summary: "今日見返すハイライト", Tag: "highlights",
+			Description: "日替わりで1件選ぶ。全件を一巡するまで同じものは出ない。同じ日なら何度呼んでも同じものを返す。",
+			Params: []openapi.Param{
+				{Name: "tz", In: "query", Description: "「今日」を決める IANA タイムゾーン（例: Asia/Tokyo）。既定は UTC"},
+			},
+			Response: response.HighlightDaily{},
+			Errors:   map[int]string{http.StatusBadRequest: CodeValidationFailed + ": tz が不正"},
+		},
+
+		// ゴミ箱
+		{
+			Method: "GET", Path: "/api/trash", Summary: "ゴミ箱の本の一覧（ゴミ箱に入れた新しい順）", Tag: "trash",
+			Response: response.TrashGet{},
+		},
+		{
+			Method: "POST", Path: "/api/trash/{id}/restore", Summary: "ゴミ箱の本を戻す", Tag: "trash",
+			Params:   []openapi.Param{bookID},
+			Response: response.TrashRestore{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   errTrashNotFound,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/trash/{id}", Summary: "ゴミ箱の本を完全に消す（読書記録・表紙画像も消える）", Tag: "trash",
+			Params:         []openapi.Param{bookID},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidBookID,
+				http.StatusNotFound:   errTrashNotFound,
+			},
+		},
+
+		// CSV の取り込み
+		{
+			Method: "POST", Path: "/api/import/csv", Summary: "CSV から本を取り込む", Tag: "import",
+			Description: "取り込みは非同期で、202 の Location（GET /api/import/jobs/{id}）で進み具合と行ごとの結果を見る。" +
+				"format=goodreads は Goodreads の Export Library の CSV で、Exclusive Shelf を status（read → completed など）、Bookshelves を tags、" +
+				"Date Read を読み終えた日（status の履歴）、My Rating / My Review をレビューにする。" +
+				"format=generic は mapping で本の項目ごとに CSV の列名を指定する（title / author / totalPages は必須）。" +
+				"登録済みの本や CSV の前の行と同じらしい本は登録せず、その行は 409（" + CodeDuplicateBook + "）になる。",
+			RequestForm: []openapi.FormField{
+				{Name: "file", Binary: true, Required: true, Description: "CSV（UTF-8、10MB・5000 行まで。1行目はヘッダー）"},
+				{Name: "format", Description: "goodreads（既定）/ generic"},
+				{Name: "mapping", Description: "本の項目 → CSV の列名の JSON（例: {\"title\":\"書名\",\"totalPages\":\"ページ数\"}）。" +
+					"項目は title / author / publisher / totalPages / isbn / status / thumbnailUrl / encounterNote / tags（カンマ区切り）/ " +
+					"addedOn / finishedOn（YYYY-MM-DD か YYYY/MM/DD）/ rating / review / spoiler。goodreads では Goodreads の列を上書きするときだけ指定する"},
+				{Name: "dryRun", Description: "true なら何も登録せず、登録するはずの本と行ごとのエラーだけを返す"},
+			},
+			Response: response.ImportJob{}, ResponseStatus: http.StatusAccepted,
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": file がない・CSV として読めない・行がない / format・mapping が不正（行ごとの不備は rows の error）",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/import/jobs/{id}", Summary: "CSV の取り込みの進み具合", Tag: "import",
+			Description: "rows は処理した行の結果で、登録した行は 201（dryRun なら 200）。取り込みの結果は終わってから 24 時間見られる。" +
+				"進み具合と行の結果は保存しているので、どのインスタンスに聞いても同じ結果になる。取り込みを進めていたサーバーが止まると state は failed になる（登録済みの本は残る）。",
+			Params: []openapi.Param{
+				{Name: "id", In: "path", Description: "POST /api/import/csv で返った id"},
+			},
+			Response: response.ImportJob{},
+			Errors:   map[int]string{http.StatusNotFound: CodeImportJobNotFound + ": 取り込みがない（期限切れを含む）"},
+		},
+
+		// 本棚
+		{
+			Method: "GET", Path: "/api/shelves", Summary: "棚の一覧（名前順）", Tag: "shelves",
+			Response: response.ShelfGet{},
+		},
+		{
+			Method: "POST", Path: "/api/shelves", Summary: "棚を作る", Tag: "shelves",
+			Request: request.ShelfCreateForm{}, Response: response.ShelfCreate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正",
+				http.StatusConflict:   CodeShelfNameTaken + ": 同じ名前の棚がある",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/shelves/{id}", Summary: "棚を1つ取得する", Tag: "shelves",
+			Params:   []openapi.Param{shelfID},
+			Response: response.ShelfGetByID{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidID,
+				http.StatusNotFound:   errShelfNotFound,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/shelves/{id}", Summary: "棚を更新する（送った項目だけ）", Tag: "shelves",
+			Description: "名前を変えると、入っている本の shelves も同じトランザクションで新しい名前になる。",
+			Params:      []openapi.Param{shelfID},
+			Request:     request.ShelfUpdateForm{}, Response: response.ShelfUpdate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正",
+				http.StatusNotFound:   errShelfNotFound,
+				http.StatusConflict:   CodeShelfNameTaken + ": 同じ名前の棚がある",
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/shelves/{id}", Summary: "棚を削除する（本は消えず、棚から出るだけ）", Tag: "shelves",
+			Params:         []openapi.Param{shelfID},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidID,
+				http.StatusNotFound:   errShelfNotFound,
+			},
+		},
+
+		// タグ
+		{
+			Method: "GET", Path: "/api/tags", Summary: "タグの一覧（名前順）", Tag: "tags",
+			Response: response.TagGet{},
+		},
+		{
+			Method: "POST", Path: "/api/tags", Summary: "タグを作る", Tag: "tags",
+			Description: "本の作成・更新で tags に知らない名前を付けても自動で作られる。",
+			Request:     request.TagForm{}, Response: response.TagCreate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正",
+				http.StatusConflict:   CodeTagNameTaken + ": 同じ名前のタグがある",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/tags/{id}", Summary: "タグを1つ取得する", Tag: "tags",
+			Params:   []openapi.Param{tagID},
+			Response: response.TagGetByID{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidID,
+				http.StatusNotFound:   errTagNotFound,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/tags/{id}", Summary: "タグの名前を変える", Tag: "tags",
+			Description: "付いている本の tags も同じトランザクションで新しい名前になる。",
+			Params:      []openapi.Param{tagID},
+			Request:     request.TagForm{}, Response: response.TagUpdate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正",
+				http.StatusNotFound:   errTagNotFound,
+				http.StatusConflict:   CodeTagNameTaken + ": 同じ名前のタグがある",
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/tags/{id}", Summary: "タグを削除する（付いていた本からも外れる）", Tag: "tags",
+			Params:         []openapi.Param{tagID},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidID,
+				http.StatusNotFound:   errTagNotFound,
+			},
+		},
+
+		// 読書目標
+		{
+			Method: "GET", Path: "/api/goals", Summary: "読書目標の一覧（期間の開始日順）", Tag: "goals",
+			Response: response.ReadingGoalGet{},
+		},
+		{
+			Method: "POST", Path: "/api/goals", Summary: "読書目標を作る", Tag: "goals",
+			Description: "metric は books（読み終えた冊数）か pages（読書記録のページ数）。" +
+				"period が year なら year 年、month なら year 年 month 月、custom なら startDate〜endDate（どちらも含む）が期間になる。",
+			Request: request.ReadingGoalCreateForm{}, Response: response.ReadingGoalCreate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正（errors に項目ごとのエラー）",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/goals/{id}", Summary: "読書目標を1つ取得する", Tag: "goals",
+			Params:   []openapi.Param{goalID},
+			Response: response.ReadingGoalGetByID{},
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidID,
+				http.StatusNotFound:   errGoalNotFound,
+			},
+		},
+		{
+			Method: "PUT", Path: "/api/goals/{id}", Summary: "読書目標を更新する（送った項目だけ）", Tag: "goals",
+			Description: "変えられるのは title と target だけ。期間・metric を変えるときは作り直す。",
+			Params:      []openapi.Param{goalID},
+			Request:     request.ReadingGoalUpdateForm{}, Response: response.ReadingGoalUpdate{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": 入力が不正",
+				http.StatusNotFound:   errGoalNotFound,
+			},
+		},
+		{
+			Method: "DELETE", Path: "/api/goals/{id}", Summary: "読書目標を削除する", Tag: "goals",
+			Params:         []openapi.Param{goalID},
+			ResponseStatus: http.StatusNoContent,
+			Errors: map[int]string{
+				http.StatusBadRequest: errInvalidID,
+				http.StatusNotFound:   errGoalNotFound,
+			},
+		},
+		{
+			Method: "GET", Path: "/api/goals/{id}/progress", Summary: "読書目標の進み具合（予定と実績の推移）", Tag: "goals",
+			Description: "books は completed になった日（後で読書記録を消して completed でなくなったものは除く）、pages は読書記録の終了日で数える。" +
+				"予定は期間を通して一定のペースで、curve に日ごとの予定と実績（どちらも累計、今日より後の実績は null）を返す。",
+			Params: []openapi.Param{
+				goalID,
+				{Name: "tz", In: "query", Description: "「今日」と読了・読書記録の日付を決める IANA タイムゾーン（例: Asia/Tokyo）。既定は UTC"},
+			},
+			Response: response.ReadingGoalProgress{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": id / tz が不正",
+				http.StatusNotFound:   errGoalNotFound,
+			},
+		},
+
+		// 統計
+		{
+			Method: "GET", Path: "/api/stats", Summary: "読書の統計", Tag: "stats",
+			Description: "byStatus は今の status ごとの冊数。それ以外は期間の中の記録から数える。" +
+				"ページ数は読書記録の終了日、読み終えた本は completed になった日で数え、" +
+				"averageDaysToFinish は読み始め（最初の読書記録か reading になった日）から読み終えるまでの日数（両端を含む）。" +
+				"completionRate は読み終えた / (読み終えた + 読むのをやめた)。heatmap の level は期間の中で一番読んだ日を 4 とした 0〜4。",
+			Params: []openapi.Param{
+				{Name: "from", In: "query", Description: "期間の初日（YYYY-MM-DD）。既定は to の1年前の翌日"},
+				{Name: "to", In: "query", Description: "期間の最終日（YYYY-MM-DD）。既定は今日"},
+				{Name: "tz", In: "query", Description: "「今日」と記録の日付を決める IANA タイムゾーン（例: Asia/Tokyo）。既定は UTC"},
+			},
+			Response: response.StatsGet{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": from / to / tz が不正 / " + CodeInvalidDateRange + ": from が to より後・期間が長すぎる",
+			},
+		},
+		{
+			Method: "GET", Path: "/api/streak", Summary: "読書の連続記録", Tag: "stats",
+			Description: "読書記録のある日（記録の終了日）が続いた日数。current は今日か昨日まで続いている連続日数で、" +
+				"今日まだ読んでいなくても昨日まで続いていれば途切れていない（readToday で分かる）。",
+			Params: []openapi.Param{
+				{Name: "tz", In: "query", Description: "日付を決める IANA タイムゾーン（例: Asia/Tokyo）。既定はユーザーの timeZone"},
+			},
+			Response: response.StreakGet{},
+			Errors:   map[int]string{http.StatusBadRequest: CodeValidationFailed + ": tz が不正"},
+		},
+
+		// ISBN
+		{
+			Method: "GET", Path: "/api/lookup/isbn/{isbn}", Summary: "ISBN から本の作成フォームを埋める", Tag: "books",
+			Description: "Open Library・Google Books を順に引き、最初に見つかった書誌情報の足りない項目を後のサービスで埋める。" +
+				"form はそのまま POST /api/books に送れる（targetCompleteDate などは足す）。" +
+				"?cover=true なら表紙画像をアップロードと同じように取り込み、form.thumbnailId に入れる。取り込めなくても書誌情報は返し、coverError に理由を入れる。",
+			Params: []openapi.Param{
+				{Name: "isbn", In: "path", Description: "ISBN-10 / ISBN-13（ハイフン可）"},
+				{Name: "cover", In: "query", Enum: []string{"true", "false"}, Description: "表紙画像を取り込むか。既定は false"},
+			},
+			Response: response.ISBNLookup{},
+			Errors: map[int]string{
+				http.StatusBadRequest: CodeValidationFailed + ": ISBN の形式・チェックディジットが不正",
+				http.StatusNotFound:   CodeISBNNotFound + ": どのサービスにもその ISBN の本がない",
+				http.StatusBadGateway: CodeMetadataUnavailable + ": 書誌情報のサービスに接続できない",
+			},
+		},
+	}
+}
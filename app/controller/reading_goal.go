@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+type ReadingGoalController struct {
+	ReadingGoal *usecase.ReadingGoal
+}
+
+func NewReadingGoalController(u *usecase.ReadingGoal) *ReadingGoalController {
+	return &ReadingGoalController{ReadingGoal: u}
+}
+
+func (c *ReadingGoalController) GetGoals(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingGoalGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingGoal.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReadingGoalController) GetGoalByID(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingGoalGetByID(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingGoal.GetByID(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReadingGoalController) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingGoalCreate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingGoal.Create(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReadingGoalController) UpdateGoal(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingGoalUpdate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingGoal.Update(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReadingGoalController) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingGoalDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	_, err = c.ReadingGoal.Delete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGoalProgress は目標の期間の実績と、予定（期間を通して一定のペース）との比較を返す。?tz で日付を決めるタイムゾーンを選ぶ。
+func (c *ReadingGoalController) GetGoalProgress(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingGoalProgress(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingGoal.Progress(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
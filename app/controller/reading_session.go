@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// ReadingSessionController は本ごとの読書記録（/api/books/{id}/sessions）のHTTPハンドラです。
+type ReadingSessionController struct {
+	ReadingSession *usecase.ReadingSession
+}
+
+func NewReadingSessionController(s *usecase.ReadingSession) *ReadingSessionController {
+	return &ReadingSessionController{ReadingSession: s}
+}
+
+func (c *ReadingSessionController) GetSessions(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingSessionGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingSession.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReadingSessionController) CreateSession(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingSessionCreate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingSession.Create(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// DeleteSession は記録を削除し、再計算後の本を返す。
+func (c *ReadingSessionController) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReadingSessionDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.ReadingSession.Delete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
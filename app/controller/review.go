@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// ReviewController は本のレビュー（1冊に1つ）の HTTP ハンドラ。
+type ReviewController struct {
+	Review *usecase.Review
+}
+
+func NewReviewController(u *usecase.Review) *ReviewController {
+	return &ReviewController{Review: u}
+}
+
+func (c *ReviewController) GetReview(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReviewGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Review.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReviewController) PutReview(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReviewPut(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Review.Put(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ReviewController) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewReviewDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	_, err = c.Review.Delete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+type ShelfController struct {
+	Shelf *usecase.Shelf
+}
+
+func NewShelfController(u *usecase.Shelf) *ShelfController {
+	return &ShelfController{Shelf: u}
+}
+
+func (c *ShelfController) GetShelves(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewShelfGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Shelf.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ShelfController) GetShelfByID(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewShelfGetByID(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Shelf.GetByID(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ShelfController) CreateShelf(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewShelfCreate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Shelf.Create(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ShelfController) UpdateShelf(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewShelfUpdate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Shelf.Update(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *ShelfController) DeleteShelf(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewShelfDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	_, err = c.Shelf.Delete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+type StatsController struct {
+	Stats *usecase.Stats
+}
+
+func NewStatsController(u *usecase.Stats) *StatsController {
+	return &StatsController{Stats: u}
+}
+
+// GetStats は ?from〜?to（既定は今日までの1年間）の読書の統計を返す。?tz で日付を決めるタイムゾーンを選ぶ。
+func (c *StatsController) GetStats(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewStatsGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Stats.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+type StreakController struct {
+	Streak *usecase.Streak
+}
+
+func NewStreakController(u *usecase.Streak) *StreakController {
+	return &StreakController{Streak: u}
+}
+
+// GetStreak は読書の連続記録（今の連続日数と最長記録）を返す。
+func (c *StreakController) GetStreak(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewStreakGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Streak.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
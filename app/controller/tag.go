@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+type TagController struct {
+	Tag *usecase.Tag
+}
+
+func NewTagController(u *usecase.Tag) *TagController {
+	return &TagController{Tag: u}
+}
+
+func (c *TagController) GetTags(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTagGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Tag.Get(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *TagController) GetTagByID(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTagGetByID(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Tag.GetByID(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *TagController) CreateTag(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTagCreate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Tag.Create(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *TagController) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTagUpdate(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Tag.Update(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (c *TagController) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTagDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	_, err = c.Tag.Delete(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// GetTrash はゴミ箱の本の一覧を返す。
+func (c *BookController) GetTrash(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTrashGet(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.Trash(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// RestoreBook はゴミ箱の本を戻す。
+func (c *BookController) RestoreBook(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTrashRestore(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	res, err := c.Book.Restore(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", response.BookETag(res.Book))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// PurgeBook はゴミ箱の本を完全に消す。
+func (c *BookController) PurgeBook(w http.ResponseWriter, r *http.Request) {
+	req, err := request.NewTrashDelete(r)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	_, err = c.Book.Purge(r.Context(), req)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
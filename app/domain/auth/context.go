@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+type contextKey struct{}
+
+// WithUser は context にログイン中のユーザーを入れる。認証 middleware でリクエストごとに呼ぶ。
+func WithUser(ctx context.Context, user *entity.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext は context からログイン中のユーザーを取得する。repository 層で本の持ち主を決めるのに使う。
+func UserFromContext(ctx context.Context) (*entity.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*entity.User)
+	return user, ok && user != nil
+}
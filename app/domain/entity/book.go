@@ -7,7 +7,9 @@ type Status string
 const (
 	StatusUnread Status = "unread"
 	StatusReading Status = "reading"
+	StatusPaused Status = "paused"
 	StatusCompleted Status = "completed"
+	StatusAbandoned Status = "abandoned"
 )
 // Book は本のドメインエンティティ。JSON と Datastore の両方で使う。
 // ID は Datastore の Key で持つため datastore:"-" で保存しない。
@@ -17,12 +19,20 @@ type Book struct {
 	Author              string    `json:"author"             datastore:"author"`
 	TotalPages          int       `json:"totalPages"         datastore:"totalPages"`
 	Publisher           string    `json:"publisher"          datastore:"publisher"`
+	ISBN                string    `json:"isbn,omitempty"     datastore:"isbn"` // ISBN-13（ハイフンなし）。ISBN-10 で登録しても 13 桁にそろえる
 	ThumbnailUrl        string    `json:"thumbnailUrl"       datastore:"thumbnailUrl"`
+	ThumbnailID         string    `json:"thumbnailId"        datastore:"thumbnailId"` // アップロードした Thumbnail の ID。空なら thumbnailUrl は外部の URL
 	Status              Status    `json:"status"             datastore:"status"`
 	TargetCompleteDate  time.Time `json:"targetCompleteDate" datastore:"targetCompleteDate"`
 	EncounterNote       string    `json:"encounterNote"      datastore:"encounterNote"`
 	ReadPages           int       `json:"readPages"          datastore:"readPages"`
+	BaseReadPages       int       `json:"-"                  datastore:"baseReadPages,noindex"` // 読書記録なしで入力した readPages（登録時・PATCH）。1 ページ目からここまでは読んだものとして数える
 	TargetPagesPerDay   int       `json:"targetPagesPerDay"  datastore:"targetPagesPerDay"`
+	ReadingRestartedAt  time.Time `json:"readingRestartedAt" datastore:"readingRestartedAt"` // 再読を始めた日時。これより前の読書記録は readPages に数えない
+	Shelves             []string  `json:"shelves,omitempty"   datastore:"shelves"` // 入っている棚の名前。棚の改名・削除で書き換える
+	Tags                []string  `json:"tags,omitempty"      datastore:"tags"`
 	CreatedAt           time.Time `json:"createdAt"          datastore:"createdAt"`
 	UpdatedAt           time.Time `json:"updatedAt"          datastore:"updatedAt"`
+	Version             int       `json:"version"            datastore:"version"` // 保存するたびに 1 増やす。ETag に使う
+	DeletedAt           time.Time `json:"-"                  datastore:"deletedAt"` // ゴミ箱に入れた日時。ゼロ値ならゴミ箱に入っていない。JSON ではゴミ箱の一覧（response.TrashedBook）でだけ返す
 }
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// HighlightColor はハイライトの色。用途（引用・要確認など）の区別に使う。
+type HighlightColor string
+
+const (
+	HighlightYellow HighlightColor = "yellow"
+	HighlightGreen  HighlightColor = "green"
+	HighlightBlue   HighlightColor = "blue"
+	HighlightPink   HighlightColor = "pink"
+	HighlightPurple HighlightColor = "purple"
+)
+
+// Highlight は本から抜き出した引用・ハイライト。Datastore では Book の Key の子エンティティとして保存する。
+type Highlight struct {
+	ID        int            `json:"id"        datastore:"-"`
+	BookID    int            `json:"bookId"    datastore:"-"`
+	Text      string         `json:"text"      datastore:"text,noindex"`
+	Page      int            `json:"page"      datastore:"page"`
+	Chapter   string         `json:"chapter"   datastore:"chapter,noindex"` // 任意
+	Comment   string         `json:"comment"   datastore:"comment,noindex"` // 自分のメモ
+	Color     HighlightColor `json:"color"     datastore:"color"`
+	Category  string         `json:"category"  datastore:"category"` // 任意の分類（「定義」「要確認」など）
+	CreatedAt time.Time      `json:"createdAt" datastore:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" datastore:"updatedAt"`
+}
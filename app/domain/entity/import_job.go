@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// ImportJobState は CSV の取り込みの状態。
+type ImportJobState string
+
+const (
+	ImportJobRunning   ImportJobState = "running"
+	ImportJobSucceeded ImportJobState = "succeeded" // すべての行を処理した（失敗した行があっても succeeded）
+	ImportJobFailed    ImportJobState = "failed"    // Datastore のエラーなどで途中で止まった
+)
+
+// ImportJob は CSV の取り込み1回分。Datastore では取り込みを始めた User の Key の子として保存し、
+// 行の結果（Rows）は1エンティティの大きさの上限に届かないよう、取り込みの Key の子に処理した塊ごとに分けて保存する。
+// どのインスタンスで取り込んでいても、進み具合はほかのインスタンスから読める。
+type ImportJob struct {
+	ID         string         `datastore:"-"` // ランダムな ID。Key 名に使う
+	UserID     int            `datastore:"-"` // Key の親から埋める
+	Format     string         `datastore:"format,noindex"`
+	DryRun     bool           `datastore:"dryRun,noindex"`
+	State      ImportJobState `datastore:"state,noindex"`
+	Total      int            `datastore:"total,noindex"`
+	Processed  int            `datastore:"processed,noindex"` // Rows の数と同じ
+	Rows       []ImportRow    `datastore:"-"`
+	Error      string         `datastore:"error,noindex"` // State が failed のときの原因
+	CreatedAt  time.Time      `datastore:"createdAt,noindex"`
+	UpdatedAt  time.Time      `datastore:"updatedAt,noindex"` // 最後に進み具合を書いた日時
+	FinishedAt time.Time      `datastore:"finishedAt,noindex"`
+}
+
+// ImportRow は1行分の結果。Error がなければ Book に登録した本（dryRun なら登録するはずの本で ID は 0）が入る。
+// Datastore には塊ごとに JSON にして保存する。
+type ImportRow struct {
+	Row   int             `json:"row"` // CSV の行番号（ヘッダーが1行目）
+	Book  *Book           `json:"book,omitempty"`
+	Error *ImportRowError `json:"error,omitempty"`
+}
+
+// ImportRowError は登録しなかった行の理由。Fields があれば入力の不備、Candidates があれば同じらしい本があった。
+// どちらもなければそれ以外のエラーで、Kind に決まったエラー（表紙画像が使われているなど）の文言が入ることがある。
+type ImportRowError struct {
+	Message    string               `json:"message"`
+	Kind       string               `json:"kind,omitempty"`
+	Fields     []ImportRowField     `json:"fields,omitempty"`
+	Candidates []ImportRowCandidate `json:"candidates,omitempty"`
+}
+
+func (e *ImportRowError) Error() string {
+	return e.Message
+}
+
+// ImportRowField は入力の不備のある項目。
+type ImportRowField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportRowCandidate は同じらしい本。Reason・Score は重複の候補を探したときのもの。
+type ImportRowCandidate struct {
+	Book   Book    `json:"book"`
+	Reason string  `json:"reason"`
+	Score  float64 `json:"score"`
+}
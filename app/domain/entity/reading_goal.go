@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// GoalMetric は読書目標で数えるもの。
+type GoalMetric string
+
+const (
+	GoalBooks GoalMetric = "books" // 読み終えた冊数
+	GoalPages GoalMetric = "pages" // 読書記録のページ数
+)
+
+// GoalPeriod は読書目標の期間の決め方。
+type GoalPeriod string
+
+const (
+	GoalYear   GoalPeriod = "year"
+	GoalMonth  GoalPeriod = "month"
+	GoalCustom GoalPeriod = "custom"
+)
+
+// ReadingGoal は期間を決めた読書目標（「2026年に24冊」「11月に1000ページ」）。Datastore では User の Key の子として保存する。
+// StartDate・EndDate はどちらも含む暦日で、NormalizedDate と同じくその日の 00:00:00Z で持つ。
+type ReadingGoal struct {
+	ID        int        `json:"id"        datastore:"-"`
+	Title     string     `json:"title"     datastore:"title,noindex"` // 任意
+	Metric    GoalMetric `json:"metric"    datastore:"metric"`
+	Target    int        `json:"target"    datastore:"target"`
+	Period    GoalPeriod `json:"period"    datastore:"period"`
+	StartDate time.Time  `json:"startDate" datastore:"startDate"`
+	EndDate   time.Time  `json:"endDate"   datastore:"endDate"`
+	CreatedAt time.Time  `json:"createdAt" datastore:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt" datastore:"updatedAt"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// ReadingSession は1回分の読書記録。Datastore では Book の Key の子エンティティとして保存する。
+// StartPage〜EndPage はどちらも含む（1〜30 なら 30 ページ）。
+type ReadingSession struct {
+	ID        int       `json:"id"        datastore:"-"`
+	BookID    int       `json:"bookId"    datastore:"-"`
+	StartPage int       `json:"startPage" datastore:"startPage"`
+	EndPage   int       `json:"endPage"   datastore:"endPage"`
+	StartedAt time.Time `json:"startedAt" datastore:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"   datastore:"endedAt"`
+	Note      string    `json:"note"      datastore:"note,noindex"`
+	CreatedAt time.Time `json:"createdAt" datastore:"createdAt"`
+}
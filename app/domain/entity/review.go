@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// Review は読み終えた本の感想。1冊に1つで、Datastore では Book の Key の子エンティティとして保存する。
+// Rating は 0.5 刻みの 0.5〜5。FinishedOn は読み終えた日の 00:00:00Z。
+type Review struct {
+	BookID      int       `json:"bookId"      datastore:"-"`
+	Rating      float64   `json:"rating"      datastore:"rating"`
+	Text        string    `json:"text"        datastore:"text,noindex"` // Markdown
+	Spoiler     bool      `json:"spoiler"     datastore:"spoiler,noindex"`
+	FinishedOn  time.Time `json:"finishedOn"  datastore:"finishedOn"`
+	WouldReread bool      `json:"wouldReread" datastore:"wouldReread,noindex"`
+	CreatedAt   time.Time `json:"createdAt"   datastore:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"   datastore:"updatedAt"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Shelf はユーザーが作る本棚（「積読」「2026年に読む」など）。Datastore では User の Key の子として保存する。
+// 本は棚の名前を Book.Shelves に持つので、名前を変えたら本の側も書き換える。
+type Shelf struct {
+	ID          int       `json:"id"          datastore:"-"`
+	Name        string    `json:"name"        datastore:"name"`
+	Description string    `json:"description" datastore:"description,noindex"`
+	CreatedAt   time.Time `json:"createdAt"   datastore:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"   datastore:"updatedAt"`
+}
+
+// Tag は本に付ける自由なタグ。本の作成・更新で知らない名前を付けると自動で作る。
+// 棚と同じく本は名前を Book.Tags に持つ。
+type Tag struct {
+	ID        int       `json:"id"        datastore:"-"`
+	Name      string    `json:"name"      datastore:"name"`
+	CreatedAt time.Time `json:"createdAt" datastore:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" datastore:"updatedAt"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Transition は status を変える操作。
+type Transition string
+
+const (
+	TransitionStart   Transition = "start"   // unread / paused → reading
+	TransitionPause   Transition = "pause"   // reading → paused
+	TransitionFinish  Transition = "finish"  // reading / paused → completed（readPages == totalPages のときだけ）
+	TransitionAbandon Transition = "abandon" // unread / reading / paused → abandoned
+	TransitionReread  Transition = "reread"  // completed / abandoned → reading（readPages を 0 からやり直す）
+	// TransitionProgress は読書記録の追加・削除で readPages が変わったことによる自動の遷移。
+	TransitionProgress Transition = "progress"
+	// TransitionEdit は PATCH で status を直接書き換えたことによる遷移（登録時の入力の誤りを直すときなど）。
+	TransitionEdit Transition = "edit"
+	// TransitionImport は CSV の取り込みで、読み終えた日を履歴に残したもの（本は completed で登録してある）。
+	TransitionImport Transition = "import"
+)
+
+// StatusChange は status の遷移1回分の履歴。Datastore では Book の Key の子エンティティとして保存する。
+type StatusChange struct {
+	ID        int        `json:"id"        datastore:"-"`
+	BookID    int        `json:"bookId"    datastore:"-"`
+	Action    Transition `json:"action"    datastore:"action"`
+	From      Status     `json:"from"      datastore:"from"`
+	To        Status     `json:"to"        datastore:"to"`
+	ReadPages int        `json:"readPages" datastore:"readPages"` // 遷移した時点の readPages
+	ChangedAt time.Time  `json:"changedAt" datastore:"changedAt"`
+}
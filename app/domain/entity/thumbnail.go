@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// Thumbnail はアップロードされた表紙画像。Datastore 上ではアップロードした User の Key の子として保存する。
+// BookID が 0 の間はどの本にも付いておらず、猶予期間を過ぎても付かなければ GC で消す。
+type Thumbnail struct {
+	ID         string    `json:"id"         datastore:"-"` // ファイル名の元になるランダムな ID。Key 名に使う
+	UserID     int       `json:"-"          datastore:"-"` // Key の親から埋める
+	BookID     int       `json:"bookId"     datastore:"bookId"`
+	URL        string    `json:"url"        datastore:"url,noindex"`   // original の URL
+	Files      []string  `json:"-"          datastore:"files,noindex"` // ThumbnailStore に保存したファイル名（サイズ違いすべて）
+	UploadedAt time.Time `json:"uploadedAt" datastore:"uploadedAt"`
+	AttachedAt time.Time `json:"attachedAt" datastore:"attachedAt,noindex"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// User はログインするユーザー。本とその子エンティティは Datastore 上でこの User の Key の子孫として保存する。
+type User struct {
+	ID            int       `json:"id"            datastore:"-"`
+	Email         string    `json:"email"         datastore:"email"`
+	Name          string    `json:"name"          datastore:"name"`
+	PasswordHash  string    `json:"-"             datastore:"passwordHash,noindex"` // bcrypt
+	TimeZone      string    `json:"timeZone"      datastore:"timeZone,noindex"`     // IANA のタイムゾーン名（例: Asia/Tokyo）。連続記録の日付やリマインダーの時刻に使う。空なら UTC
+	DailyReminder bool      `json:"dailyReminder" datastore:"dailyReminder"`        // 目標ページ数/日に届いていない日にリマインダーを送るか
+	RemindedOn    time.Time `json:"-"             datastore:"remindedOn,noindex"`   // 最後にリマインダーを確かめた日（ユーザーのタイムゾーンの暦日）。インスタンスをまたいで1日1回にする
+	CreatedAt     time.Time `json:"createdAt"     datastore:"createdAt"`
+}
+
+// AuthToken はログインセッション。クライアントに渡すトークンそのものは保存せず、SHA-256 のハッシュを Key にする。
+type AuthToken struct {
+	TokenHash string    `json:"-"         datastore:"-"`
+	UserID    int       `json:"userId"    datastore:"userId"`
+	ExpiresAt time.Time `json:"expiresAt" datastore:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt" datastore:"createdAt"`
+}
@@ -0,0 +1,90 @@
+// Package isbn は ISBN の検証と正規化。本には ISBN-13 にそろえて保存する。
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidFormat は桁数・使える文字が ISBN-10 / ISBN-13 のどちらでもないときに返す。
+	ErrInvalidFormat = errors.New("isbn must be 10 or 13 digits (ISBN-10 may end with X)")
+	// ErrInvalidChecksum はチェックディジットが合わないときに返す。
+	ErrInvalidChecksum = errors.New("isbn checksum is invalid")
+)
+
+// Normalize は ISBN-10 / ISBN-13 を検証し、ISBN-13 の数字だけの文字列にする。
+// ハイフン・空白と "ISBN" の接頭辞は無視し、全角の数字も受け付ける。
+func Normalize(s string) (string, error) {
+	digits := clean(s)
+	switch len(digits) {
+	case 10:
+		if !valid10(digits) {
+			return "", ErrInvalidChecksum
+		}
+		body := "978" + digits[:9]
+		return body + string(check13(body)), nil
+	case 13:
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", ErrInvalidFormat
+		}
+		if check13(digits[:12]) != digits[12] {
+			return "", ErrInvalidChecksum
+		}
+		return digits, nil
+	}
+	return "", ErrInvalidFormat
+}
+
+// clean は区切りを除いた文字列。数字と末尾の X 以外が入っていれば空を返す。
+func clean(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 4 && strings.EqualFold(s[:4], "isbn") {
+		s = strings.TrimLeft(s[4:], ":- ")
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= '０' && r <= '９':
+			b.WriteRune('0' + r - '０')
+		case r == 'X' || r == 'x':
+			b.WriteByte('X')
+		case r == '-' || r == ' ' || r == '‐' || r == '－':
+		default:
+			return ""
+		}
+	}
+	out := b.String()
+	if i := strings.IndexByte(out, 'X'); i >= 0 && (i != len(out)-1 || len(out) != 10) {
+		return ""
+	}
+	return out
+}
+
+// valid10 は ISBN-10 のチェックディジット（11 を法とする重み付きの和、10 は X）を確かめる。
+func valid10(d string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		v := int(d[i] - '0')
+		if d[i] == 'X' {
+			v = 10
+		}
+		sum += v * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// check13 は ISBN-13 の先頭12桁からチェックディジットを求める。
+func check13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		v := int(body[i] - '0')
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return byte('0' + (10-sum%10)%10)
+}
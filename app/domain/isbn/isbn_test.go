@@ -0,0 +1,52 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		// ISBN-10 は 978 を付けてチェックディジットを求め直す
+		{in: "0306406152", want: "9780306406157"},
+		{in: "0-306-40615-2", want: "9780306406157"},
+		{in: "4101010013", want: "9784101010014"},
+		{in: "0-8044-2957-X", want: "9780804429573"},
+		{in: "080442957x", want: "9780804429573"},
+		{in: "ISBN 0-306-40615-2", want: "9780306406157"},
+		{in: "isbn:0306406152", want: "9780306406157"},
+		{in: "０３０６４０６１５２", want: "9780306406157"},
+		{in: "０‐３０６‐４０６１５‐２", want: "9780306406157"},
+		// ISBN-13 はそのまま
+		{in: "9780306406157", want: "9780306406157"},
+		{in: "978-4-10-101001-4", want: "9784101010014"},
+		{in: "  978 0 306 40615 7  ", want: "9780306406157"},
+		// チェックディジットが合わない
+		{in: "0306406153", wantErr: ErrInvalidChecksum},
+		{in: "030640615X", wantErr: ErrInvalidChecksum},
+		{in: "9780306406158", wantErr: ErrInvalidChecksum},
+		// 桁数・文字が ISBN でない
+		{in: "", wantErr: ErrInvalidFormat},
+		{in: "12345", wantErr: ErrInvalidFormat},
+		{in: "03064X6152", wantErr: ErrInvalidFormat},
+		{in: "978030640615X", wantErr: ErrInvalidFormat},
+		{in: "9770306406157", wantErr: ErrInvalidFormat},
+		{in: "0306406152a", wantErr: ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Normalize(%q) = %q, %v; want error %v", tt.in, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// AuthTokenRepo はログインセッションの永続化のインターフェース。トークンのハッシュで引く。
+type AuthTokenRepo interface {
+	Create(ctx context.Context, token *entity.AuthToken) error
+	FindByHash(ctx context.Context, hash string) (*entity.AuthToken, error)
+	Delete(ctx context.Context, hash string) error
+}
+
+const kindAuthToken = "AuthToken"
+
+type authTokenRepo struct{}
+
+func NewAuthTokenRepo() AuthTokenRepo {
+	return &authTokenRepo{}
+}
+
+func (r *authTokenRepo) Create(ctx context.Context, token *entity.AuthToken) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = ds.Put(ctx, datastore.NameKey(kindAuthToken, token.TokenHash, nil), token)
+	return err
+}
+
+func (r *authTokenRepo) FindByHash(ctx context.Context, hash string) (*entity.AuthToken, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token := &entity.AuthToken{}
+	if err := ds.Get(ctx, datastore.NameKey(kindAuthToken, hash, nil), token); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	token.TokenHash = hash
+	return token, nil
+}
+
+func (r *authTokenRepo) Delete(ctx context.Context, hash string) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return ds.Delete(ctx, datastore.NameKey(kindAuthToken, hash, nil))
+}
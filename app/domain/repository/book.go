@@ -3,25 +3,91 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"cloud.google.com/go/datastore"
+	"google.golang.org/api/iterator"
 
 	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/auth"
 	dsclient "github.com/sora-00/booktracker-api/app/infra/datastore"
 )
 
 // BookRepo は本の永続化のインターフェース。
 type BookRepo interface {
 	Create(ctx context.Context, book *entity.Book) error
-	Update(ctx context.Context, book *entity.Book) error
+	// Update は最新の本を読んで edit で書き換え、同じトランザクションで保存する（edit が返した status の履歴も）。本がなければ ErrNotFound。
+	Update(ctx context.Context, id int, edit BookEditor) (*entity.Book, error)
+	// FindAll・Query・FindByID などの読み書きはゴミ箱の本を含まない（ゴミ箱の本は ErrNotFound）。
 	FindAll(ctx context.Context) ([]entity.Book, error)
+	Query(ctx context.Context, q BookQuery) ([]entity.Book, string, error)
 	FindByID(ctx context.Context, id int) (*entity.Book, error)
-	Delete(ctx context.Context, id int) error
+	// Delete は本と子エンティティを完全に消す。ゴミ箱の本も消せる。check が nil でなければ、消す前に同じトランザクションで最新の本を確かめる。
+	Delete(ctx context.Context, id int, check BookCheck) error
+	// Trash は本に DeletedAt を付けてゴミ箱に入れる。子エンティティは残すので Restore で元に戻せる。
+	Trash(ctx context.Context, id int, at time.Time, check BookCheck) (*entity.Book, error)
+	// FindTrashed はゴミ箱の本を、ゴミ箱に入れた新しい順に返す。
+	FindTrashed(ctx context.Context) ([]entity.Book, error)
+	// Restore はゴミ箱の本を戻す。ゴミ箱になければ ErrTrashNotFound。
+	Restore(ctx context.Context, id int) (*entity.Book, error)
+	// PurgeTrashed は before より前にゴミ箱に入れた本を全ユーザー分完全に消し、消したものを返す。
+	PurgeTrashed(ctx context.Context, before time.Time) ([]PurgedBook, error)
+	// Merge は source の本の子エンティティ（読書記録・status の履歴・レビュー・ハイライト）を target に移して source を消す。
+	// どちらかの本がなければ ErrNotFound。
+	Merge(ctx context.Context, targetID, sourceID int, merge BookMerger) (*entity.Book, error)
+	// CreateMulti は books を1つのトランザクションでまとめて登録し（PutMulti）、それぞれに ID を入れる。
+	CreateMulti(ctx context.Context, books []*entity.Book) error
+	// UpdateMulti は ids の本を1つのトランザクションで読み（GetMulti）、edit で書き換えてまとめて保存する（PutMulti）。
+	// 本がない・edit がエラーを返した項目は errs に入れてその本だけ保存しない。atomic なら1件でもあれば何も保存せず、books は nil。
+	UpdateMulti(ctx context.Context, ids []int, edit BookBatchEditor, atomic bool) (books []*entity.Book, errs []error, err error)
+	// DeleteMulti は ids の本を1つのトランザクションで子エンティティごと完全に消す（DeleteMulti）。ない本は無視する。
+	DeleteMulti(ctx context.Context, ids []int) error
+}
+
+// BookEditor は Update のトランザクション内で最新の Book を受け取って書き換える。
+// status が変わったときはその履歴を返すと同じトランザクションで保存する。error を返すとトランザクションごと取り消す。
+type BookEditor func(book *entity.Book) (*entity.StatusChange, error)
+
+// BookBatchEditor は UpdateMulti のトランザクション内で ids[i] の最新の本を書き換える。返すものは BookEditor と同じ。
+type BookBatchEditor func(i int, book *entity.Book) (*entity.StatusChange, error)
+
+// BookMerger は Merge のトランザクション内で最新の target・source と、移した後の target の全読書記録を受け取って target を書き換える。
+// status が変わったときはその履歴を返すと同じトランザクションで保存する。error を返すとトランザクションごと取り消す。
+type BookMerger func(target, source *entity.Book, sessions []entity.ReadingSession) (*entity.StatusChange, error)
+
+// PurgedBook は PurgeTrashed で消した本と、その本のユーザーの ID。
+type PurgedBook struct {
+	UserID int
+	Book   entity.Book
+}
+
+// BookQuery は一覧取得の絞り込み・並び替え・ページング条件。空の項目は条件なし。
+// Sort は Datastore のプロパティ名（createdAt / updatedAt / targetCompleteDate / title）をそのまま使う。
+type BookQuery struct {
+	Status    entity.Status
+	Author    string
+	Publisher string
+	Shelf     string // 棚の名前。この棚に入っている本だけ
+	Tag       string // このタグが付いている本だけ
+	Sort      string
+	Desc      bool
+	Limit     int
+	PageToken string // 前ページの nextPageToken（Datastore のカーソル）
 }
 
 // ErrNotFound は対象が存在しないときに返す。controller で 404 に変換する。
 var ErrNotFound = errors.New("not found")
 
+// ErrSessionNotFound は本はあるが読書記録がないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrSessionNotFound = fmt.Errorf("reading session %w", ErrNotFound)
+
+// ErrTrashNotFound はゴミ箱に本がないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrTrashNotFound = fmt.Errorf("trashed book %w", ErrNotFound)
+
+// ErrInvalidPageToken は pageToken が解釈できないときに返す。controller で 400 に変換する。
+var ErrInvalidPageToken = errors.New("invalid pageToken")
+
 const kindBook = "Book"
 
 type bookRepo struct{}
@@ -33,6 +99,11 @@ func NewBookRepo() BookRepo {
 var errNoDatastore = errors.New("datastore client not found in context")
 
 func (r *bookRepo) ds(ctx context.Context) (*datastore.Client, error) {
+	return clientFromContext(ctx)
+}
+
+// clientFromContext は middleware で context に載せた Datastore クライアントを取り出す。各 repository 実装で共通。
+func clientFromContext(ctx context.Context) (*datastore.Client, error) {
 	ds, ok := dsclient.FromContext(ctx)
 	if !ok {
 		return nil, errNoDatastore
@@ -40,12 +111,78 @@ func (r *bookRepo) ds(ctx context.Context) (*datastore.Client, error) {
 	return ds, nil
 }
 
+var errNoUser = errors.New("login user not found in context")
+
+// userKey はログイン中のユーザーの Key。本とその子エンティティはすべてこの Key の子孫として保存するので、
+// 他のユーザーの本は Key が一致せず読み書きできない。
+func userKey(ctx context.Context) (*datastore.Key, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	return datastore.IDKey(kindUser, int64(user.ID), nil), nil
+}
+
+func bookKey(ctx context.Context, id int) (*datastore.Key, error) {
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datastore.IDKey(kindBook, int64(id), uk), nil
+}
+
+// getBook は bk の本を読む。本がないかゴミ箱に入っていれば ErrNotFound。tx が nil でなければトランザクションの中で読む。
+// 本やその子エンティティを扱う repository の操作は、Delete と trash の操作以外はこれで本を確かめる。
+func getBook(ctx context.Context, ds *datastore.Client, tx *datastore.Transaction, bk *datastore.Key, book *entity.Book) error {
+	var err error
+	if tx != nil {
+		err = tx.Get(bk, book)
+	} else {
+		err = ds.Get(ctx, bk, book)
+	}
+	if err == datastore.ErrNoSuchEntity || err == nil && !book.DeletedAt.IsZero() {
+		return ErrNotFound
+	}
+	return err
+}
+
+// trashedBookIDs はログイン中のユーザーのゴミ箱の本の ID。子エンティティをユーザー全体の祖先クエリで読むときに、
+// ゴミ箱の本の分を除くのに使う（index.yaml に deletedAt の複合インデックスが必要）。
+func trashedBookIDs(ctx context.Context, ds *datastore.Client, uk *datastore.Key) (map[int]bool, error) {
+	q := datastore.NewQuery(kindBook).Ancestor(uk).FilterField("deletedAt", ">", time.Time{}).Order("-deletedAt").KeysOnly()
+	keys, err := ds.GetAll(ctx, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		ids[int(key.ID)] = true
+	}
+	return ids, nil
+}
+
+// withoutTrashed は items からゴミ箱の本（trashed）の子エンティティを除く。bookID は子エンティティの本の ID を返す。
+func withoutTrashed[T any](items []T, trashed map[int]bool, bookID func(T) int) []T {
+	live := items[:0]
+	for _, item := range items {
+		if !trashed[bookID(item)] {
+			live = append(live, item)
+		}
+	}
+	return live
+}
+
 func (r *bookRepo) Create(ctx context.Context, book *entity.Book) error {
 	ds, err := r.ds(ctx)
 	if err != nil {
 		return err
 	}
-	key := datastore.IncompleteKey(kindBook, nil)
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	key := datastore.IncompleteKey(kindBook, uk)
+	book.Version = 1
 	key, err = ds.Put(ctx, key, book)
 	if err != nil {
 		return err
@@ -54,14 +191,39 @@ func (r *bookRepo) Create(ctx context.Context, book *entity.Book) error {
 	return nil
 }
 
-func (r *bookRepo) Update(ctx context.Context, book *entity.Book) error {
+// Update は別の端末からの更新を上書きしないよう、読んでから書くまでをトランザクションにする。
+// 本を書き換える repository の操作はどれも version を 1 増やす。
+func (r *bookRepo) Update(ctx context.Context, id int, edit BookEditor) (*entity.Book, error) {
 	ds, err := r.ds(ctx)
 	if err != nil {
+		return nil, err
+	}
+	key, err := bookKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var book entity.Book
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		book = entity.Book{}
+		if err := getBook(ctx, ds, tx, key, &book); err != nil {
+			return err
+		}
+		book.ID = id
+		change, err := edit(&book)
+		if err != nil {
+			return err
+		}
+		if err := putStatusChange(tx, key, change); err != nil {
+			return err
+		}
+		book.Version++
+		_, err = tx.Put(key, &book)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	key := datastore.IDKey(kindBook, int64(book.ID), nil)
-	_, err = ds.Put(ctx, key, book)
-	return err
+	return &book, nil
 }
 
 func (r *bookRepo) FindAll(ctx context.Context) ([]entity.Book, error) {
@@ -69,16 +231,102 @@ func (r *bookRepo) FindAll(ctx context.Context) ([]entity.Book, error) {
 	if err != nil {
 		return nil, err
 	}
-	q := datastore.NewQuery(kindBook).Order("createdAt")
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := datastore.NewQuery(kindBook).Ancestor(uk).Order("createdAt")
 	var books []entity.Book
 	keys, err := ds.GetAll(ctx, q, &books)
 	if err != nil {
 		return nil, err
 	}
+	// deletedAt のない古い本もあるので、ゴミ箱の本はクエリのフィルタではなく読んでから除く
+	live := books[:0]
 	for i := range keys {
-		books[i].ID = int(keys[i].ID)
+		if books[i].DeletedAt.IsZero() {
+			books[i].ID = int(keys[i].ID)
+			live = append(live, books[i])
+		}
 	}
-	return books, nil
+	return live, nil
+}
+
+// Query は絞り込み条件を Datastore のクエリに変換して1ページ分返す。続きがあれば nextPageToken を返す。
+// 等価フィルタと並び替えの組み合わせには index.yaml の複合インデックスが必要。
+func (r *bookRepo) Query(ctx context.Context, bq BookQuery) ([]entity.Book, string, error) {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	q := datastore.NewQuery(kindBook).Ancestor(uk)
+	if bq.Status != "" {
+		q = q.FilterField("status", "=", string(bq.Status))
+	}
+	if bq.Author != "" {
+		q = q.FilterField("author", "=", bq.Author)
+	}
+	if bq.Publisher != "" {
+		q = q.FilterField("publisher", "=", bq.Publisher)
+	}
+	// shelves / tags は複数値のプロパティなので、等価フィルタはどれか1つが一致すれば当たる
+	if bq.Shelf != "" {
+		q = q.FilterField("shelves", "=", bq.Shelf)
+	}
+	if bq.Tag != "" {
+		q = q.FilterField("tags", "=", bq.Tag)
+	}
+	order := bq.Sort
+	if order == "" {
+		order = "createdAt"
+	}
+	if bq.Desc {
+		order = "-" + order
+	}
+	q = q.Order(order)
+	if bq.PageToken != "" {
+		cursor, err := datastore.DecodeCursor(bq.PageToken)
+		if err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		q = q.Start(cursor)
+	}
+
+	// ゴミ箱の本を読み飛ばすので件数は Limit で絞らず、limit+1 件目の生きている本が見つかったら次ページありとする
+	var books []entity.Book
+	var cursor datastore.Cursor
+	next := ""
+	it := ds.Run(ctx, q)
+	for {
+		var b entity.Book
+		key, err := it.Next(&b)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if !b.DeletedAt.IsZero() {
+			continue
+		}
+		if bq.Limit > 0 && len(books) == bq.Limit {
+			// limit+1 件目が取れた = 続きがあるので limit 件目直後のカーソルを返す
+			next = cursor.String()
+			break
+		}
+		b.ID = int(key.ID)
+		books = append(books, b)
+		if bq.Limit > 0 && len(books) == bq.Limit {
+			if cursor, err = it.Cursor(); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return books, next, nil
 }
 
 func (r *bookRepo) FindByID(ctx context.Context, id int) (*entity.Book, error) {
@@ -86,26 +334,260 @@ func (r *bookRepo) FindByID(ctx context.Context, id int) (*entity.Book, error) {
 	if err != nil {
 		return nil, err
 	}
-	key := datastore.IDKey(kindBook, int64(id), nil)
+	key, err := bookKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 	book := &entity.Book{}
-	if err := ds.Get(ctx, key, book); err != nil {
-		if err == datastore.ErrNoSuchEntity {
-			return nil, ErrNotFound
-		}
+	if err := getBook(ctx, ds, nil, key, book); err != nil {
 		return nil, err
 	}
 	book.ID = int(key.ID)
 	return book, nil
 }
 
-func (r *bookRepo) Delete(ctx context.Context, id int) error {
+func (r *bookRepo) Delete(ctx context.Context, id int, check BookCheck) error {
 	ds, err := r.ds(ctx)
 	if err != nil {
 		return err
 	}
-	if _, err := r.FindByID(ctx, id); err != nil {
+	key, err := bookKey(ctx, id)
+	if err != nil {
 		return err
 	}
-	key := datastore.IDKey(kindBook, int64(id), nil)
-	return ds.Delete(ctx, key)
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var book entity.Book
+		if err := tx.Get(key, &book); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrNotFound
+			}
+			return err
+		}
+		book.ID = id
+		if check != nil {
+			if err := check(&book); err != nil {
+				return err
+			}
+		}
+		return deleteBook(ctx, ds, tx, key)
+	})
+	return err
+}
+
+// deleteBook は読書記録などの子エンティティもまとめて消す（kind なしの祖先クエリは Book 自身も含む）。
+func deleteBook(ctx context.Context, ds *datastore.Client, tx *datastore.Transaction, key *datastore.Key) error {
+	q := datastore.NewQuery("").Ancestor(key).KeysOnly().Transaction(tx)
+	keys, err := ds.GetAll(ctx, q, nil)
+	if err != nil {
+		return err
+	}
+	return tx.DeleteMulti(keys)
+}
+
+func (r *bookRepo) Trash(ctx context.Context, id int, at time.Time, check BookCheck) (*entity.Book, error) {
+	return r.Update(ctx, id, func(book *entity.Book) (*entity.StatusChange, error) {
+		if check != nil {
+			if err := check(book); err != nil {
+				return nil, err
+			}
+		}
+		book.DeletedAt = at
+		return nil, nil
+	})
+}
+
+// FindTrashed は deletedAt があってゼロ値より後の本を探す（index.yaml に deletedAt の複合インデックスが必要）。
+func (r *bookRepo) FindTrashed(ctx context.Context) ([]entity.Book, error) {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := datastore.NewQuery(kindBook).Ancestor(uk).FilterField("deletedAt", ">", time.Time{}).Order("-deletedAt")
+	var books []entity.Book
+	keys, err := ds.GetAll(ctx, q, &books)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		books[i].ID = int(keys[i].ID)
+	}
+	return books, nil
+}
+
+func (r *bookRepo) Restore(ctx context.Context, id int) (*entity.Book, error) {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := bookKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var book entity.Book
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		book = entity.Book{}
+		if err := tx.Get(key, &book); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrTrashNotFound
+			}
+			return err
+		}
+		if book.DeletedAt.IsZero() {
+			return ErrTrashNotFound
+		}
+		book.ID = id
+		book.DeletedAt = time.Time{}
+		book.Version++
+		_, err := tx.Put(key, &book)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// PurgeTrashed はユーザーをまたぐので祖先なしのクエリで探す（deletedAt だけのフィルタなので組み込みのインデックスで足りる）。
+// 探してから消すまでの間に戻された本は、トランザクション内で読み直して残す。
+func (r *bookRepo) PurgeTrashed(ctx context.Context, before time.Time) ([]PurgedBook, error) {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := datastore.NewQuery(kindBook).
+		FilterField("deletedAt", ">", time.Time{}).
+		FilterField("deletedAt", "<", before).
+		KeysOnly()
+	keys, err := ds.GetAll(ctx, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	var purged []PurgedBook
+	for _, key := range keys {
+		var book entity.Book
+		_, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+			book = entity.Book{}
+			if err := tx.Get(key, &book); err != nil {
+				return err
+			}
+			if book.DeletedAt.IsZero() || !book.DeletedAt.Before(before) {
+				return errBookRestored
+			}
+			return deleteBook(ctx, ds, tx, key)
+		})
+		if err == errBookRestored || err == datastore.ErrNoSuchEntity {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		book.ID = int(key.ID)
+		purged = append(purged, PurgedBook{UserID: int(key.Parent.ID), Book: book})
+	}
+	return purged, nil
+}
+
+// errBookRestored は PurgeTrashed のトランザクションを何も書かずに抜けるための内部エラー。
+var errBookRestored = errors.New("book restored")
+
+// Merge は子エンティティを kind ごとの型を使わず PropertyList のまま target の下に付け直す（Key の ID は変わる）。
+// レビューは1冊に1つなので、target にすでにあれば source のものは捨てる。
+// 1つのトランザクションで書けるのは 500 エンティティまでなので、それより子エンティティの多い本はまとめられない。
+func (r *bookRepo) Merge(ctx context.Context, targetID, sourceID int, merge BookMerger) (*entity.Book, error) {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tk, err := bookKey(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := bookKey(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	sessionRepo := &readingSessionRepo{}
+	var target entity.Book
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		target = entity.Book{}
+		var source entity.Book
+		for _, b := range []struct {
+			key  *datastore.Key
+			book *entity.Book
+		}{{tk, &target}, {sk, &source}} {
+			if err := getBook(ctx, ds, tx, b.key, b.book); err != nil {
+				return err
+			}
+		}
+		target.ID, source.ID = targetID, sourceID
+
+		hasReview := true
+		if err := tx.Get(datastore.NameKey(kindReview, reviewKeyName, tk), &entity.Review{}); err == datastore.ErrNoSuchEntity {
+			hasReview = false
+		} else if err != nil {
+			return err
+		}
+		// kind なしの祖先クエリは source 自身も含む
+		keys, err := ds.GetAll(ctx, datastore.NewQuery("").Ancestor(sk).KeysOnly().Transaction(tx), nil)
+		if err != nil {
+			return err
+		}
+		children := make([]datastore.PropertyList, len(keys))
+		if err := tx.GetMulti(keys, children); err != nil {
+			return err
+		}
+		var newKeys []*datastore.Key
+		var moved []datastore.PropertyList
+		for i, key := range keys {
+			switch {
+			case key.Equal(sk):
+				continue
+			case key.Kind == kindReview:
+				if hasReview {
+					continue
+				}
+				newKeys = append(newKeys, datastore.NameKey(kindReview, reviewKeyName, tk))
+			default:
+				newKeys = append(newKeys, datastore.IncompleteKey(key.Kind, tk))
+			}
+			moved = append(moved, children[i])
+		}
+
+		sessions, err := sessionRepo.findAll(ctx, ds, tx, tk)
+		if err != nil {
+			return err
+		}
+		sourceSessions, err := sessionRepo.findAll(ctx, ds, tx, sk)
+		if err != nil {
+			return err
+		}
+		for _, s := range sourceSessions {
+			s.BookID = targetID
+			sessions = append(sessions, s)
+		}
+		change, err := merge(&target, &source, sessions)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.PutMulti(newKeys, moved); err != nil {
+			return err
+		}
+		if err := tx.DeleteMulti(keys); err != nil {
+			return err
+		}
+		if err := putStatusChange(tx, tk, change); err != nil {
+			return err
+		}
+		target.Version++
+		_, err = tx.Put(tk, &target)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
 }
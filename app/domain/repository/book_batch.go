@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+func (r *bookRepo) CreateMulti(ctx context.Context, books []*entity.Book) error {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	keys := make([]*datastore.Key, len(books))
+	for i, book := range books {
+		keys[i] = datastore.IncompleteKey(kindBook, uk)
+		book.Version = 1
+	}
+	var pks []*datastore.PendingKey
+	commit, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var err error
+		pks, err = tx.PutMulti(keys, books)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, pk := range pks {
+		books[i].ID = int(commit.Key(pk).ID)
+	}
+	return nil
+}
+
+// UpdateMulti は Update と同じく読んでから書くまでをトランザクションにする。
+// 1つのトランザクションで書けるのは 500 エンティティまでなので、件数は呼び出し側で絞る（status の履歴も1件ずつ書く）。
+func (r *bookRepo) UpdateMulti(ctx context.Context, ids []int, edit BookBatchEditor, atomic bool) ([]*entity.Book, []error, error) {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make([]*datastore.Key, len(ids))
+	for i, id := range ids {
+		if keys[i], err = bookKey(ctx, id); err != nil {
+			return nil, nil, err
+		}
+	}
+	var books []*entity.Book
+	var errs []error
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		books = make([]*entity.Book, len(ids))
+		errs = make([]error, len(ids))
+		got := make([]entity.Book, len(ids))
+		var getErrs datastore.MultiError
+		if err := tx.GetMulti(keys, got); err != nil {
+			var ok bool
+			if getErrs, ok = err.(datastore.MultiError); !ok {
+				return err
+			}
+		}
+		var putKeys []*datastore.Key
+		var putBooks []*entity.Book
+		failed := false
+		for i := range ids {
+			if getErrs != nil && getErrs[i] != nil && getErrs[i] != datastore.ErrNoSuchEntity {
+				return getErrs[i]
+			}
+			book := &got[i]
+			// ない本とゴミ箱の本は getBook と同じく ErrNotFound
+			if getErrs != nil && getErrs[i] != nil || !book.DeletedAt.IsZero() {
+				errs[i], failed = ErrNotFound, true
+				continue
+			}
+			book.ID = ids[i]
+			change, err := edit(i, book)
+			if err != nil {
+				errs[i], failed = err, true
+				continue
+			}
+			if err := putStatusChange(tx, keys[i], change); err != nil {
+				return err
+			}
+			book.Version++
+			books[i] = book
+			putKeys = append(putKeys, keys[i])
+			putBooks = append(putBooks, book)
+		}
+		if failed && atomic {
+			return errBatchFailed
+		}
+		_, err := tx.PutMulti(putKeys, putBooks)
+		return err
+	})
+	if err == errBatchFailed {
+		return nil, errs, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return books, errs, nil
+}
+
+// errBatchFailed は atomic な UpdateMulti のトランザクションを何も書かずに抜けるための内部エラー。
+var errBatchFailed = errors.New("batch failed")
+
+// DeleteMulti は Delete と同じく kind なしの祖先クエリで本自身と子エンティティの Key を集めて消す。
+func (r *bookRepo) DeleteMulti(ctx context.Context, ids []int) error {
+	ds, err := r.ds(ctx)
+	if err != nil {
+		return err
+	}
+	keys := make([]*datastore.Key, len(ids))
+	for i, id := range ids {
+		if keys[i], err = bookKey(ctx, id); err != nil {
+			return err
+		}
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var all []*datastore.Key
+		for _, key := range keys {
+			q := datastore.NewQuery("").Ancestor(key).KeysOnly().Transaction(tx)
+			children, err := ds.GetAll(ctx, q, nil)
+			if err != nil {
+				return err
+			}
+			all = append(all, children...)
+		}
+		return tx.DeleteMulti(all)
+	})
+	return err
+}
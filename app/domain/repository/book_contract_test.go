@@ -0,0 +1,624 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	dsclient "github.com/sora-00/booktracker-api/app/infra/datastore"
+)
+
+// BookRepo の実装（インメモリ・Datastore）が同じ振る舞いをするかを確かめる。
+// Datastore 実装は DATASTORE_EMULATOR_HOST があるときだけ動かす（gcloud beta emulators datastore start）。
+
+func TestMemoryBookRepoContract(t *testing.T) {
+	repo := NewMemoryBookRepo()
+	runBookRepoContract(t, repo, NewMemoryReadingSessionRepo(repo), context.Background())
+}
+
+func TestDatastoreBookRepoContract(t *testing.T) {
+	if os.Getenv("DATASTORE_EMULATOR_HOST") == "" {
+		t.Skip("DATASTORE_EMULATOR_HOST is not set")
+	}
+	ctx := context.Background()
+	ds, err := dsclient.NewClient(ctx)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { ds.Close() })
+	runBookRepoContract(t, NewBookRepo(), NewReadingSessionRepo(), dsclient.WithContext(ctx, ds))
+}
+
+// runBookRepoContract は base に実装が必要とするもの（Datastore クライアントなど）を入れて渡す。sessions は Merge の確認に使う、repo と同じ保存先の実装。
+// 実行ごとに別のユーザーにするので、エミュレータにデータが残っていても結果は変わらない。
+func runBookRepoContract(t *testing.T, repo BookRepo, sessions ReadingSessionRepo, base context.Context) {
+	firstUserID := int(time.Now().UnixNano() % (1 << 40))
+	users := 0
+	newUserCtx := func(t *testing.T) context.Context {
+		t.Helper()
+		users++
+		return auth.WithUser(base, &entity.User{ID: firstUserID + users})
+	}
+	newBook := func(title string, createdAt time.Time) *entity.Book {
+		return &entity.Book{
+			Title:      title,
+			Author:     "author",
+			TotalPages: 100,
+			Publisher:  "publisher",
+			Status:     entity.StatusUnread,
+			CreatedAt:  createdAt,
+			UpdatedAt:  createdAt,
+		}
+	}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Create assigns IDs", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		a, b := newBook("a", day), newBook("b", day)
+		for _, book := range []*entity.Book{a, b} {
+			if err := repo.Create(ctx, book); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		if a.ID == 0 || b.ID == 0 || a.ID == b.ID {
+			t.Fatalf("IDs = %d, %d; want distinct non-zero IDs", a.ID, b.ID)
+		}
+		if a.Version != 1 {
+			t.Errorf("Version = %d, want 1", a.Version)
+		}
+		got, err := repo.FindByID(ctx, a.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.ID != a.ID || got.Title != "a" {
+			t.Errorf("FindByID = {ID: %d, Title: %q}, want {ID: %d, Title: %q}", got.ID, got.Title, a.ID, "a")
+		}
+	})
+
+	t.Run("FindAll orders by createdAt", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		// 登録した順と createdAt の順を変えておく
+		for _, b := range []*entity.Book{
+			newBook("second", day.Add(2*time.Hour)),
+			newBook("third", day.Add(3*time.Hour)),
+			newBook("first", day.Add(time.Hour)),
+		} {
+			if err := repo.Create(ctx, b); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		books, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		var titles []string
+		for _, b := range books {
+			if b.ID == 0 {
+				t.Errorf("FindAll returned %q without ID", b.Title)
+			}
+			titles = append(titles, b.Title)
+		}
+		want := []string{"first", "second", "third"}
+		if len(titles) != len(want) {
+			t.Fatalf("FindAll titles = %v, want %v", titles, want)
+		}
+		for i := range want {
+			if titles[i] != want[i] {
+				t.Fatalf("FindAll titles = %v, want %v", titles, want)
+			}
+		}
+	})
+
+	t.Run("FindAll is scoped to the user", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		if err := repo.Create(ctx, newBook("mine", day)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		other := newUserCtx(t)
+		books, err := repo.FindAll(other)
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		if len(books) != 0 {
+			t.Errorf("FindAll for another user = %d books, want 0", len(books))
+		}
+	})
+
+	t.Run("missing book is ErrNotFound", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		book := newBook("gone", day)
+		if err := repo.Create(ctx, book); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repo.Delete(ctx, book.ID, nil); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.FindByID(ctx, book.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByID after Delete: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, book.ID, nil); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete twice: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.FindByID(ctx, 987654321); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByID unknown id: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("trashed book is ErrNotFound", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		book := newBook("trashed", day)
+		if err := repo.Create(ctx, book); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := repo.Trash(ctx, book.ID, time.Now(), nil); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+		if _, err := repo.FindByID(ctx, book.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByID trashed: err = %v, want ErrNotFound", err)
+		}
+		books, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		if len(books) != 0 {
+			t.Errorf("FindAll with a trashed book = %d books, want 0", len(books))
+		}
+	})
+
+	create := func(t *testing.T, ctx context.Context, books ...*entity.Book) {
+		t.Helper()
+		for _, b := range books {
+			if err := repo.Create(ctx, b); err != nil {
+				t.Fatalf("Create %q: %v", b.Title, err)
+			}
+		}
+	}
+	errEdit := errors.New("edit failed")
+
+	t.Run("Query filters and sorts", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		a := newBook("a", day.Add(3*time.Hour))
+		a.Status, a.Author, a.Shelves, a.Tags = entity.StatusReading, "murakami", []string{"desk"}, []string{"novel", "fav"}
+		a.TargetCompleteDate, a.UpdatedAt = day.AddDate(0, 0, 20), day.AddDate(0, 0, 1)
+		b := newBook("b", day.Add(time.Hour))
+		b.Status, b.Publisher, b.Tags = entity.StatusReading, "shinchosha", []string{"novel"}
+		b.TargetCompleteDate, b.UpdatedAt = day.AddDate(0, 0, 10), day.AddDate(0, 0, 3)
+		c := newBook("c", day.Add(2*time.Hour))
+		c.Author, c.Shelves = "murakami", []string{"desk", "bed"}
+		c.TargetCompleteDate, c.UpdatedAt = day.AddDate(0, 0, 30), day.AddDate(0, 0, 2)
+		trashed := newBook("trashed", day)
+		trashed.Status, trashed.Author = entity.StatusReading, "murakami"
+		create(t, ctx, a, b, c, trashed)
+		if _, err := repo.Trash(ctx, trashed.ID, day, nil); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+
+		tests := []struct {
+			name string
+			q    BookQuery
+			want []string
+		}{
+			{name: "no filter orders by createdAt", q: BookQuery{}, want: []string{"b", "c", "a"}},
+			{name: "status", q: BookQuery{Status: entity.StatusReading}, want: []string{"b", "a"}},
+			{name: "author", q: BookQuery{Author: "murakami"}, want: []string{"c", "a"}},
+			{name: "publisher", q: BookQuery{Publisher: "shinchosha"}, want: []string{"b"}},
+			{name: "shelf", q: BookQuery{Shelf: "desk"}, want: []string{"c", "a"}},
+			{name: "tag", q: BookQuery{Tag: "novel"}, want: []string{"b", "a"}},
+			{name: "filters combine", q: BookQuery{Status: entity.StatusReading, Tag: "novel", Author: "murakami"}, want: []string{"a"}},
+			{name: "no match", q: BookQuery{Shelf: "kitchen"}, want: nil},
+			{name: "title desc", q: BookQuery{Sort: "title", Desc: true}, want: []string{"c", "b", "a"}},
+			{name: "updatedAt", q: BookQuery{Sort: "updatedAt"}, want: []string{"a", "c", "b"}},
+			{name: "targetCompleteDate desc", q: BookQuery{Sort: "targetCompleteDate", Desc: true}, want: []string{"c", "a", "b"}},
+			{name: "filter and sort", q: BookQuery{Author: "murakami", Sort: "title", Desc: true}, want: []string{"c", "a"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				books, next, err := repo.Query(ctx, tt.q)
+				if err != nil {
+					t.Fatalf("Query: %v", err)
+				}
+				if got := bookTitles(books); !slices.Equal(got, tt.want) {
+					t.Errorf("Query titles = %v, want %v", got, tt.want)
+				}
+				if next != "" {
+					t.Errorf("nextPageToken = %q, want none", next)
+				}
+			})
+		}
+	})
+
+	t.Run("Query pages with pageToken", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		var want []string
+		for i := range 5 {
+			title := string(rune('a' + i))
+			create(t, ctx, newBook(title, day.Add(time.Duration(i)*time.Hour)))
+			want = append(want, title)
+		}
+		// 最後の本をゴミ箱に入れる。ゴミ箱の本しか残っていなければ次のページはない
+		trashed := newBook("trashed", day.Add(10*time.Hour))
+		create(t, ctx, trashed)
+		if _, err := repo.Trash(ctx, trashed.ID, day, nil); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+
+		for _, limit := range []int{2, 5} {
+			var got []string
+			var tokens []string
+			token := ""
+			for {
+				books, next, err := repo.Query(ctx, BookQuery{Limit: limit, PageToken: token})
+				if err != nil {
+					t.Fatalf("Query limit %d page %d: %v", limit, len(tokens)+1, err)
+				}
+				if len(books) > limit {
+					t.Fatalf("Query limit %d returned %d books", limit, len(books))
+				}
+				got = append(got, bookTitles(books)...)
+				if next == "" {
+					break
+				}
+				tokens = append(tokens, next)
+				if len(tokens) > 5 {
+					t.Fatalf("Query limit %d did not stop paging: %v", limit, got)
+				}
+				token = next
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Query limit %d pages = %v, want %v", limit, got, want)
+			}
+			if wantPages := (len(want) + limit - 1) / limit; len(tokens)+1 != wantPages {
+				t.Errorf("Query limit %d returned %d pages, want %d", limit, len(tokens)+1, wantPages)
+			}
+		}
+
+		if _, _, err := repo.Query(ctx, BookQuery{PageToken: "%%%"}); !errors.Is(err, ErrInvalidPageToken) {
+			t.Errorf("Query with a broken pageToken: err = %v, want ErrInvalidPageToken", err)
+		}
+	})
+
+	t.Run("Update edits in place", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		book := newBook("before", day)
+		create(t, ctx, book)
+		updated, err := repo.Update(ctx, book.ID, func(b *entity.Book) (*entity.StatusChange, error) {
+			if b.ID != book.ID || b.Title != "before" {
+				t.Errorf("editor got {ID: %d, Title: %q}, want {ID: %d, Title: %q}", b.ID, b.Title, book.ID, "before")
+			}
+			b.Title = "after"
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.ID != book.ID || updated.Title != "after" || updated.Version != 2 {
+			t.Errorf("Update = {ID: %d, Title: %q, Version: %d}, want {ID: %d, Title: %q, Version: 2}", updated.ID, updated.Title, updated.Version, book.ID, "after")
+		}
+		got, err := repo.FindByID(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.Title != "after" || got.Version != 2 {
+			t.Errorf("FindByID after Update = {Title: %q, Version: %d}, want {Title: %q, Version: 2}", got.Title, got.Version, "after")
+		}
+	})
+
+	t.Run("failed editor or check changes nothing", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		book := newBook("keep", day)
+		create(t, ctx, book)
+		failingCheck := func(*entity.Book) error { return errEdit }
+
+		_, err := repo.Update(ctx, book.ID, func(b *entity.Book) (*entity.StatusChange, error) {
+			b.Title = "changed"
+			return nil, errEdit
+		})
+		if !errors.Is(err, errEdit) {
+			t.Errorf("Update with a failing editor: err = %v, want %v", err, errEdit)
+		}
+		if _, err := repo.Trash(ctx, book.ID, day, failingCheck); !errors.Is(err, errEdit) {
+			t.Errorf("Trash with a failing check: err = %v, want %v", err, errEdit)
+		}
+		if err := repo.Delete(ctx, book.ID, failingCheck); !errors.Is(err, errEdit) {
+			t.Errorf("Delete with a failing check: err = %v, want %v", err, errEdit)
+		}
+		got, err := repo.FindByID(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.Title != "keep" || got.Version != 1 {
+			t.Errorf("FindByID = {Title: %q, Version: %d}, want {Title: %q, Version: 1}", got.Title, got.Version, "keep")
+		}
+
+		if _, err := repo.Update(ctx, 987654321, func(*entity.Book) (*entity.StatusChange, error) {
+			t.Error("editor called for a missing book")
+			return nil, nil
+		}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Update unknown id: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Trash, FindTrashed and Restore", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		older, newer, live := newBook("older", day), newBook("newer", day), newBook("live", day)
+		create(t, ctx, older, newer, live)
+		for _, trash := range []struct {
+			book *entity.Book
+			at   time.Time
+		}{{older, day.Add(time.Hour)}, {newer, day.Add(2 * time.Hour)}} {
+			trashed, err := repo.Trash(ctx, trash.book.ID, trash.at, nil)
+			if err != nil {
+				t.Fatalf("Trash: %v", err)
+			}
+			if !trashed.DeletedAt.Equal(trash.at) || trashed.Version != 2 {
+				t.Errorf("Trash = {DeletedAt: %v, Version: %d}, want {DeletedAt: %v, Version: 2}", trashed.DeletedAt, trashed.Version, trash.at)
+			}
+		}
+
+		books, err := repo.FindTrashed(ctx)
+		if err != nil {
+			t.Fatalf("FindTrashed: %v", err)
+		}
+		if got, want := bookTitles(books), []string{"newer", "older"}; !slices.Equal(got, want) {
+			t.Fatalf("FindTrashed titles = %v, want %v", got, want)
+		}
+		if books[0].ID != newer.ID || !books[0].DeletedAt.Equal(day.Add(2*time.Hour)) {
+			t.Errorf("FindTrashed[0] = {ID: %d, DeletedAt: %v}, want {ID: %d, DeletedAt: %v}", books[0].ID, books[0].DeletedAt, newer.ID, day.Add(2*time.Hour))
+		}
+		if other, err := repo.FindTrashed(newUserCtx(t)); err != nil || len(other) != 0 {
+			t.Errorf("FindTrashed for another user = %d books, %v; want 0", len(other), err)
+		}
+
+		restored, err := repo.Restore(ctx, older.ID)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if restored.ID != older.ID || !restored.DeletedAt.IsZero() || restored.Version != 3 {
+			t.Errorf("Restore = {ID: %d, DeletedAt: %v, Version: %d}, want {ID: %d, DeletedAt: zero, Version: 3}", restored.ID, restored.DeletedAt, restored.Version, older.ID)
+		}
+		if _, err := repo.FindByID(ctx, older.ID); err != nil {
+			t.Errorf("FindByID after Restore: %v", err)
+		}
+		for name, id := range map[string]int{"live": live.ID, "restored": older.ID, "unknown": 987654321} {
+			if _, err := repo.Restore(ctx, id); !errors.Is(err, ErrTrashNotFound) {
+				t.Errorf("Restore %s book: err = %v, want ErrTrashNotFound", name, err)
+			}
+		}
+	})
+
+	t.Run("PurgeTrashed deletes books trashed before the cutoff", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		user, _ := auth.UserFromContext(ctx)
+		old, recent, live := newBook("old", day), newBook("recent", day), newBook("live", day)
+		create(t, ctx, old, recent, live)
+		cutoff := day.Add(time.Hour)
+		if _, err := repo.Trash(ctx, old.ID, cutoff.Add(-time.Second), nil); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+		if _, err := repo.Trash(ctx, recent.ID, cutoff, nil); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+
+		// PurgeTrashed は全ユーザーが対象なので、このユーザーの分だけを見る。
+		// 祖先なしのクエリで探すので、エミュレータは --consistency=1.0 で起動しておく
+		purged, err := repo.PurgeTrashed(base, cutoff)
+		if err != nil {
+			t.Fatalf("PurgeTrashed: %v", err)
+		}
+		var mine []string
+		for _, p := range purged {
+			if p.UserID == user.ID {
+				if p.Book.ID == 0 {
+					t.Errorf("PurgeTrashed returned %q without ID", p.Book.Title)
+				}
+				mine = append(mine, p.Book.Title)
+			}
+		}
+		if want := []string{"old"}; !slices.Equal(mine, want) {
+			t.Errorf("PurgeTrashed titles = %v, want %v", mine, want)
+		}
+		books, err := repo.FindTrashed(ctx)
+		if err != nil {
+			t.Fatalf("FindTrashed: %v", err)
+		}
+		if got, want := bookTitles(books), []string{"recent"}; !slices.Equal(got, want) {
+			t.Errorf("FindTrashed after PurgeTrashed = %v, want %v", got, want)
+		}
+		if _, err := repo.Restore(ctx, old.ID); !errors.Is(err, ErrTrashNotFound) {
+			t.Errorf("Restore purged book: err = %v, want ErrTrashNotFound", err)
+		}
+		if _, err := repo.FindByID(ctx, live.ID); err != nil {
+			t.Errorf("FindByID live book after PurgeTrashed: %v", err)
+		}
+	})
+
+	t.Run("Merge moves sessions and deletes the source", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		target, source, other := newBook("target", day), newBook("source", day), newBook("other", day)
+		create(t, ctx, target, source, other)
+		keepBook := func(b *entity.Book, _ []entity.ReadingSession) (*entity.StatusChange, error) { return nil, nil }
+		for i, id := range []int{target.ID, source.ID} {
+			start := day.Add(time.Duration(i+1) * time.Hour)
+			s := &entity.ReadingSession{StartPage: 1 + 10*i, EndPage: 10 + 10*i, StartedAt: start, EndedAt: start.Add(30 * time.Minute), CreatedAt: start}
+			if _, err := sessions.Create(ctx, id, s, keepBook); err != nil {
+				t.Fatalf("Create session: %v", err)
+			}
+		}
+
+		// merge が失敗すれば何も変わらない
+		_, err := repo.Merge(ctx, target.ID, source.ID, func(tb, _ *entity.Book, _ []entity.ReadingSession) (*entity.StatusChange, error) {
+			tb.Title = "changed"
+			return nil, errEdit
+		})
+		if !errors.Is(err, errEdit) {
+			t.Errorf("Merge with a failing merger: err = %v, want %v", err, errEdit)
+		}
+		if got, err := repo.FindByID(ctx, source.ID); err != nil || got.Title != "source" {
+			t.Errorf("FindByID source after a failed Merge = %+v, %v; want the source unchanged", got, err)
+		}
+		before, err := repo.FindByID(ctx, target.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if before.Title != "target" {
+			t.Errorf("target title after a failed Merge = %q, want %q", before.Title, "target")
+		}
+		if got, err := sessions.FindByBookID(ctx, target.ID); err != nil || len(got) != 1 {
+			t.Errorf("target sessions after a failed Merge = %d, %v; want 1", len(got), err)
+		}
+
+		merged, err := repo.Merge(ctx, target.ID, source.ID, func(tb, sb *entity.Book, ss []entity.ReadingSession) (*entity.StatusChange, error) {
+			if tb.ID != target.ID || sb.ID != source.ID {
+				t.Errorf("merger got target %d, source %d; want %d, %d", tb.ID, sb.ID, target.ID, source.ID)
+			}
+			if len(ss) != 2 {
+				t.Errorf("merger got %d sessions, want 2", len(ss))
+			}
+			for _, s := range ss {
+				if s.BookID != target.ID {
+					t.Errorf("merger got a session of book %d, want %d", s.BookID, target.ID)
+				}
+			}
+			tb.ReadPages = 20
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Merge: %v", err)
+		}
+		if merged.ID != target.ID || merged.ReadPages != 20 || merged.Version != before.Version+1 {
+			t.Errorf("Merge = {ID: %d, ReadPages: %d, Version: %d}, want {ID: %d, ReadPages: 20, Version: %d}", merged.ID, merged.ReadPages, merged.Version, target.ID, before.Version+1)
+		}
+		if _, err := repo.FindByID(ctx, source.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByID source after Merge: err = %v, want ErrNotFound", err)
+		}
+		got, err := sessions.FindByBookID(ctx, target.ID)
+		if err != nil {
+			t.Fatalf("FindByBookID: %v", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("target sessions after Merge = %d, want 2", len(got))
+		}
+
+		for name, ids := range map[string][2]int{"missing source": {target.ID, source.ID}, "missing target": {987654321, other.ID}} {
+			_, err := repo.Merge(ctx, ids[0], ids[1], func(*entity.Book, *entity.Book, []entity.ReadingSession) (*entity.StatusChange, error) {
+				t.Errorf("merger called with a %s", name)
+				return nil, nil
+			})
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("Merge with a %s: err = %v, want ErrNotFound", name, err)
+			}
+		}
+	})
+
+	t.Run("CreateMulti assigns IDs", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		books := []*entity.Book{newBook("a", day), newBook("b", day.Add(time.Hour)), newBook("c", day.Add(2*time.Hour))}
+		if err := repo.CreateMulti(ctx, books); err != nil {
+			t.Fatalf("CreateMulti: %v", err)
+		}
+		seen := map[int]bool{}
+		for _, b := range books {
+			if b.ID == 0 || seen[b.ID] || b.Version != 1 {
+				t.Errorf("CreateMulti set {ID: %d, Version: %d}, want a distinct non-zero ID and Version 1", b.ID, b.Version)
+			}
+			seen[b.ID] = true
+		}
+		all, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		if got, want := bookTitles(all), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+			t.Errorf("FindAll after CreateMulti = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("UpdateMulti", func(t *testing.T) {
+		for _, atomic := range []bool{false, true} {
+			t.Run(map[bool]string{false: "partial", true: "atomic"}[atomic], func(t *testing.T) {
+				ctx := newUserCtx(t)
+				ok, failing, trashed := newBook("ok", day), newBook("failing", day), newBook("trashed", day)
+				create(t, ctx, ok, failing, trashed)
+				if _, err := repo.Trash(ctx, trashed.ID, day, nil); err != nil {
+					t.Fatalf("Trash: %v", err)
+				}
+				ids := []int{ok.ID, 987654321, failing.ID, trashed.ID}
+				books, errs, err := repo.UpdateMulti(ctx, ids, func(i int, b *entity.Book) (*entity.StatusChange, error) {
+					if b.ID != ids[i] {
+						t.Errorf("editor %d got book %d, want %d", i, b.ID, ids[i])
+					}
+					b.Title += "!"
+					if b.ID == failing.ID {
+						return nil, errEdit
+					}
+					return nil, nil
+				}, atomic)
+				if err != nil {
+					t.Fatalf("UpdateMulti: %v", err)
+				}
+				if len(errs) != len(ids) || errs[0] != nil || !errors.Is(errs[1], ErrNotFound) || !errors.Is(errs[2], errEdit) || !errors.Is(errs[3], ErrNotFound) {
+					t.Errorf("UpdateMulti errs = %v, want [nil ErrNotFound %v ErrNotFound]", errs, errEdit)
+				}
+
+				wantTitle, wantVersion := "ok!", 2
+				if atomic {
+					wantTitle, wantVersion = "ok", 1
+					if books != nil {
+						t.Errorf("atomic UpdateMulti books = %v, want nil", books)
+					}
+				} else {
+					if len(books) != len(ids) || books[0] == nil || books[0].Title != "ok!" || books[0].Version != 2 || books[1] != nil || books[2] != nil || books[3] != nil {
+						t.Errorf("UpdateMulti books = %v, want only the first updated", books)
+					}
+				}
+				for _, want := range []struct {
+					id      int
+					title   string
+					version int
+				}{{ok.ID, wantTitle, wantVersion}, {failing.ID, "failing", 1}} {
+					got, err := repo.FindByID(ctx, want.id)
+					if err != nil {
+						t.Fatalf("FindByID: %v", err)
+					}
+					if got.Title != want.title || got.Version != want.version {
+						t.Errorf("FindByID after UpdateMulti = {Title: %q, Version: %d}, want {Title: %q, Version: %d}", got.Title, got.Version, want.title, want.version)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("DeleteMulti ignores missing books", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		a, b, trashed, kept := newBook("a", day), newBook("b", day), newBook("trashed", day), newBook("kept", day)
+		create(t, ctx, a, b, trashed, kept)
+		if _, err := repo.Trash(ctx, trashed.ID, day, nil); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+		if err := repo.DeleteMulti(ctx, []int{a.ID, 987654321, b.ID, trashed.ID}); err != nil {
+			t.Fatalf("DeleteMulti: %v", err)
+		}
+		all, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		if got, want := bookTitles(all), []string{"kept"}; !slices.Equal(got, want) {
+			t.Errorf("FindAll after DeleteMulti = %v, want %v", got, want)
+		}
+		if books, err := repo.FindTrashed(ctx); err != nil || len(books) != 0 {
+			t.Errorf("FindTrashed after DeleteMulti = %d books, %v; want 0", len(books), err)
+		}
+	})
+}
+
+func bookTitles(books []entity.Book) []string {
+	var titles []string
+	for _, b := range books {
+		titles = append(titles, b.Title)
+	}
+	return titles
+}
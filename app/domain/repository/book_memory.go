@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryBookRepo は BookRepo のインメモリ実装。テストやエミュレータなしのオフライン開発で使う。
+// ID の自動採番・FindAll の createdAt 順・ErrNotFound の返し方は Datastore 実装に合わせている。
+// Book の子エンティティ（読書記録など）も同じ mutex で守り、Datastore のトランザクションの代わりにする。
+// 本はユーザーごとに分け、Datastore の祖先キーと同じく他のユーザーの本は見えない。
+type memoryBookRepo struct {
+	mu     sync.Mutex
+	nextID int
+	books  map[memoryBookKey]entity.Book
+
+	nextSessionID int
+	sessions      map[int]entity.ReadingSession
+
+	nextStatusChangeID int
+	statusChanges      map[int]entity.StatusChange
+
+	nextShelfID int
+	shelves     map[memoryLabelKey]entity.Shelf
+	nextTagID   int
+	tags        map[memoryLabelKey]entity.Tag
+
+	reviews map[int]entity.Review // key は本の ID（1冊に1つ）
+
+	nextHighlightID int
+	highlights      map[int]entity.Highlight
+}
+
+func NewMemoryBookRepo() BookRepo {
+	return &memoryBookRepo{
+		nextID:        1,
+		books:         map[memoryBookKey]entity.Book{},
+		nextSessionID: 1,
+		sessions:      map[int]entity.ReadingSession{},
+
+		nextStatusChangeID: 1,
+		statusChanges:      map[int]entity.StatusChange{},
+
+		nextShelfID: 1,
+		shelves:     map[memoryLabelKey]entity.Shelf{},
+		nextTagID:   1,
+		tags:        map[memoryLabelKey]entity.Tag{},
+
+		reviews: map[int]entity.Review{},
+
+		nextHighlightID: 1,
+		highlights:      map[int]entity.Highlight{},
+	}
+}
+
+// memoryBookKey は Datastore の User → Book の Key に当たる。
+type memoryBookKey struct {
+	userID int
+	bookID int
+}
+
+// bookKey はログイン中のユーザーの本の key。Datastore 実装の bookKey と同じく、ユーザーがいなければエラー。
+func (r *memoryBookRepo) bookKey(ctx context.Context, id int) (memoryBookKey, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return memoryBookKey{}, errNoUser
+	}
+	return memoryBookKey{userID: user.ID, bookID: id}, nil
+}
+
+// book は呼び出し側で mu を取っている前提。Datastore 実装の getBook と同じく、ゴミ箱の本はないものとして扱う。
+func (r *memoryBookRepo) book(key memoryBookKey) (entity.Book, bool) {
+	b, ok := r.books[key]
+	if !ok || !b.DeletedAt.IsZero() {
+		return entity.Book{}, false
+	}
+	return b, true
+}
+
+func (r *memoryBookRepo) Create(ctx context.Context, book *entity.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.bookKey(ctx, r.nextID)
+	if err != nil {
+		return err
+	}
+	book.ID = r.nextID
+	book.Version = 1
+	r.nextID++
+	r.books[key] = *book
+	return nil
+}
+
+func (r *memoryBookRepo) Update(ctx context.Context, id int, edit BookEditor) (*entity.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.bookKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	book, ok := r.book(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	change, err := edit(&book)
+	if err != nil {
+		return nil, err
+	}
+	r.addStatusChange(id, change)
+	book.Version++
+	r.books[key] = book
+	return &book, nil
+}
+
+func (r *memoryBookRepo) FindAll(ctx context.Context) ([]entity.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	books := make([]entity.Book, 0, len(r.books))
+	for key, b := range r.books {
+		if key.userID == user.ID && b.DeletedAt.IsZero() {
+			books = append(books, b)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if !books[i].CreatedAt.Equal(books[j].CreatedAt) {
+			return books[i].CreatedAt.Before(books[j].CreatedAt)
+		}
+		return books[i].ID < books[j].ID
+	})
+	return books, nil
+}
+
+// Query の pageToken はインメモリ実装では次ページ先頭のオフセットを文字列にしたもの。
+func (r *memoryBookRepo) Query(ctx context.Context, q BookQuery) ([]entity.Book, string, error) {
+	offset := 0
+	if q.PageToken != "" {
+		n, err := strconv.Atoi(q.PageToken)
+		if err != nil || n < 0 {
+			return nil, "", ErrInvalidPageToken
+		}
+		offset = n
+	}
+	all, err := r.FindAll(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	books := make([]entity.Book, 0, len(all))
+	for _, b := range all {
+		if q.Status != "" && b.Status != q.Status {
+			continue
+		}
+		if q.Author != "" && b.Author != q.Author {
+			continue
+		}
+		if q.Publisher != "" && b.Publisher != q.Publisher {
+			continue
+		}
+		if q.Shelf != "" && !slices.Contains(b.Shelves, q.Shelf) {
+			continue
+		}
+		if q.Tag != "" && !slices.Contains(b.Tags, q.Tag) {
+			continue
+		}
+		books = append(books, b)
+	}
+	// FindAll で createdAt 順になっているので、同値のときはその順を保つ
+	sort.SliceStable(books, func(i, j int) bool {
+		c := compareBook(books[i], books[j], q.Sort)
+		if q.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	if offset > len(books) {
+		offset = len(books)
+	}
+	books = books[offset:]
+	next := ""
+	if q.Limit > 0 && len(books) > q.Limit {
+		books = books[:q.Limit]
+		next = strconv.Itoa(offset + q.Limit)
+	}
+	return books, next, nil
+}
+
+func compareBook(a, b entity.Book, field string) int {
+	switch field {
+	case "updatedAt":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "targetCompleteDate":
+		return a.TargetCompleteDate.Compare(b.TargetCompleteDate)
+	case "title":
+		return strings.Compare(a.Title, b.Title)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+func (r *memoryBookRepo) FindByID(ctx context.Context, id int) (*entity.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.bookKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := r.book(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &b, nil
+}
+
+func (r *memoryBookRepo) Delete(ctx context.Context, id int, check BookCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.bookKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	book, ok := r.books[key]
+	if !ok {
+		return ErrNotFound
+	}
+	if check != nil {
+		if err := check(&book); err != nil {
+			return err
+		}
+	}
+	r.deleteBook(key)
+	return nil
+}
+
+// deleteBook は本と子エンティティを消す。呼び出し側で mu を取っている前提。
+func (r *memoryBookRepo) deleteBook(key memoryBookKey) {
+	id := key.bookID
+	delete(r.books, key)
+	for sid, sess := range r.sessions {
+		if sess.BookID == id {
+			delete(r.sessions, sid)
+		}
+	}
+	for cid, c := range r.statusChanges {
+		if c.BookID == id {
+			delete(r.statusChanges, cid)
+		}
+	}
+	delete(r.reviews, id)
+	for hid, h := range r.highlights {
+		if h.BookID == id {
+			delete(r.highlights, hid)
+		}
+	}
+}
+
+func (r *memoryBookRepo) Trash(ctx context.Context, id int, at time.Time, check BookCheck) (*entity.Book, error) {
+	return r.Update(ctx, id, func(book *entity.Book) (*entity.StatusChange, error) {
+		if check != nil {
+			if err := check(book); err != nil {
+				return nil, err
+			}
+		}
+		book.DeletedAt = at
+		return nil, nil
+	})
+}
+
+func (r *memoryBookRepo) FindTrashed(ctx context.Context) ([]entity.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	var books []entity.Book
+	for key, b := range r.books {
+		if key.userID == user.ID && !b.DeletedAt.IsZero() {
+			books = append(books, b)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if !books[i].DeletedAt.Equal(books[j].DeletedAt) {
+			return books[i].DeletedAt.After(books[j].DeletedAt)
+		}
+		return books[i].ID < books[j].ID
+	})
+	return books, nil
+}
+
+func (r *memoryBookRepo) Restore(ctx context.Context, id int) (*entity.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.bookKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	book, ok := r.books[key]
+	if !ok || book.DeletedAt.IsZero() {
+		return nil, ErrTrashNotFound
+	}
+	book.DeletedAt = time.Time{}
+	book.Version++
+	r.books[key] = book
+	return &book, nil
+}
+
+func (r *memoryBookRepo) PurgeTrashed(ctx context.Context, before time.Time) ([]PurgedBook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged []PurgedBook
+	for key, b := range r.books {
+		if !b.DeletedAt.IsZero() && b.DeletedAt.Before(before) {
+			r.deleteBook(key)
+			purged = append(purged, PurgedBook{UserID: key.userID, Book: b})
+		}
+	}
+	return purged, nil
+}
+
+func (r *memoryBookRepo) Merge(ctx context.Context, targetID, sourceID int, merge BookMerger) (*entity.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tk, err := r.bookKey(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := r.bookKey(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := r.book(tk)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	source, ok := r.book(sk)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	sessions := r.sessionsOf(targetID)
+	for _, s := range r.sessionsOf(sourceID) {
+		s.BookID = targetID
+		sessions = append(sessions, s)
+	}
+	change, err := merge(&target, &source, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	// ここから先は失敗しないので、merge が成功してから書き換える
+	for _, s := range sessions {
+		r.sessions[s.ID] = s
+	}
+	for id, c := range r.statusChanges {
+		if c.BookID == sourceID {
+			c.BookID = targetID
+			r.statusChanges[id] = c
+		}
+	}
+	for id, h := range r.highlights {
+		if h.BookID == sourceID {
+			h.BookID = targetID
+			r.highlights[id] = h
+		}
+	}
+	if review, ok := r.reviews[sourceID]; ok {
+		if _, ok := r.reviews[targetID]; !ok {
+			review.BookID = targetID
+			r.reviews[targetID] = review
+		}
+		delete(r.reviews, sourceID)
+	}
+	r.addStatusChange(targetID, change)
+	target.Version++
+	delete(r.books, sk)
+	r.books[tk] = target
+	return &target, nil
+}
+
+func (r *memoryBookRepo) CreateMulti(ctx context.Context, books []*entity.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return errNoUser
+	}
+	for _, book := range books {
+		key := memoryBookKey{userID: user.ID, bookID: r.nextID}
+		book.ID = r.nextID
+		book.Version = 1
+		r.nextID++
+		r.books[key] = *book
+	}
+	return nil
+}
+
+func (r *memoryBookRepo) UpdateMulti(ctx context.Context, ids []int, edit BookBatchEditor, atomic bool) ([]*entity.Book, []error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, nil, errNoUser
+	}
+	books := make([]*entity.Book, len(ids))
+	changes := make([]*entity.StatusChange, len(ids))
+	errs := make([]error, len(ids))
+	failed := false
+	for i, id := range ids {
+		book, ok := r.book(memoryBookKey{userID: user.ID, bookID: id})
+		if !ok {
+			errs[i], failed = ErrNotFound, true
+			continue
+		}
+		var err error
+		if changes[i], err = edit(i, &book); err != nil {
+			errs[i], failed = err, true
+			continue
+		}
+		book.Version++
+		books[i] = &book
+	}
+	if failed && atomic {
+		return nil, errs, nil
+	}
+	// edit がすべて終わってから書き換える（Datastore 実装のトランザクションの PutMulti に当たる）
+	for i, book := range books {
+		if book != nil {
+			r.addStatusChange(book.ID, changes[i])
+			r.books[memoryBookKey{userID: user.ID, bookID: book.ID}] = *book
+		}
+	}
+	return books, errs, nil
+}
+
+func (r *memoryBookRepo) DeleteMulti(ctx context.Context, ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		key, err := r.bookKey(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, ok := r.books[key]; ok {
+			r.deleteBook(key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/api/iterator"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// HighlightRepo は本のハイライト（引用）の永続化のインターフェース。
+type HighlightRepo interface {
+	// Create はハイライトを追加する。本がなければ ErrNotFound、check が通らなければそのエラー。
+	Create(ctx context.Context, bookID int, highlight *entity.Highlight, check BookCheck) error
+	FindByBookID(ctx context.Context, bookID int) ([]entity.Highlight, error)
+	// FindByID は本はあってハイライトがなければ ErrHighlightNotFound。
+	FindByID(ctx context.Context, bookID, id int) (*entity.Highlight, error)
+	Update(ctx context.Context, highlight *entity.Highlight, check BookCheck) error
+	Delete(ctx context.Context, bookID, id int) error
+	// Query は本をまたいだハイライトを新しい順に1ページ分返す。続きがあれば nextPageToken を返す。
+	Query(ctx context.Context, q HighlightQuery) ([]entity.Highlight, string, error)
+	// FindAll はログイン中のユーザーのハイライトをすべて返す。
+	FindAll(ctx context.Context) ([]entity.Highlight, error)
+}
+
+// HighlightQuery はハイライト一覧の絞り込み・ページング条件。空の項目は条件なし。
+type HighlightQuery struct {
+	BookID    int
+	Color     entity.HighlightColor
+	Category  string
+	Limit     int
+	PageToken string
+}
+
+// ErrHighlightNotFound は本はあるがハイライトがないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrHighlightNotFound = fmt.Errorf("highlight %w", ErrNotFound)
+
+const kindHighlight = "Highlight"
+
+type highlightRepo struct{}
+
+func NewHighlightRepo() HighlightRepo {
+	return &highlightRepo{}
+}
+
+// putHighlight は本を読み直して check を通してからハイライトを書く。key が不完全なら新しく採番する。
+func (r *highlightRepo) putHighlight(ctx context.Context, bookID int, key *datastore.Key, highlight *entity.Highlight, check BookCheck) (*datastore.Key, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk := key.Parent
+	var pk *datastore.PendingKey
+	commit, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var book entity.Book
+		if err := getBook(ctx, ds, tx, bk, &book); err != nil {
+			return err
+		}
+		book.ID = bookID
+		if !key.Incomplete() {
+			if err := tx.Get(key, &entity.Highlight{}); err != nil {
+				if err == datastore.ErrNoSuchEntity {
+					return ErrHighlightNotFound
+				}
+				return err
+			}
+		}
+		if err := check(&book); err != nil {
+			return err
+		}
+		pk, err = tx.Put(key, highlight)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if key.Incomplete() {
+		key = commit.Key(pk)
+	}
+	return key, nil
+}
+
+func (r *highlightRepo) Create(ctx context.Context, bookID int, highlight *entity.Highlight, check BookCheck) error {
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	key, err := r.putHighlight(ctx, bookID, datastore.IncompleteKey(kindHighlight, bk), highlight, check)
+	if err != nil {
+		return err
+	}
+	highlight.ID = int(key.ID)
+	highlight.BookID = bookID
+	return nil
+}
+
+func (r *highlightRepo) Update(ctx context.Context, highlight *entity.Highlight, check BookCheck) error {
+	bk, err := bookKey(ctx, highlight.BookID)
+	if err != nil {
+		return err
+	}
+	_, err = r.putHighlight(ctx, highlight.BookID, datastore.IDKey(kindHighlight, int64(highlight.ID), bk), highlight, check)
+	return err
+}
+
+func (r *highlightRepo) FindByBookID(ctx context.Context, bookID int) ([]entity.Highlight, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := getBook(ctx, ds, nil, bk, &entity.Book{}); err != nil {
+		return nil, err
+	}
+	var highlights []entity.Highlight
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindHighlight).Ancestor(bk), &highlights)
+	if err != nil {
+		return nil, err
+	}
+	fillHighlightKeys(highlights, keys)
+	sortHighlightsByPage(highlights)
+	return highlights, nil
+}
+
+func (r *highlightRepo) FindByID(ctx context.Context, bookID, id int) (*entity.Highlight, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	// ゴミ箱の本のハイライトは見せないので、先に本を確かめる
+	if err := getBook(ctx, ds, nil, bk, &entity.Book{}); err != nil {
+		return nil, err
+	}
+	h := &entity.Highlight{}
+	if err := ds.Get(ctx, datastore.IDKey(kindHighlight, int64(id), bk), h); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrHighlightNotFound
+		}
+		return nil, err
+	}
+	h.ID = id
+	h.BookID = bookID
+	return h, nil
+}
+
+func (r *highlightRepo) Delete(ctx context.Context, bookID, id int) error {
+	if _, err := r.FindByID(ctx, bookID, id); err != nil {
+		return err
+	}
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	return ds.Delete(ctx, datastore.IDKey(kindHighlight, int64(id), bk))
+}
+
+// Query は bookId があればその本の Key、なければユーザーの Key を祖先にして createdAt の新しい順に引く。ゴミ箱の本の分は除く。
+// color / category と並び替えの組み合わせには index.yaml の複合インデックスが必要。
+func (r *highlightRepo) Query(ctx context.Context, hq HighlightQuery) ([]entity.Highlight, string, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	trashed, err := trashedBookIDs(ctx, ds, uk)
+	if err != nil {
+		return nil, "", err
+	}
+	ancestor := uk
+	if hq.BookID != 0 {
+		ancestor = datastore.IDKey(kindBook, int64(hq.BookID), ancestor)
+	}
+	q := datastore.NewQuery(kindHighlight).Ancestor(ancestor)
+	if hq.Color != "" {
+		q = q.FilterField("color", "=", string(hq.Color))
+	}
+	if hq.Category != "" {
+		q = q.FilterField("category", "=", hq.Category)
+	}
+	q = q.Order("-createdAt")
+	if hq.PageToken != "" {
+		cursor, err := datastore.DecodeCursor(hq.PageToken)
+		if err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		q = q.Start(cursor)
+	}
+
+	// ゴミ箱の本のハイライトを読み飛ばすので件数は Limit で絞らず、limit+1 件目が見つかったら次ページありとする
+	var highlights []entity.Highlight
+	var cursor datastore.Cursor
+	next := ""
+	it := ds.Run(ctx, q)
+	for {
+		var h entity.Highlight
+		key, err := it.Next(&h)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if trashed[int(key.Parent.ID)] {
+			continue
+		}
+		if hq.Limit > 0 && len(highlights) == hq.Limit {
+			next = cursor.String()
+			break
+		}
+		h.ID = int(key.ID)
+		h.BookID = int(key.Parent.ID)
+		highlights = append(highlights, h)
+		if hq.Limit > 0 && len(highlights) == hq.Limit {
+			if cursor, err = it.Cursor(); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return highlights, next, nil
+}
+
+func (r *highlightRepo) FindAll(ctx context.Context) ([]entity.Highlight, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var highlights []entity.Highlight
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindHighlight).Ancestor(uk), &highlights)
+	if err != nil {
+		return nil, err
+	}
+	fillHighlightKeys(highlights, keys)
+	trashed, err := trashedBookIDs(ctx, ds, uk)
+	if err != nil {
+		return nil, err
+	}
+	highlights = withoutTrashed(highlights, trashed, func(h entity.Highlight) int { return h.BookID })
+	sortHighlightsByID(highlights)
+	return highlights, nil
+}
+
+func fillHighlightKeys(highlights []entity.Highlight, keys []*datastore.Key) {
+	for i := range keys {
+		highlights[i].ID = int(keys[i].ID)
+		highlights[i].BookID = int(keys[i].Parent.ID)
+	}
+}
+
+// sortHighlightsByPage は本の中のハイライトをページ順（同じページは追加した順）に並べる。
+func sortHighlightsByPage(highlights []entity.Highlight) {
+	sort.Slice(highlights, func(i, j int) bool {
+		if highlights[i].Page != highlights[j].Page {
+			return highlights[i].Page < highlights[j].Page
+		}
+		if !highlights[i].CreatedAt.Equal(highlights[j].CreatedAt) {
+			return highlights[i].CreatedAt.Before(highlights[j].CreatedAt)
+		}
+		return highlights[i].ID < highlights[j].ID
+	})
+}
+
+// sortHighlightsByID は並び順を実装によらず決まったものにする（日替わりの1件を選ぶのに使う）。
+func sortHighlightsByID(highlights []entity.Highlight) {
+	sort.Slice(highlights, func(i, j int) bool {
+		if highlights[i].BookID != highlights[j].BookID {
+			return highlights[i].BookID < highlights[j].BookID
+		}
+		return highlights[i].ID < highlights[j].ID
+	})
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryHighlightRepo は HighlightRepo のインメモリ実装。データは memoryBookRepo と共有し、本の削除で一緒に消す。
+type memoryHighlightRepo struct {
+	store *memoryBookRepo
+}
+
+// NewMemoryHighlightRepo は books（NewMemoryBookRepo の戻り値）とデータを共有する実装を返す。
+func NewMemoryHighlightRepo(books BookRepo) HighlightRepo {
+	return &memoryHighlightRepo{store: books.(*memoryBookRepo)}
+}
+
+// book は呼び出し側で mu を取っている前提。ログイン中のユーザーの本を返す。
+func (r *memoryHighlightRepo) book(ctx context.Context, bookID int) (entity.Book, error) {
+	key, err := r.store.bookKey(ctx, bookID)
+	if err != nil {
+		return entity.Book{}, err
+	}
+	book, ok := r.store.book(key)
+	if !ok {
+		return entity.Book{}, ErrNotFound
+	}
+	return book, nil
+}
+
+func (r *memoryHighlightRepo) Create(ctx context.Context, bookID int, highlight *entity.Highlight, check BookCheck) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	book, err := r.book(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if err := check(&book); err != nil {
+		return err
+	}
+	highlight.ID = s.nextHighlightID
+	highlight.BookID = bookID
+	s.nextHighlightID++
+	s.highlights[highlight.ID] = *highlight
+	return nil
+}
+
+func (r *memoryHighlightRepo) FindByBookID(ctx context.Context, bookID int) ([]entity.Highlight, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := r.book(ctx, bookID); err != nil {
+		return nil, err
+	}
+	highlights := []entity.Highlight{}
+	for _, h := range s.highlights {
+		if h.BookID == bookID {
+			highlights = append(highlights, h)
+		}
+	}
+	sortHighlightsByPage(highlights)
+	return highlights, nil
+}
+
+func (r *memoryHighlightRepo) FindByID(ctx context.Context, bookID, id int) (*entity.Highlight, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := r.book(ctx, bookID); err != nil {
+		return nil, err
+	}
+	h, ok := s.highlights[id]
+	if !ok || h.BookID != bookID {
+		return nil, ErrHighlightNotFound
+	}
+	return &h, nil
+}
+
+func (r *memoryHighlightRepo) Update(ctx context.Context, highlight *entity.Highlight, check BookCheck) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	book, err := r.book(ctx, highlight.BookID)
+	if err != nil {
+		return err
+	}
+	if h, ok := s.highlights[highlight.ID]; !ok || h.BookID != highlight.BookID {
+		return ErrHighlightNotFound
+	}
+	if err := check(&book); err != nil {
+		return err
+	}
+	s.highlights[highlight.ID] = *highlight
+	return nil
+}
+
+func (r *memoryHighlightRepo) Delete(ctx context.Context, bookID, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := r.book(ctx, bookID); err != nil {
+		return err
+	}
+	if h, ok := s.highlights[id]; !ok || h.BookID != bookID {
+		return ErrHighlightNotFound
+	}
+	delete(s.highlights, id)
+	return nil
+}
+
+// Query の pageToken は memoryBookRepo.Query と同じく次ページ先頭のオフセット。
+func (r *memoryHighlightRepo) Query(ctx context.Context, q HighlightQuery) ([]entity.Highlight, string, error) {
+	offset := 0
+	if q.PageToken != "" {
+		n, err := strconv.Atoi(q.PageToken)
+		if err != nil || n < 0 {
+			return nil, "", ErrInvalidPageToken
+		}
+		offset = n
+	}
+	all, err := r.FindAll(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	highlights := make([]entity.Highlight, 0, len(all))
+	for _, h := range all {
+		if q.BookID != 0 && h.BookID != q.BookID {
+			continue
+		}
+		if q.Color != "" && h.Color != q.Color {
+			continue
+		}
+		if q.Category != "" && h.Category != q.Category {
+			continue
+		}
+		highlights = append(highlights, h)
+	}
+	sort.SliceStable(highlights, func(i, j int) bool {
+		return highlights[i].CreatedAt.After(highlights[j].CreatedAt)
+	})
+	if offset > len(highlights) {
+		offset = len(highlights)
+	}
+	highlights = highlights[offset:]
+	next := ""
+	if q.Limit > 0 && len(highlights) > q.Limit {
+		highlights = highlights[:q.Limit]
+		next = strconv.Itoa(offset + q.Limit)
+	}
+	return highlights, next, nil
+}
+
+func (r *memoryHighlightRepo) FindAll(ctx context.Context) ([]entity.Highlight, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	var highlights []entity.Highlight
+	for _, h := range s.highlights {
+		if _, ok := s.book(memoryBookKey{userID: user.ID, bookID: h.BookID}); ok {
+			highlights = append(highlights, h)
+		}
+	}
+	sortHighlightsByID(highlights)
+	return highlights, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ImportJobRepo は CSV の取り込みの進み具合と行の結果の永続化のインターフェース。
+// 取り込みを進めるインスタンスと進み具合を聞かれるインスタンスが違っても同じ結果を返す。
+type ImportJobRepo interface {
+	Create(ctx context.Context, job *entity.ImportJob) error
+	// Update は job の進み具合を書き、rows を処理した行の結果の後ろに足す。job.Processed は rows を含めた数にしておく。
+	Update(ctx context.Context, job *entity.ImportJob, rows []entity.ImportRow) error
+	// FindByID はログイン中のユーザーの取り込みを、処理した行の結果ごと返す。
+	FindByID(ctx context.Context, id string) (*entity.ImportJob, error)
+	// DeleteFinishedBefore はログイン中のユーザーの、before より前に終わった取り込みを行の結果ごと消す。
+	DeleteFinishedBefore(ctx context.Context, before time.Time) error
+}
+
+// ErrImportJobNotFound は取り込みがない（他のユーザーのもの・期限が過ぎたものを含む）ときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrImportJobNotFound = fmt.Errorf("import job %w", ErrNotFound)
+
+const (
+	kindImportJob     = "ImportJob"
+	kindImportJobRows = "ImportJobRows"
+)
+
+// importJobRows は行の結果の塊。Key の ID は塊の最初の行の位置 + 1 で、ID の順に並べると CSV の順になる。
+type importJobRows struct {
+	Rows []byte `datastore:"rows,noindex"` // []entity.ImportRow の JSON
+}
+
+type importJobRepo struct{}
+
+func NewImportJobRepo() ImportJobRepo {
+	return &importJobRepo{}
+}
+
+func importJobKey(ctx context.Context, id string) (*datastore.Key, error) {
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datastore.NameKey(kindImportJob, id, uk), nil
+}
+
+func (r *importJobRepo) Create(ctx context.Context, job *entity.ImportJob) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := importJobKey(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := ds.Put(ctx, key, job); err != nil {
+		return err
+	}
+	job.UserID = int(key.Parent.ID)
+	return nil
+}
+
+// Update は行の結果を先に書く。途中で失敗しても、Processed が書いていない行を数えることはない。
+func (r *importJobRepo) Update(ctx context.Context, job *entity.ImportJob, rows []entity.ImportRow) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := importJobKey(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		b, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		offset := job.Processed - len(rows)
+		if _, err := ds.Put(ctx, datastore.IDKey(kindImportJobRows, int64(offset+1), key), &importJobRows{Rows: b}); err != nil {
+			return err
+		}
+	}
+	_, err = ds.Put(ctx, key, job)
+	return err
+}
+
+func (r *importJobRepo) FindByID(ctx context.Context, id string) (*entity.ImportJob, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := importJobKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job := &entity.ImportJob{}
+	if err := ds.Get(ctx, key, job); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+	job.ID, job.UserID = id, int(key.Parent.ID)
+
+	var chunks []importJobRows
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindImportJobRows).Ancestor(key), &chunks)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]].ID < keys[order[j]].ID })
+	job.Rows = make([]entity.ImportRow, 0, job.Processed)
+	for _, i := range order {
+		var rows []entity.ImportRow
+		if err := json.Unmarshal(chunks[i].Rows, &rows); err != nil {
+			return nil, fmt.Errorf("import job %s: rows %d: %w", id, keys[i].ID, err)
+		}
+		job.Rows = append(job.Rows, rows...)
+	}
+	return job, nil
+}
+
+func (r *importJobRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	// ユーザーごとの取り込みは多くないので、複合インデックスを作らずに全部読んで選ぶ
+	var jobs []entity.ImportJob
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindImportJob).Ancestor(uk), &jobs)
+	if err != nil {
+		return err
+	}
+	for i, job := range jobs {
+		if job.State == entity.ImportJobRunning || !job.FinishedAt.Before(before) {
+			continue
+		}
+		// 取り込みの Key 自身と、その子の行の結果
+		descendants, err := ds.GetAll(ctx, datastore.NewQuery("").Ancestor(keys[i]).KeysOnly(), nil)
+		if err != nil {
+			return err
+		}
+		if err := ds.DeleteMulti(ctx, descendants); err != nil {
+			return err
+		}
+	}
+	return nil
+}
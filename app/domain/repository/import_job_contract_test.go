@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	dsclient "github.com/sora-00/booktracker-api/app/infra/datastore"
+)
+
+// ImportJobRepo の実装が同じ振る舞いをするかを確かめる。Datastore 実装は BookRepo と同じくエミュレータがあるときだけ動かす。
+
+func TestMemoryImportJobRepoContract(t *testing.T) {
+	runImportJobRepoContract(t, NewMemoryImportJobRepo(), context.Background())
+}
+
+func TestDatastoreImportJobRepoContract(t *testing.T) {
+	if os.Getenv("DATASTORE_EMULATOR_HOST") == "" {
+		t.Skip("DATASTORE_EMULATOR_HOST is not set")
+	}
+	ctx := context.Background()
+	ds, err := dsclient.NewClient(ctx)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { ds.Close() })
+	runImportJobRepoContract(t, NewImportJobRepo(), dsclient.WithContext(ctx, ds))
+}
+
+func runImportJobRepoContract(t *testing.T, repo ImportJobRepo, base context.Context) {
+	firstUserID := int(time.Now().UnixNano() % (1 << 40))
+	users := 0
+	newUserCtx := func(t *testing.T) context.Context {
+		t.Helper()
+		users++
+		return auth.WithUser(base, &entity.User{ID: firstUserID + users})
+	}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newJob := func(id string) *entity.ImportJob {
+		return &entity.ImportJob{ID: id, Format: "goodreads", State: entity.ImportJobRunning, Total: 3, CreatedAt: day, UpdatedAt: day}
+	}
+
+	t.Run("Update appends rows in order", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		job := newJob("rows")
+		if err := repo.Create(ctx, job); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		job.Processed = 2
+		first := []entity.ImportRow{
+			{Row: 2, Book: &entity.Book{ID: 10, Title: "a"}},
+			{Row: 3, Error: &entity.ImportRowError{Message: "title is required", Fields: []entity.ImportRowField{{Field: "title", Message: "title is required"}}}},
+		}
+		if err := repo.Update(ctx, job, first); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		job.Processed = 3
+		job.State, job.FinishedAt = entity.ImportJobSucceeded, day.Add(time.Minute)
+		second := []entity.ImportRow{
+			{Row: 4, Error: &entity.ImportRowError{Message: "duplicate", Candidates: []entity.ImportRowCandidate{{Book: entity.Book{ID: 10}, Reason: "isbn", Score: 1}}}},
+		}
+		if err := repo.Update(ctx, job, second); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, err := repo.FindByID(ctx, "rows")
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.State != entity.ImportJobSucceeded || got.Processed != 3 || !got.FinishedAt.Equal(job.FinishedAt) {
+			t.Errorf("FindByID = {State: %s, Processed: %d, FinishedAt: %v}, want {succeeded, 3, %v}", got.State, got.Processed, got.FinishedAt, job.FinishedAt)
+		}
+		var rows []int
+		for _, r := range got.Rows {
+			rows = append(rows, r.Row)
+		}
+		if len(rows) != 3 || rows[0] != 2 || rows[1] != 3 || rows[2] != 4 {
+			t.Fatalf("Rows = %v, want [2 3 4]", rows)
+		}
+		if got.Rows[0].Book == nil || got.Rows[0].Book.ID != 10 {
+			t.Errorf("Rows[0].Book = %+v, want book 10", got.Rows[0].Book)
+		}
+		if e := got.Rows[1].Error; e == nil || len(e.Fields) != 1 || e.Fields[0].Field != "title" {
+			t.Errorf("Rows[1].Error = %+v, want a title field error", e)
+		}
+		if e := got.Rows[2].Error; e == nil || len(e.Candidates) != 1 || e.Candidates[0].Book.ID != 10 {
+			t.Errorf("Rows[2].Error = %+v, want a candidate of book 10", e)
+		}
+	})
+
+	t.Run("another user's job is ErrImportJobNotFound", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		if err := repo.Create(ctx, newJob("mine")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		other := newUserCtx(t)
+		if _, err := repo.FindByID(other, "mine"); !errors.Is(err, ErrImportJobNotFound) {
+			t.Errorf("FindByID for another user: err = %v, want ErrImportJobNotFound", err)
+		}
+		if _, err := repo.FindByID(ctx, "unknown"); !errors.Is(err, ErrImportJobNotFound) {
+			t.Errorf("FindByID unknown id: err = %v, want ErrImportJobNotFound", err)
+		}
+	})
+
+	t.Run("DeleteFinishedBefore keeps running and recent jobs", func(t *testing.T) {
+		ctx := newUserCtx(t)
+		old, recent, running := newJob("old"), newJob("recent"), newJob("running")
+		for _, job := range []*entity.ImportJob{old, recent, running} {
+			if err := repo.Create(ctx, job); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		old.Processed, old.State, old.FinishedAt = 1, entity.ImportJobSucceeded, day
+		recent.State, recent.FinishedAt = entity.ImportJobFailed, day.Add(48*time.Hour)
+		if err := repo.Update(ctx, old, []entity.ImportRow{{Row: 2}}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repo.Update(ctx, recent, nil); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		if err := repo.DeleteFinishedBefore(ctx, day.Add(24*time.Hour)); err != nil {
+			t.Fatalf("DeleteFinishedBefore: %v", err)
+		}
+		if _, err := repo.FindByID(ctx, "old"); !errors.Is(err, ErrImportJobNotFound) {
+			t.Errorf("FindByID old: err = %v, want ErrImportJobNotFound", err)
+		}
+		for _, id := range []string{"recent", "running"} {
+			if _, err := repo.FindByID(ctx, id); err != nil {
+				t.Errorf("FindByID %s: %v", id, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryImportJobRepo は ImportJobRepo のインメモリ実装。ID はランダムなので全ユーザーで1つの map に入れ、
+// 持ち主が違う取り込みは Datastore 実装と同じく ErrImportJobNotFound にする。
+type memoryImportJobRepo struct {
+	mu   sync.Mutex
+	jobs map[string]*entity.ImportJob
+}
+
+func NewMemoryImportJobRepo() ImportJobRepo {
+	return &memoryImportJobRepo{jobs: map[string]*entity.ImportJob{}}
+}
+
+// own はログイン中のユーザーの取り込みを返す。
+func (r *memoryImportJobRepo) own(ctx context.Context, id string) (*entity.ImportJob, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	job, ok := r.jobs[id]
+	if !ok || job.UserID != user.ID {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+func (r *memoryImportJobRepo) Create(ctx context.Context, job *entity.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return errNoUser
+	}
+	job.UserID = user.ID
+	stored := *job
+	stored.Rows = nil
+	r.jobs[job.ID] = &stored
+	return nil
+}
+
+func (r *memoryImportJobRepo) Update(ctx context.Context, job *entity.ImportJob, rows []entity.ImportRow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, err := r.own(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	all := append(stored.Rows, rows...)
+	*stored = *job
+	stored.Rows = all
+	return nil
+}
+
+// FindByID は取り込みの途中でも読めるよう写しを返す。
+func (r *memoryImportJobRepo) FindByID(ctx context.Context, id string) (*entity.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, err := r.own(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job := *stored
+	job.Rows = append([]entity.ImportRow{}, stored.Rows...)
+	return &job, nil
+}
+
+func (r *memoryImportJobRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return errNoUser
+	}
+	for id, job := range r.jobs {
+		if job.UserID == user.ID && job.State != entity.ImportJobRunning && job.FinishedAt.Before(before) {
+			delete(r.jobs, id)
+		}
+	}
+	return nil
+}
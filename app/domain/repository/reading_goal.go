@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ReadingGoalRepo は読書目標の永続化のインターフェース。
+type ReadingGoalRepo interface {
+	Create(ctx context.Context, goal *entity.ReadingGoal) error
+	// FindAll はログイン中のユーザーの目標を期間の開始日順で返す。
+	FindAll(ctx context.Context) ([]entity.ReadingGoal, error)
+	FindByID(ctx context.Context, id int) (*entity.ReadingGoal, error)
+	Update(ctx context.Context, goal *entity.ReadingGoal) error
+	Delete(ctx context.Context, id int) error
+}
+
+// ErrGoalNotFound は目標がないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrGoalNotFound = fmt.Errorf("reading goal %w", ErrNotFound)
+
+const kindReadingGoal = "ReadingGoal"
+
+type readingGoalRepo struct{}
+
+func NewReadingGoalRepo() ReadingGoalRepo {
+	return &readingGoalRepo{}
+}
+
+func readingGoalKey(ctx context.Context, id int) (*datastore.Key, error) {
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datastore.IDKey(kindReadingGoal, int64(id), uk), nil
+}
+
+func (r *readingGoalRepo) Create(ctx context.Context, goal *entity.ReadingGoal) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := ds.Put(ctx, datastore.IncompleteKey(kindReadingGoal, uk), goal)
+	if err != nil {
+		return err
+	}
+	goal.ID = int(key.ID)
+	return nil
+}
+
+func (r *readingGoalRepo) FindAll(ctx context.Context) ([]entity.ReadingGoal, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var goals []entity.ReadingGoal
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindReadingGoal).Ancestor(uk), &goals)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		goals[i].ID = int(keys[i].ID)
+	}
+	sortReadingGoals(goals)
+	return goals, nil
+}
+
+func (r *readingGoalRepo) FindByID(ctx context.Context, id int) (*entity.ReadingGoal, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := readingGoalKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	goal := &entity.ReadingGoal{}
+	if err := ds.Get(ctx, key, goal); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrGoalNotFound
+		}
+		return nil, err
+	}
+	goal.ID = id
+	return goal, nil
+}
+
+// Update は消された目標を作り直さないよう、トランザクション内で存在を確かめてから書く。
+func (r *readingGoalRepo) Update(ctx context.Context, goal *entity.ReadingGoal) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := readingGoalKey(ctx, goal.ID)
+	if err != nil {
+		return err
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		if err := tx.Get(key, &entity.ReadingGoal{}); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrGoalNotFound
+			}
+			return err
+		}
+		_, err := tx.Put(key, goal)
+		return err
+	})
+	return err
+}
+
+func (r *readingGoalRepo) Delete(ctx context.Context, id int) error {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return err
+	}
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := readingGoalKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ds.Delete(ctx, key)
+}
+
+// sortReadingGoals は目標を期間の開始日順（同じ日は ID 順）に並べる。
+func sortReadingGoals(goals []entity.ReadingGoal) {
+	sort.Slice(goals, func(i, j int) bool {
+		if !goals[i].StartDate.Equal(goals[j].StartDate) {
+			return goals[i].StartDate.Before(goals[j].StartDate)
+		}
+		return goals[i].ID < goals[j].ID
+	})
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryReadingGoalRepo は ReadingGoalRepo のインメモリ実装。目標は本と関係しないので独自の mutex で守る。
+type memoryReadingGoalRepo struct {
+	mu     sync.Mutex
+	nextID int
+	goals  map[memoryLabelKey]entity.ReadingGoal
+}
+
+func NewMemoryReadingGoalRepo() ReadingGoalRepo {
+	return &memoryReadingGoalRepo{nextID: 1, goals: map[memoryLabelKey]entity.ReadingGoal{}}
+}
+
+func (r *memoryReadingGoalRepo) key(ctx context.Context, id int) (memoryLabelKey, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return memoryLabelKey{}, errNoUser
+	}
+	return memoryLabelKey{userID: user.ID, id: id}, nil
+}
+
+func (r *memoryReadingGoalRepo) Create(ctx context.Context, goal *entity.ReadingGoal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.key(ctx, r.nextID)
+	if err != nil {
+		return err
+	}
+	goal.ID = r.nextID
+	r.nextID++
+	r.goals[key] = *goal
+	return nil
+}
+
+func (r *memoryReadingGoalRepo) FindAll(ctx context.Context) ([]entity.ReadingGoal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	goals := []entity.ReadingGoal{}
+	for key, g := range r.goals {
+		if key.userID == user.ID {
+			goals = append(goals, g)
+		}
+	}
+	sortReadingGoals(goals)
+	return goals, nil
+}
+
+func (r *memoryReadingGoalRepo) FindByID(ctx context.Context, id int) (*entity.ReadingGoal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g, ok := r.goals[key]
+	if !ok {
+		return nil, ErrGoalNotFound
+	}
+	return &g, nil
+}
+
+func (r *memoryReadingGoalRepo) Update(ctx context.Context, goal *entity.ReadingGoal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.key(ctx, goal.ID)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.goals[key]; !ok {
+		return ErrGoalNotFound
+	}
+	r.goals[key] = *goal
+	return nil
+}
+
+func (r *memoryReadingGoalRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.goals[key]; !ok {
+		return ErrGoalNotFound
+	}
+	delete(r.goals, key)
+	return nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// BookUpdater はトランザクション内で最新の Book と、変更後の全読書記録を受け取って Book を書き換える。
+// status が変わったときはその履歴を返すと同じトランザクションで保存する。error を返すとトランザクションごと取り消す。
+type BookUpdater func(book *entity.Book, sessions []entity.ReadingSession) (*entity.StatusChange, error)
+
+// ReadingSessionRepo は読書記録の永続化のインターフェース。
+// 追加・削除は親の Book の更新と同じトランザクションで行う。
+type ReadingSessionRepo interface {
+	Create(ctx context.Context, bookID int, session *entity.ReadingSession, update BookUpdater) (*entity.Book, error)
+	FindByBookID(ctx context.Context, bookID int) ([]entity.ReadingSession, error)
+	// FindAll はログイン中のユーザーの全部の本の読書記録を開始日時順で返す（目標・統計の集計用）。
+	FindAll(ctx context.Context) ([]entity.ReadingSession, error)
+	Delete(ctx context.Context, bookID, sessionID int, update BookUpdater) (*entity.Book, error)
+}
+
+const kindReadingSession = "ReadingSession"
+
+type readingSessionRepo struct{}
+
+func NewReadingSessionRepo() ReadingSessionRepo {
+	return &readingSessionRepo{}
+}
+
+func (r *readingSessionRepo) Create(ctx context.Context, bookID int, session *entity.ReadingSession, update BookUpdater) (*entity.Book, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	var book entity.Book
+	var pk *datastore.PendingKey
+	commit, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		book = entity.Book{}
+		if err := getBook(ctx, ds, tx, bk, &book); err != nil {
+			return err
+		}
+		book.ID = bookID
+		sessions, err := r.findAll(ctx, ds, tx, bk)
+		if err != nil {
+			return err
+		}
+		sessions = append(sessions, *session)
+		change, err := update(&book, sessions)
+		if err != nil {
+			return err
+		}
+		if pk, err = tx.Put(datastore.IncompleteKey(kindReadingSession, bk), session); err != nil {
+			return err
+		}
+		if err := putStatusChange(tx, bk, change); err != nil {
+			return err
+		}
+		book.Version++
+		_, err = tx.Put(bk, &book)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	session.ID = int(commit.Key(pk).ID)
+	session.BookID = bookID
+	return &book, nil
+}
+
+func (r *readingSessionRepo) FindByBookID(ctx context.Context, bookID int) ([]entity.ReadingSession, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := getBook(ctx, ds, nil, bk, &entity.Book{}); err != nil {
+		return nil, err
+	}
+	sessions, err := r.findAll(ctx, ds, nil, bk)
+	if err != nil {
+		return nil, err
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+func (r *readingSessionRepo) Delete(ctx context.Context, bookID, sessionID int, update BookUpdater) (*entity.Book, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	sk := datastore.IDKey(kindReadingSession, int64(sessionID), bk)
+	var book entity.Book
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		book = entity.Book{}
+		if err := getBook(ctx, ds, tx, bk, &book); err != nil {
+			return err
+		}
+		book.ID = bookID
+		if err := tx.Get(sk, &entity.ReadingSession{}); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		sessions, err := r.findAll(ctx, ds, tx, bk)
+		if err != nil {
+			return err
+		}
+		rest := sessions[:0]
+		for _, s := range sessions {
+			if s.ID != sessionID {
+				rest = append(rest, s)
+			}
+		}
+		change, err := update(&book, rest)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(sk); err != nil {
+			return err
+		}
+		if err := putStatusChange(tx, bk, change); err != nil {
+			return err
+		}
+		book.Version++
+		_, err = tx.Put(bk, &book)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// findAll は Book 配下の読書記録を全件取る。tx が nil でなければトランザクション内で読む。
+func (r *readingSessionRepo) findAll(ctx context.Context, ds *datastore.Client, tx *datastore.Transaction, bk *datastore.Key) ([]entity.ReadingSession, error) {
+	q := datastore.NewQuery(kindReadingSession).Ancestor(bk)
+	if tx != nil {
+		q = q.Transaction(tx)
+	}
+	var sessions []entity.ReadingSession
+	keys, err := ds.GetAll(ctx, q, &sessions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		sessions[i].ID = int(keys[i].ID)
+		sessions[i].BookID = int(keys[i].Parent.ID)
+	}
+	return sessions, nil
+}
+
+func (r *readingSessionRepo) FindAll(ctx context.Context) ([]entity.ReadingSession, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 読書記録は User → Book → ReadingSession なので、ユーザーの Key を祖先にすれば全部の本の分が取れる
+	sessions, err := r.findAll(ctx, ds, nil, uk)
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := trashedBookIDs(ctx, ds, uk)
+	if err != nil {
+		return nil, err
+	}
+	sessions = withoutTrashed(sessions, trashed, func(s entity.ReadingSession) int { return s.BookID })
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+// sortSessions は読書記録を開始日時順（同時刻は ID 順）に並べる。
+func sortSessions(sessions []entity.ReadingSession) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartedAt.Equal(sessions[j].StartedAt) {
+			return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryReadingSessionRepo は ReadingSessionRepo のインメモリ実装。
+// データは memoryBookRepo に持たせ、Book の更新と同じ mutex の中で読み書きする。
+type memoryReadingSessionRepo struct {
+	store *memoryBookRepo
+}
+
+// NewMemoryReadingSessionRepo は books（NewMemoryBookRepo の戻り値）とデータを共有する実装を返す。
+func NewMemoryReadingSessionRepo(books BookRepo) ReadingSessionRepo {
+	return &memoryReadingSessionRepo{store: books.(*memoryBookRepo)}
+}
+
+func (r *memoryReadingSessionRepo) Create(ctx context.Context, bookID int, session *entity.ReadingSession, update BookUpdater) (*entity.Book, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	book, ok := s.book(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	created := *session
+	created.ID = s.nextSessionID
+	created.BookID = bookID
+	sessions := append(s.sessionsOf(bookID), created)
+	change, err := update(&book, sessions)
+	if err != nil {
+		return nil, err
+	}
+	s.addStatusChange(bookID, change)
+	s.nextSessionID++
+	s.sessions[created.ID] = created
+	book.Version++
+	s.books[key] = book
+	*session = created
+	return &book, nil
+}
+
+func (r *memoryReadingSessionRepo) FindByBookID(ctx context.Context, bookID int) ([]entity.ReadingSession, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.book(key); !ok {
+		return nil, ErrNotFound
+	}
+	sessions := s.sessionsOf(bookID)
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+func (r *memoryReadingSessionRepo) Delete(ctx context.Context, bookID, sessionID int, update BookUpdater) (*entity.Book, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	book, ok := s.book(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if sess, ok := s.sessions[sessionID]; !ok || sess.BookID != bookID {
+		return nil, ErrSessionNotFound
+	}
+	rest := make([]entity.ReadingSession, 0)
+	for _, sess := range s.sessionsOf(bookID) {
+		if sess.ID != sessionID {
+			rest = append(rest, sess)
+		}
+	}
+	change, err := update(&book, rest)
+	if err != nil {
+		return nil, err
+	}
+	s.addStatusChange(bookID, change)
+	delete(s.sessions, sessionID)
+	book.Version++
+	s.books[key] = book
+	return &book, nil
+}
+
+func (r *memoryReadingSessionRepo) FindAll(ctx context.Context) ([]entity.ReadingSession, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	var sessions []entity.ReadingSession
+	for _, sess := range s.sessions {
+		if _, ok := s.book(memoryBookKey{userID: user.ID, bookID: sess.BookID}); ok {
+			sessions = append(sessions, sess)
+		}
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+// sessionsOf は呼び出し側で mu を取っている前提。
+func (r *memoryBookRepo) sessionsOf(bookID int) []entity.ReadingSession {
+	var sessions []entity.ReadingSession
+	for _, sess := range r.sessions {
+		if sess.BookID == bookID {
+			sessions = append(sessions, sess)
+		}
+	}
+	return sessions
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// BookCheck はトランザクション内で最新の Book を受け取り、その本にレビュー・ハイライトなどを書いてよいかを確かめる。
+type BookCheck func(book *entity.Book) error
+
+// ReviewRepo は本のレビューの永続化のインターフェース。レビューは1冊に1つ。
+type ReviewRepo interface {
+	// Put はレビューを保存する（すでにあれば上書きし、createdAt は引き継ぐ）。本がなければ ErrNotFound。
+	Put(ctx context.Context, bookID int, review *entity.Review, check BookCheck) error
+	// FindByBookID は本のレビューを返す。本はあってレビューがなければ ErrReviewNotFound。
+	FindByBookID(ctx context.Context, bookID int) (*entity.Review, error)
+	// FindAll はログイン中のユーザーのレビューをすべて返す。
+	FindAll(ctx context.Context) ([]entity.Review, error)
+	Delete(ctx context.Context, bookID int) error
+}
+
+// ErrReviewNotFound は本はあるがレビューがないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrReviewNotFound = fmt.Errorf("review %w", ErrNotFound)
+
+const (
+	kindReview = "Review"
+	// reviewKeyName は1冊に1つのレビューの Key 名。Book の Key の子なので本ごとに一意になる。
+	reviewKeyName = "review"
+)
+
+type reviewRepo struct{}
+
+func NewReviewRepo() ReviewRepo {
+	return &reviewRepo{}
+}
+
+func (r *reviewRepo) Put(ctx context.Context, bookID int, review *entity.Review, check BookCheck) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	key := datastore.NameKey(kindReview, reviewKeyName, bk)
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var book entity.Book
+		if err := getBook(ctx, ds, tx, bk, &book); err != nil {
+			return err
+		}
+		book.ID = bookID
+		if err := check(&book); err != nil {
+			return err
+		}
+		var old entity.Review
+		switch err := tx.Get(key, &old); err {
+		case nil:
+			review.CreatedAt = old.CreatedAt
+		case datastore.ErrNoSuchEntity:
+		default:
+			return err
+		}
+		_, err := tx.Put(key, review)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	review.BookID = bookID
+	return nil
+}
+
+func (r *reviewRepo) FindByBookID(ctx context.Context, bookID int) (*entity.Review, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	// 本がないのかレビューがないのかで返すエラーを分ける（ゴミ箱の本のレビューも見せない）
+	if err := getBook(ctx, ds, nil, bk, &entity.Book{}); err != nil {
+		return nil, err
+	}
+	review := &entity.Review{}
+	if err := ds.Get(ctx, datastore.NameKey(kindReview, reviewKeyName, bk), review); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	review.BookID = bookID
+	return review, nil
+}
+
+func (r *reviewRepo) FindAll(ctx context.Context) ([]entity.Review, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reviews []entity.Review
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindReview).Ancestor(uk), &reviews)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		reviews[i].BookID = int(keys[i].Parent.ID)
+	}
+	trashed, err := trashedBookIDs(ctx, ds, uk)
+	if err != nil {
+		return nil, err
+	}
+	return withoutTrashed(reviews, trashed, func(r entity.Review) int { return r.BookID }), nil
+}
+
+func (r *reviewRepo) Delete(ctx context.Context, bookID int) error {
+	if _, err := r.FindByBookID(ctx, bookID); err != nil {
+		return err
+	}
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	return ds.Delete(ctx, datastore.NameKey(kindReview, reviewKeyName, bk))
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryReviewRepo は ReviewRepo のインメモリ実装。データは memoryBookRepo と共有し、本の削除で一緒に消す。
+type memoryReviewRepo struct {
+	store *memoryBookRepo
+}
+
+// NewMemoryReviewRepo は books（NewMemoryBookRepo の戻り値）とデータを共有する実装を返す。
+func NewMemoryReviewRepo(books BookRepo) ReviewRepo {
+	return &memoryReviewRepo{store: books.(*memoryBookRepo)}
+}
+
+func (r *memoryReviewRepo) Put(ctx context.Context, bookID int, review *entity.Review, check BookCheck) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	book, ok := s.book(key)
+	if !ok {
+		return ErrNotFound
+	}
+	if err := check(&book); err != nil {
+		return err
+	}
+	if old, ok := s.reviews[bookID]; ok {
+		review.CreatedAt = old.CreatedAt
+	}
+	review.BookID = bookID
+	s.reviews[bookID] = *review
+	return nil
+}
+
+func (r *memoryReviewRepo) FindByBookID(ctx context.Context, bookID int) (*entity.Review, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.book(key); !ok {
+		return nil, ErrNotFound
+	}
+	review, ok := s.reviews[bookID]
+	if !ok {
+		return nil, ErrReviewNotFound
+	}
+	return &review, nil
+}
+
+func (r *memoryReviewRepo) FindAll(ctx context.Context) ([]entity.Review, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	var reviews []entity.Review
+	for key, b := range s.books {
+		if review, ok := s.reviews[key.bookID]; ok && key.userID == user.ID && b.DeletedAt.IsZero() {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (r *memoryReviewRepo) Delete(ctx context.Context, bookID int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.book(key); !ok {
+		return ErrNotFound
+	}
+	if _, ok := s.reviews[bookID]; !ok {
+		return ErrReviewNotFound
+	}
+	delete(s.reviews, bookID)
+	return nil
+}
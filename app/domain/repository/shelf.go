@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ShelfRepo はユーザーが作る本棚の永続化のインターフェース。
+// 本は棚の名前を Book.Shelves に持つので、改名・削除は同じトランザクションで本の側も書き換える。
+type ShelfRepo interface {
+	// Create は棚を作る。同じ名前の棚があれば ErrShelfNameTaken。
+	Create(ctx context.Context, shelf *entity.Shelf) error
+	// FindAll はログイン中のユーザーの棚を名前順で返す。
+	FindAll(ctx context.Context) ([]entity.Shelf, error)
+	FindByID(ctx context.Context, id int) (*entity.Shelf, error)
+	// Update は棚を保存する。名前が変わっていれば、入っている本の shelves も新しい名前に書き換える。
+	Update(ctx context.Context, shelf *entity.Shelf) error
+	// Delete は棚を消し、入っていた本の shelves からも外す。
+	Delete(ctx context.Context, id int) error
+}
+
+// ErrShelfNotFound は棚がないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrShelfNotFound = fmt.Errorf("shelf %w", ErrNotFound)
+
+// ErrShelfNameTaken は同じ名前の棚がすでにあるときに返す。controller で 409 に変換する。
+var ErrShelfNameTaken = errors.New("shelf name already in use")
+
+const kindShelf = "Shelf"
+
+type shelfRepo struct{}
+
+func NewShelfRepo() ShelfRepo {
+	return &shelfRepo{}
+}
+
+func shelfKey(ctx context.Context, id int) (*datastore.Key, error) {
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datastore.IDKey(kindShelf, int64(id), uk), nil
+}
+
+// Create は同名チェックと保存の間に同じ名前の棚が作られないよう、トランザクション内で確認してから書く。
+func (r *shelfRepo) Create(ctx context.Context, shelf *entity.Shelf) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	var pk *datastore.PendingKey
+	commit, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		taken, err := labelNameTaken(ctx, ds, tx, uk, kindShelf, shelf.Name, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrShelfNameTaken
+		}
+		pk, err = tx.Put(datastore.IncompleteKey(kindShelf, uk), shelf)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	shelf.ID = int(commit.Key(pk).ID)
+	return nil
+}
+
+func (r *shelfRepo) FindAll(ctx context.Context) ([]entity.Shelf, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := datastore.NewQuery(kindShelf).Ancestor(uk).Order("name")
+	var shelves []entity.Shelf
+	keys, err := ds.GetAll(ctx, q, &shelves)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		shelves[i].ID = int(keys[i].ID)
+	}
+	return shelves, nil
+}
+
+func (r *shelfRepo) FindByID(ctx context.Context, id int) (*entity.Shelf, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := shelfKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	shelf := &entity.Shelf{}
+	if err := ds.Get(ctx, key, shelf); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrShelfNotFound
+		}
+		return nil, err
+	}
+	shelf.ID = id
+	return shelf, nil
+}
+
+func (r *shelfRepo) Update(ctx context.Context, shelf *entity.Shelf) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := shelfKey(ctx, shelf.ID)
+	if err != nil {
+		return err
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var old entity.Shelf
+		if err := tx.Get(key, &old); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrShelfNotFound
+			}
+			return err
+		}
+		if old.Name != shelf.Name {
+			taken, err := labelNameTaken(ctx, ds, tx, key.Parent, kindShelf, shelf.Name, shelf.ID)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return ErrShelfNameTaken
+			}
+			if err := relabelBooks(ctx, ds, tx, key.Parent, "shelves", old.Name, shelf.Name, bookShelves); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(key, shelf)
+		return err
+	})
+	return err
+}
+
+func (r *shelfRepo) Delete(ctx context.Context, id int) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := shelfKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var shelf entity.Shelf
+		if err := tx.Get(key, &shelf); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrShelfNotFound
+			}
+			return err
+		}
+		if err := relabelBooks(ctx, ds, tx, key.Parent, "shelves", shelf.Name, "", bookShelves); err != nil {
+			return err
+		}
+		return tx.Delete(key)
+	})
+	return err
+}
+
+// labelNameTaken は kind（Shelf / Tag）に name という名前のものが id 以外にあるか。
+// 棚・タグはユーザーの Key の子なので、祖先クエリにすればトランザクションの中で確認できる。
+func labelNameTaken(ctx context.Context, ds *datastore.Client, tx *datastore.Transaction, uk *datastore.Key, kind, name string, id int) (bool, error) {
+	q := datastore.NewQuery(kind).Ancestor(uk).FilterField("name", "=", name).KeysOnly().Transaction(tx)
+	keys, err := ds.GetAll(ctx, q, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, k := range keys {
+		if int(k.ID) != id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// relabelBooks は prop（shelves / tags）に from を持つ本を、トランザクション内で to に付け替える。to が空なら外す。
+// 本もユーザーの Key の子孫なので祖先クエリで探せる。1回のコミットで書ける件数（500）を超える棚・タグは想定していない。
+func relabelBooks(ctx context.Context, ds *datastore.Client, tx *datastore.Transaction, uk *datastore.Key, prop, from, to string, labels func(*entity.Book) *[]string) error {
+	q := datastore.NewQuery(kindBook).Ancestor(uk).FilterField(prop, "=", from).Transaction(tx)
+	var books []entity.Book
+	keys, err := ds.GetAll(ctx, q, &books)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	for i := range books {
+		l := labels(&books[i])
+		*l = replaceLabel(*l, from, to)
+		books[i].Version++
+	}
+	_, err = tx.PutMulti(keys, books)
+	return err
+}
+
+func bookShelves(b *entity.Book) *[]string { return &b.Shelves }
+
+func bookTags(b *entity.Book) *[]string { return &b.Tags }
+
+// replaceLabel は labels の from を to に置き換える（to が空なら取り除く）。置き換えた結果の重複は1つにまとめる。
+func replaceLabel(labels []string, from, to string) []string {
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if l == from {
+			l = to
+		}
+		if l == "" || slices.Contains(out, l) {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out
+}
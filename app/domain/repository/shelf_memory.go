@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryShelfRepo は ShelfRepo のインメモリ実装。棚の改名・削除で本も書き換えるので、
+// データは memoryBookRepo に持たせて同じ mutex の中で読み書きする。
+type memoryShelfRepo struct {
+	store *memoryBookRepo
+}
+
+// NewMemoryShelfRepo は books（NewMemoryBookRepo の戻り値）とデータを共有する実装を返す。
+func NewMemoryShelfRepo(books BookRepo) ShelfRepo {
+	return &memoryShelfRepo{store: books.(*memoryBookRepo)}
+}
+
+// memoryLabelKey は Datastore の User → Shelf / Tag の Key に当たる。
+type memoryLabelKey struct {
+	userID int
+	id     int
+}
+
+func (r *memoryBookRepo) labelKey(ctx context.Context, id int) (memoryLabelKey, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return memoryLabelKey{}, errNoUser
+	}
+	return memoryLabelKey{userID: user.ID, id: id}, nil
+}
+
+// relabel は Datastore 実装の relabelBooks と同じく、userID の本の from を to に付け替える（to が空なら外す）。
+func (r *memoryBookRepo) relabel(userID int, labels func(*entity.Book) *[]string, from, to string) {
+	for key, b := range r.books {
+		if key.userID != userID {
+			continue
+		}
+		l := labels(&b)
+		if !slices.Contains(*l, from) {
+			continue
+		}
+		*l = replaceLabel(*l, from, to)
+		b.Version++
+		r.books[key] = b
+	}
+}
+
+func (r *memoryShelfRepo) nameTaken(userID int, name string, id int) bool {
+	for key, s := range r.store.shelves {
+		if key.userID == userID && key.id != id && s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryShelfRepo) Create(ctx context.Context, shelf *entity.Shelf) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, s.nextShelfID)
+	if err != nil {
+		return err
+	}
+	if r.nameTaken(key.userID, shelf.Name, 0) {
+		return ErrShelfNameTaken
+	}
+	shelf.ID = s.nextShelfID
+	s.nextShelfID++
+	s.shelves[key] = *shelf
+	return nil
+}
+
+func (r *memoryShelfRepo) FindAll(ctx context.Context) ([]entity.Shelf, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	shelves := []entity.Shelf{}
+	for key, shelf := range s.shelves {
+		if key.userID == user.ID {
+			shelves = append(shelves, shelf)
+		}
+	}
+	sort.Slice(shelves, func(i, j int) bool { return shelves[i].Name < shelves[j].Name })
+	return shelves, nil
+}
+
+func (r *memoryShelfRepo) FindByID(ctx context.Context, id int) (*entity.Shelf, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	shelf, ok := s.shelves[key]
+	if !ok {
+		return nil, ErrShelfNotFound
+	}
+	return &shelf, nil
+}
+
+func (r *memoryShelfRepo) Update(ctx context.Context, shelf *entity.Shelf) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, shelf.ID)
+	if err != nil {
+		return err
+	}
+	old, ok := s.shelves[key]
+	if !ok {
+		return ErrShelfNotFound
+	}
+	if old.Name != shelf.Name {
+		if r.nameTaken(key.userID, shelf.Name, shelf.ID) {
+			return ErrShelfNameTaken
+		}
+		s.relabel(key.userID, bookShelves, old.Name, shelf.Name)
+	}
+	s.shelves[key] = *shelf
+	return nil
+}
+
+func (r *memoryShelfRepo) Delete(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	shelf, ok := s.shelves[key]
+	if !ok {
+		return ErrShelfNotFound
+	}
+	s.relabel(key.userID, bookShelves, shelf.Name, "")
+	delete(s.shelves, key)
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// StatusTransitioner はトランザクション内で最新の Book を受け取り、status を遷移させてその履歴を返す。
+type StatusTransitioner func(book *entity.Book) (*entity.StatusChange, error)
+
+// StatusChangeRepo は status 遷移履歴の永続化のインターフェース。
+// 遷移は Book の更新と履歴の追加を同じトランザクションで行う。
+type StatusChangeRepo interface {
+	Transition(ctx context.Context, bookID int, fn StatusTransitioner) (*entity.Book, *entity.StatusChange, error)
+	FindByBookID(ctx context.Context, bookID int) ([]entity.StatusChange, error)
+	// FindAll はログイン中のユーザーの全部の本の遷移履歴を古い順で返す（目標・統計の集計用）。
+	FindAll(ctx context.Context) ([]entity.StatusChange, error)
+}
+
+const kindStatusChange = "StatusChange"
+
+type statusChangeRepo struct{}
+
+func NewStatusChangeRepo() StatusChangeRepo {
+	return &statusChangeRepo{}
+}
+
+func (r *statusChangeRepo) Transition(ctx context.Context, bookID int, fn StatusTransitioner) (*entity.Book, *entity.StatusChange, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var book entity.Book
+	var change *entity.StatusChange
+	var pk *datastore.PendingKey
+	commit, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		book = entity.Book{}
+		if err := getBook(ctx, ds, tx, bk, &book); err != nil {
+			return err
+		}
+		book.ID = bookID
+		if change, err = fn(&book); err != nil {
+			return err
+		}
+		if pk, err = tx.Put(datastore.IncompleteKey(kindStatusChange, bk), change); err != nil {
+			return err
+		}
+		book.Version++
+		_, err = tx.Put(bk, &book)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	change.ID = int(commit.Key(pk).ID)
+	change.BookID = bookID
+	return &book, change, nil
+}
+
+func (r *statusChangeRepo) FindByBookID(ctx context.Context, bookID int) ([]entity.StatusChange, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := getBook(ctx, ds, nil, bk, &entity.Book{}); err != nil {
+		return nil, err
+	}
+	var changes []entity.StatusChange
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindStatusChange).Ancestor(bk), &changes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		changes[i].ID = int(keys[i].ID)
+		changes[i].BookID = bookID
+	}
+	sortStatusChanges(changes)
+	return changes, nil
+}
+
+func (r *statusChangeRepo) FindAll(ctx context.Context) ([]entity.StatusChange, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var changes []entity.StatusChange
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindStatusChange).Ancestor(uk), &changes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		changes[i].ID = int(keys[i].ID)
+		changes[i].BookID = int(keys[i].Parent.ID)
+	}
+	trashed, err := trashedBookIDs(ctx, ds, uk)
+	if err != nil {
+		return nil, err
+	}
+	changes = withoutTrashed(changes, trashed, func(c entity.StatusChange) int { return c.BookID })
+	sortStatusChanges(changes)
+	return changes, nil
+}
+
+// putStatusChange は他の repository のトランザクションから遷移履歴を追加するときに使う。change が nil なら何もしない。
+func putStatusChange(tx *datastore.Transaction, bk *datastore.Key, change *entity.StatusChange) error {
+	if change == nil {
+		return nil
+	}
+	_, err := tx.Put(datastore.IncompleteKey(kindStatusChange, bk), change)
+	return err
+}
+
+// sortStatusChanges は履歴を古い順（同時刻は ID 順）に並べる。
+func sortStatusChanges(changes []entity.StatusChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].ChangedAt.Equal(changes[j].ChangedAt) {
+			return changes[i].ChangedAt.Before(changes[j].ChangedAt)
+		}
+		return changes[i].ID < changes[j].ID
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryStatusChangeRepo は StatusChangeRepo のインメモリ実装。データは memoryBookRepo と共有する。
+type memoryStatusChangeRepo struct {
+	store *memoryBookRepo
+}
+
+// NewMemoryStatusChangeRepo は books（NewMemoryBookRepo の戻り値）とデータを共有する実装を返す。
+func NewMemoryStatusChangeRepo(books BookRepo) StatusChangeRepo {
+	return &memoryStatusChangeRepo{store: books.(*memoryBookRepo)}
+}
+
+func (r *memoryStatusChangeRepo) Transition(ctx context.Context, bookID int, fn StatusTransitioner) (*entity.Book, *entity.StatusChange, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	book, ok := s.book(key)
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	change, err := fn(&book)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.addStatusChange(bookID, change)
+	book.Version++
+	s.books[key] = book
+	return &book, change, nil
+}
+
+func (r *memoryStatusChangeRepo) FindByBookID(ctx context.Context, bookID int) ([]entity.StatusChange, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.bookKey(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.book(key); !ok {
+		return nil, ErrNotFound
+	}
+	var changes []entity.StatusChange
+	for _, c := range s.statusChanges {
+		if c.BookID == bookID {
+			changes = append(changes, c)
+		}
+	}
+	sortStatusChanges(changes)
+	return changes, nil
+}
+
+func (r *memoryStatusChangeRepo) FindAll(ctx context.Context) ([]entity.StatusChange, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	var changes []entity.StatusChange
+	for _, c := range s.statusChanges {
+		if _, ok := s.book(memoryBookKey{userID: user.ID, bookID: c.BookID}); ok {
+			changes = append(changes, c)
+		}
+	}
+	sortStatusChanges(changes)
+	return changes, nil
+}
+
+// addStatusChange は呼び出し側で mu を取っている前提。change に採番した ID を書き戻す。
+func (r *memoryBookRepo) addStatusChange(bookID int, change *entity.StatusChange) {
+	if change == nil {
+		return
+	}
+	change.ID = r.nextStatusChangeID
+	change.BookID = bookID
+	r.nextStatusChangeID++
+	r.statusChanges[change.ID] = *change
+}
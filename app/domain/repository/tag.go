@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// TagRepo は本に付けるタグの永続化のインターフェース。棚と同じく本は名前を Book.Tags に持ち、
+// 改名・削除は同じトランザクションで本の側も書き換える。
+type TagRepo interface {
+	// Create はタグを作る。同じ名前のタグがあれば ErrTagNameTaken。
+	Create(ctx context.Context, tag *entity.Tag) error
+	// Ensure は names のうちまだないタグを作る。本の作成・更新で自由にタグを付けられるようにするためのもの。
+	Ensure(ctx context.Context, names []string) error
+	// FindAll はログイン中のユーザーのタグを名前順で返す。
+	FindAll(ctx context.Context) ([]entity.Tag, error)
+	FindByID(ctx context.Context, id int) (*entity.Tag, error)
+	// Update はタグを保存する。名前が変わっていれば、付いている本の tags も新しい名前に書き換える。
+	Update(ctx context.Context, tag *entity.Tag) error
+	// Delete はタグを消し、付いていた本の tags からも外す。
+	Delete(ctx context.Context, id int) error
+}
+
+// ErrTagNotFound はタグがないときに返す。errors.Is(err, ErrNotFound) でも判定できる。
+var ErrTagNotFound = fmt.Errorf("tag %w", ErrNotFound)
+
+// ErrTagNameTaken は同じ名前のタグがすでにあるときに返す。controller で 409 に変換する。
+var ErrTagNameTaken = errors.New("tag name already in use")
+
+const kindTag = "Tag"
+
+type tagRepo struct{}
+
+func NewTagRepo() TagRepo {
+	return &tagRepo{}
+}
+
+func tagKey(ctx context.Context, id int) (*datastore.Key, error) {
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datastore.IDKey(kindTag, int64(id), uk), nil
+}
+
+func (r *tagRepo) Create(ctx context.Context, tag *entity.Tag) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	var pk *datastore.PendingKey
+	commit, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		taken, err := labelNameTaken(ctx, ds, tx, uk, kindTag, tag.Name, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrTagNameTaken
+		}
+		pk, err = tx.Put(datastore.IncompleteKey(kindTag, uk), tag)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tag.ID = int(commit.Key(pk).ID)
+	return nil
+}
+
+func (r *tagRepo) Ensure(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		q := datastore.NewQuery(kindTag).Ancestor(uk).Transaction(tx)
+		var existing []entity.Tag
+		if _, err := ds.GetAll(ctx, q, &existing); err != nil {
+			return err
+		}
+		have := map[string]bool{}
+		for _, t := range existing {
+			have[t.Name] = true
+		}
+		now := time.Now()
+		var keys []*datastore.Key
+		var tags []entity.Tag
+		for _, name := range names {
+			if have[name] {
+				continue
+			}
+			have[name] = true
+			keys = append(keys, datastore.IncompleteKey(kindTag, uk))
+			tags = append(tags, entity.Tag{Name: name, CreatedAt: now, UpdatedAt: now})
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		_, err := tx.PutMulti(keys, tags)
+		return err
+	})
+	return err
+}
+
+func (r *tagRepo) FindAll(ctx context.Context) ([]entity.Tag, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := datastore.NewQuery(kindTag).Ancestor(uk).Order("name")
+	var tags []entity.Tag
+	keys, err := ds.GetAll(ctx, q, &tags)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		tags[i].ID = int(keys[i].ID)
+	}
+	return tags, nil
+}
+
+func (r *tagRepo) FindByID(ctx context.Context, id int) (*entity.Tag, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := tagKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tag := &entity.Tag{}
+	if err := ds.Get(ctx, key, tag); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	tag.ID = id
+	return tag, nil
+}
+
+func (r *tagRepo) Update(ctx context.Context, tag *entity.Tag) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := tagKey(ctx, tag.ID)
+	if err != nil {
+		return err
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var old entity.Tag
+		if err := tx.Get(key, &old); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrTagNotFound
+			}
+			return err
+		}
+		if old.Name != tag.Name {
+			taken, err := labelNameTaken(ctx, ds, tx, key.Parent, kindTag, tag.Name, tag.ID)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return ErrTagNameTaken
+			}
+			if err := relabelBooks(ctx, ds, tx, key.Parent, "tags", old.Name, tag.Name, bookTags); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Put(key, tag)
+		return err
+	})
+	return err
+}
+
+func (r *tagRepo) Delete(ctx context.Context, id int) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := tagKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var tag entity.Tag
+		if err := tx.Get(key, &tag); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrTagNotFound
+			}
+			return err
+		}
+		if err := relabelBooks(ctx, ds, tx, key.Parent, "tags", tag.Name, "", bookTags); err != nil {
+			return err
+		}
+		return tx.Delete(key)
+	})
+	return err
+}
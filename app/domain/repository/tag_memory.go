@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryTagRepo は TagRepo のインメモリ実装。棚と同じくデータは memoryBookRepo に持たせる。
+type memoryTagRepo struct {
+	store *memoryBookRepo
+}
+
+// NewMemoryTagRepo は books（NewMemoryBookRepo の戻り値）とデータを共有する実装を返す。
+func NewMemoryTagRepo(books BookRepo) TagRepo {
+	return &memoryTagRepo{store: books.(*memoryBookRepo)}
+}
+
+func (r *memoryTagRepo) nameTaken(userID int, name string, id int) bool {
+	for key, t := range r.store.tags {
+		if key.userID == userID && key.id != id && t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryTagRepo) Create(ctx context.Context, tag *entity.Tag) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, s.nextTagID)
+	if err != nil {
+		return err
+	}
+	if r.nameTaken(key.userID, tag.Name, 0) {
+		return ErrTagNameTaken
+	}
+	tag.ID = s.nextTagID
+	s.nextTagID++
+	s.tags[key] = *tag
+	return nil
+}
+
+func (r *memoryTagRepo) Ensure(ctx context.Context, names []string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return errNoUser
+	}
+	now := time.Now()
+	for _, name := range names {
+		if r.nameTaken(user.ID, name, 0) {
+			continue
+		}
+		key := memoryLabelKey{userID: user.ID, id: s.nextTagID}
+		s.tags[key] = entity.Tag{ID: s.nextTagID, Name: name, CreatedAt: now, UpdatedAt: now}
+		s.nextTagID++
+	}
+	return nil
+}
+
+func (r *memoryTagRepo) FindAll(ctx context.Context) ([]entity.Tag, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, errNoUser
+	}
+	tags := []entity.Tag{}
+	for key, t := range s.tags {
+		if key.userID == user.ID {
+			tags = append(tags, t)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *memoryTagRepo) FindByID(ctx context.Context, id int) (*entity.Tag, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := s.tags[key]
+	if !ok {
+		return nil, ErrTagNotFound
+	}
+	return &t, nil
+}
+
+func (r *memoryTagRepo) Update(ctx context.Context, tag *entity.Tag) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, tag.ID)
+	if err != nil {
+		return err
+	}
+	old, ok := s.tags[key]
+	if !ok {
+		return ErrTagNotFound
+	}
+	if old.Name != tag.Name {
+		if r.nameTaken(key.userID, tag.Name, tag.ID) {
+			return ErrTagNameTaken
+		}
+		s.relabel(key.userID, bookTags, old.Name, tag.Name)
+	}
+	s.tags[key] = *tag
+	return nil
+}
+
+func (r *memoryTagRepo) Delete(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := s.labelKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	t, ok := s.tags[key]
+	if !ok {
+		return ErrTagNotFound
+	}
+	s.relabel(key.userID, bookTags, t.Name, "")
+	delete(s.tags, key)
+	return nil
+}
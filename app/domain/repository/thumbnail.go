@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ThumbnailRepo はアップロードした表紙画像の記録の永続化のインターフェース。
+// 画像ファイルそのものは ThumbnailStore に置き、ここではどの本に付いているかとアップロード日時を持つ。
+type ThumbnailRepo interface {
+	Create(ctx context.Context, thumbnail *entity.Thumbnail) error
+	FindByID(ctx context.Context, id string) (*entity.Thumbnail, error)
+	// Attach は画像を本に付ける。別の本に付いていれば ErrThumbnailInUse。
+	Attach(ctx context.Context, id string, bookID int) (*entity.Thumbnail, error)
+	Delete(ctx context.Context, id string) error
+	// DeleteUnattached は before より前にアップロードされ、どの本にも付いていない画像の記録を全ユーザー分消し、消したものを返す。
+	DeleteUnattached(ctx context.Context, before time.Time) ([]entity.Thumbnail, error)
+}
+
+// ErrThumbnailInUse は別の本に付いている画像を付けようとしたときに返す。controller で 409 に変換する。
+var ErrThumbnailInUse = errors.New("thumbnail is attached to another book")
+
+const kindThumbnail = "Thumbnail"
+
+type thumbnailRepo struct{}
+
+func NewThumbnailRepo() ThumbnailRepo {
+	return &thumbnailRepo{}
+}
+
+// thumbnailKey はログイン中のユーザーの画像の Key。本と同じく他のユーザーの画像は Key が一致しない。
+func thumbnailKey(ctx context.Context, id string) (*datastore.Key, error) {
+	uk, err := userKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return datastore.NameKey(kindThumbnail, id, uk), nil
+}
+
+func (r *thumbnailRepo) Create(ctx context.Context, thumbnail *entity.Thumbnail) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := thumbnailKey(ctx, thumbnail.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := ds.Put(ctx, key, thumbnail); err != nil {
+		return err
+	}
+	thumbnail.UserID = int(key.Parent.ID)
+	return nil
+}
+
+func (r *thumbnailRepo) FindByID(ctx context.Context, id string) (*entity.Thumbnail, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := thumbnailKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t := &entity.Thumbnail{}
+	if err := ds.Get(ctx, key, t); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	fillThumbnailKey(t, key)
+	return t, nil
+}
+
+// Attach は GC と同時に走っても消えかけの画像を付けないよう、トランザクション内で読み直してから書く。
+func (r *thumbnailRepo) Attach(ctx context.Context, id string, bookID int) (*entity.Thumbnail, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := thumbnailKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t := &entity.Thumbnail{}
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		*t = entity.Thumbnail{}
+		if err := tx.Get(key, t); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrNotFound
+			}
+			return err
+		}
+		if t.BookID == bookID {
+			return nil
+		}
+		if t.BookID != 0 {
+			return ErrThumbnailInUse
+		}
+		t.BookID = bookID
+		t.AttachedAt = time.Now()
+		_, err := tx.Put(key, t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	fillThumbnailKey(t, key)
+	return t, nil
+}
+
+func (r *thumbnailRepo) Delete(ctx context.Context, id string) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := thumbnailKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ds.Delete(ctx, key)
+}
+
+// DeleteUnattached はユーザーをまたぐので祖先なしのクエリで探す（index.yaml に bookId + uploadedAt の複合インデックスが必要）。
+// 探してから消すまでの間に本に付けられたものは、トランザクション内で読み直して残す。
+func (r *thumbnailRepo) DeleteUnattached(ctx context.Context, before time.Time) ([]entity.Thumbnail, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := datastore.NewQuery(kindThumbnail).
+		FilterField("bookId", "=", 0).
+		FilterField("uploadedAt", "<", before).
+		KeysOnly()
+	keys, err := ds.GetAll(ctx, q, nil)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []entity.Thumbnail
+	for _, key := range keys {
+		var t entity.Thumbnail
+		_, err := ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+			t = entity.Thumbnail{}
+			if err := tx.Get(key, &t); err != nil {
+				return err
+			}
+			if t.BookID != 0 {
+				return errThumbnailAttached
+			}
+			return tx.Delete(key)
+		})
+		if err == errThumbnailAttached || err == datastore.ErrNoSuchEntity {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		fillThumbnailKey(&t, key)
+		deleted = append(deleted, t)
+	}
+	return deleted, nil
+}
+
+// errThumbnailAttached は DeleteUnattached のトランザクションを何も書かずに抜けるための内部エラー。
+var errThumbnailAttached = errors.New("thumbnail attached")
+
+func fillThumbnailKey(t *entity.Thumbnail, key *datastore.Key) {
+	t.ID = key.Name
+	if key.Parent != nil {
+		t.UserID = int(key.Parent.ID)
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryThumbnailRepo は ThumbnailRepo のインメモリ実装。ID はランダムなので全ユーザーで1つの map に入れ、
+// 持ち主が違う画像は Datastore 実装と同じく ErrNotFound にする。
+type memoryThumbnailRepo struct {
+	mu         sync.Mutex
+	thumbnails map[string]entity.Thumbnail
+}
+
+func NewMemoryThumbnailRepo() ThumbnailRepo {
+	return &memoryThumbnailRepo{thumbnails: map[string]entity.Thumbnail{}}
+}
+
+// own はログイン中のユーザーの画像を返す。
+func (r *memoryThumbnailRepo) own(ctx context.Context, id string) (entity.Thumbnail, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return entity.Thumbnail{}, errNoUser
+	}
+	t, ok := r.thumbnails[id]
+	if !ok || t.UserID != user.ID {
+		return entity.Thumbnail{}, ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *memoryThumbnailRepo) Create(ctx context.Context, thumbnail *entity.Thumbnail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return errNoUser
+	}
+	thumbnail.UserID = user.ID
+	r.thumbnails[thumbnail.ID] = *thumbnail
+	return nil
+}
+
+func (r *memoryThumbnailRepo) FindByID(ctx context.Context, id string) (*entity.Thumbnail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.own(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *memoryThumbnailRepo) Attach(ctx context.Context, id string, bookID int) (*entity.Thumbnail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, err := r.own(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.BookID != bookID {
+		if t.BookID != 0 {
+			return nil, ErrThumbnailInUse
+		}
+		t.BookID = bookID
+		t.AttachedAt = time.Now()
+		r.thumbnails[id] = t
+	}
+	return &t, nil
+}
+
+func (r *memoryThumbnailRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.own(ctx, id); err != nil {
+		if err == ErrNotFound {
+			return nil // Datastore の Delete と同じく、ないものを消してもエラーにしない
+		}
+		return err
+	}
+	delete(r.thumbnails, id)
+	return nil
+}
+
+func (r *memoryThumbnailRepo) DeleteUnattached(ctx context.Context, before time.Time) ([]entity.Thumbnail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted []entity.Thumbnail
+	for id, t := range r.thumbnails {
+		if t.BookID == 0 && t.UploadedAt.Before(before) {
+			delete(r.thumbnails, id)
+			deleted = append(deleted, t)
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i].UploadedAt.Before(deleted[j].UploadedAt) })
+	return deleted, nil
+}
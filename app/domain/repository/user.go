@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/datastore"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// UserRepo はユーザーの永続化のインターフェース。メールアドレスはユーザー間で重複させない。
+type UserRepo interface {
+	Create(ctx context.Context, user *entity.User) error
+	FindByID(ctx context.Context, id int) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	FindAll(ctx context.Context) ([]entity.User, error) // 管理コマンド・定期処理用（全ユーザー）
+	// Update は名前・設定を保存する。メールアドレスと remindedOn は変えない
+	Update(ctx context.Context, user *entity.User) error
+	// SetRemindedOn は remindedOn が from のときだけ to にし、書き換えたかどうかを返す。
+	// 読んでから書くまでをトランザクションで行うので、同じ日のリマインダーを確かめられるのは1つのインスタンスだけになる。
+	SetRemindedOn(ctx context.Context, id int, from, to time.Time) (bool, error)
+}
+
+// ErrEmailTaken は登録済みのメールアドレスで作成しようとしたときに返す。controller で 409 に変換する。
+var ErrEmailTaken = errors.New("email already registered")
+
+const (
+	kindUser      = "User"
+	kindUserEmail = "UserEmail" // メールアドレスを Key 名にした一意制約用のエンティティ
+)
+
+type userEmail struct {
+	UserID int64 `datastore:"userId,noindex"`
+}
+
+type userRepo struct{}
+
+func NewUserRepo() UserRepo {
+	return &userRepo{}
+}
+
+func (r *userRepo) Create(ctx context.Context, user *entity.User) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	// UserEmail に User の ID を書くため、トランザクションの前に ID を確定させておく
+	keys, err := ds.AllocateIDs(ctx, []*datastore.Key{datastore.IncompleteKey(kindUser, nil)})
+	if err != nil {
+		return err
+	}
+	uk := keys[0]
+	ek := datastore.NameKey(kindUserEmail, user.Email, nil)
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		err := tx.Get(ek, &userEmail{})
+		if err == nil {
+			return ErrEmailTaken
+		}
+		if err != datastore.ErrNoSuchEntity {
+			return err
+		}
+		if _, err := tx.Put(ek, &userEmail{UserID: uk.ID}); err != nil {
+			return err
+		}
+		_, err = tx.Put(uk, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	user.ID = int(uk.ID)
+	return nil
+}
+
+func (r *userRepo) FindByID(ctx context.Context, id int) (*entity.User, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := datastore.IDKey(kindUser, int64(id), nil)
+	user := &entity.User{}
+	if err := ds.Get(ctx, key, user); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	user.ID = int(key.ID)
+	return user, nil
+}
+
+func (r *userRepo) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ue userEmail
+	if err := ds.Get(ctx, datastore.NameKey(kindUserEmail, email, nil), &ue); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return r.FindByID(ctx, int(ue.UserID))
+}
+
+func (r *userRepo) FindAll(ctx context.Context) ([]entity.User, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var users []entity.User
+	keys, err := ds.GetAll(ctx, datastore.NewQuery(kindUser), &users)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		users[i].ID = int(keys[i].ID)
+	}
+	return users, nil
+}
+
+func (r *userRepo) Update(ctx context.Context, user *entity.User) error {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	key := datastore.IDKey(kindUser, int64(user.ID), nil)
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var current entity.User
+		if err := tx.Get(key, &current); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrNotFound
+			}
+			return err
+		}
+		user.Email = current.Email
+		user.RemindedOn = current.RemindedOn
+		_, err := tx.Put(key, user)
+		return err
+	})
+	return err
+}
+
+func (r *userRepo) SetRemindedOn(ctx context.Context, id int, from, to time.Time) (bool, error) {
+	ds, err := clientFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	key := datastore.IDKey(kindUser, int64(id), nil)
+	set := false
+	_, err = ds.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		set = false
+		var current entity.User
+		if err := tx.Get(key, &current); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrNotFound
+			}
+			return err
+		}
+		if !current.RemindedOn.Equal(from) {
+			return nil
+		}
+		current.RemindedOn = to
+		if _, err := tx.Put(key, &current); err != nil {
+			return err
+		}
+		set = true
+		return nil
+	})
+	return set, err
+}
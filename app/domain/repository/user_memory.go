@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// memoryUserRepo は UserRepo のインメモリ実装。
+type memoryUserRepo struct {
+	mu      sync.Mutex
+	nextID  int
+	users   map[int]entity.User
+	byEmail map[string]int
+}
+
+func NewMemoryUserRepo() UserRepo {
+	return &memoryUserRepo{
+		nextID:  1,
+		users:   map[int]entity.User{},
+		byEmail: map[string]int{},
+	}
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byEmail[user.Email]; ok {
+		return ErrEmailTaken
+	}
+	user.ID = r.nextID
+	r.nextID++
+	r.users[user.ID] = *user
+	r.byEmail[user.Email] = user.ID
+	return nil
+}
+
+func (r *memoryUserRepo) FindByID(ctx context.Context, id int) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepo) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.byEmail[email]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	u := r.users[id]
+	return &u, nil
+}
+
+func (r *memoryUserRepo) FindAll(ctx context.Context) ([]entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]entity.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.users[user.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Email = current.Email
+	user.RemindedOn = current.RemindedOn
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepo) SetRemindedOn(ctx context.Context, id int, from, to time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.users[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if !current.RemindedOn.Equal(from) {
+		return false, nil
+	}
+	current.RemindedOn = to
+	r.users[id] = current
+	return true, nil
+}
+
+// memoryAuthTokenRepo は AuthTokenRepo のインメモリ実装。
+type memoryAuthTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]entity.AuthToken
+}
+
+func NewMemoryAuthTokenRepo() AuthTokenRepo {
+	return &memoryAuthTokenRepo{tokens: map[string]entity.AuthToken{}}
+}
+
+func (r *memoryAuthTokenRepo) Create(ctx context.Context, token *entity.AuthToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (r *memoryAuthTokenRepo) FindByHash(ctx context.Context, hash string) (*entity.AuthToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (r *memoryAuthTokenRepo) Delete(ctx context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, hash)
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// ログインセッションの有効期間
+const authTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidCredentials はメールアドレスかパスワードが違うときに返す。どちらが違うかは区別しない。controller で 401 に変換する。
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrUnauthenticated はトークンがない・期限切れ・失効済みのときに返す。controller で 401 に変換する。
+	ErrUnauthenticated = errors.New("authentication required")
+)
+
+type AuthSvc struct {
+	userRepo  repository.UserRepo
+	tokenRepo repository.AuthTokenRepo
+}
+
+func NewAuthService(userRepo repository.UserRepo, tokenRepo repository.AuthTokenRepo) *AuthSvc {
+	return &AuthSvc{userRepo: userRepo, tokenRepo: tokenRepo}
+}
+
+// SignUp はユーザーを登録する。パスワードは bcrypt でハッシュ化して保存する（入力は request 層で検証済み）。
+func (s *AuthSvc) SignUp(ctx context.Context, email, name, password string) (*entity.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &entity.User{
+		Email:        normalizeEmail(email),
+		Name:         name,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login はパスワードを確認し、新しいセッショントークンを発行する。トークンそのものは呼び出し側にだけ返す。
+func (s *AuthSvc) Login(ctx context.Context, email, password string) (string, *entity.AuthToken, *entity.User, error) {
+	user, err := s.userRepo.FindByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", nil, nil, ErrInvalidCredentials
+		}
+		return "", nil, nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, nil, ErrInvalidCredentials
+	}
+	token, authToken, err := s.IssueToken(ctx, user)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return token, authToken, user, nil
+}
+
+// Authenticate はセッショントークンからユーザーを引く。期限切れのトークンはその場で消す。
+func (s *AuthSvc) Authenticate(ctx context.Context, token string) (*entity.User, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	hash := hashToken(token)
+	t, err := s.tokenRepo.FindByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if time.Now().After(t.ExpiresAt) {
+		if err := s.tokenRepo.Delete(ctx, hash); err != nil {
+			return nil, err
+		}
+		return nil, ErrUnauthenticated
+	}
+	user, err := s.userRepo.FindByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUser は変更済みのユーザーの名前・設定を保存する。
+func (s *AuthSvc) UpdateUser(ctx context.Context, user *entity.User) error {
+	return s.userRepo.Update(ctx, user)
+}
+
+// Logout はセッショントークンを失効させる。
+func (s *AuthSvc) Logout(ctx context.Context, token string) error {
+	return s.tokenRepo.Delete(ctx, hashToken(token))
+}
+
+// IssueToken は user の新しいセッショントークンを発行する。保存するのはハッシュだけ。
+func (s *AuthSvc) IssueToken(ctx context.Context, user *entity.User) (string, *entity.AuthToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	now := time.Now()
+	t := &entity.AuthToken{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(authTokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, t); err != nil {
+		return "", nil, err
+	}
+	return token, t, nil
+}
+
+// hashToken はトークンを保存用の SHA-256 に変換する。DB が漏れてもそのままではログインに使えない。
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
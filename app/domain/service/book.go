@@ -3,26 +3,250 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
 
 	"github.com/sora-00/booktracker-api/app/domain/entity"
 	"github.com/sora-00/booktracker-api/app/domain/repository"
 )
 
 type BookSvc struct {
-	repo repository.BookRepo // 抽象interfaceに依存
+	repo        repository.BookRepo // 抽象interfaceに依存
+	sessionRepo repository.ReadingSessionRepo
+	statusRepo  repository.StatusChangeRepo
+	thumbnails  *ThumbnailSvc
+	labels      *LabelSvc
+	// requireIfMatch が true なら、本の更新・削除に If-Match を必須にする
+	requireIfMatch bool
 }
 
-func NewService(repo repository.BookRepo) *BookSvc {
-	return &BookSvc{repo: repo}
+func NewService(repo repository.BookRepo, sessionRepo repository.ReadingSessionRepo, statusRepo repository.StatusChangeRepo, thumbnails *ThumbnailSvc, labels *LabelSvc, requireIfMatch bool) *BookSvc {
+	return &BookSvc{repo: repo, sessionRepo: sessionRepo, statusRepo: statusRepo, thumbnails: thumbnails, labels: labels, requireIfMatch: requireIfMatch}
 }
 
-// CreateBook は新しい本を登録する（入力は request 層で検証済み）
+// ErrPreconditionFailed は If-Match の version が今の本と違う（別の端末で更新された）ときに返す。controller で 412 に変換する。
+var ErrPreconditionFailed = errors.New("the book has been modified since it was read")
+
+// ErrPreconditionRequired は If-Match を必須にしていて、ヘッダーがないときに返す。controller で 428 に変換する。
+var ErrPreconditionRequired = errors.New("If-Match header is required")
+
+// Precondition は If-Match で指定された本の version（ETag）。Any は "*"（本があれば version は問わない）。
+type Precondition struct {
+	Any      bool
+	Versions []int
+}
+
+// matches は book が pre に合うか。pre が nil（If-Match がない）なら合うことにする。
+func (pre *Precondition) matches(book *entity.Book) bool {
+	return pre == nil || pre.Any || slices.Contains(pre.Versions, book.Version)
+}
+
+// checkPrecondition は repository のトランザクションの中で最新の本が pre に合うかを確かめる。
+func checkPrecondition(pre *Precondition) repository.BookCheck {
+	return func(book *entity.Book) error {
+		if !pre.matches(book) {
+			return ErrPreconditionFailed
+		}
+		return nil
+	}
+}
+
+// CreateBook は新しい本を登録する（入力は request 層で検証済み）。入れる棚は先に作っておく必要があり、
+// タグはまだなければ作る。
 func (s *BookSvc) CreateBook(ctx context.Context, book *entity.Book) (*entity.Book, error) {
 	if book == nil {
 		return nil, errors.New("book is required")
 	}
+	if err := s.prepareCreate(ctx, book); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Create(ctx, book); err != nil {
 		return nil, err
 	}
+	if err := s.attachCreated(ctx, book); err != nil {
+		if derr := s.repo.Delete(ctx, book.ID, nil); derr != nil {
+			log.Printf("book: rollback create %d: %v", book.ID, derr)
+		}
+		return nil, err
+	}
+	return book, nil
+}
+
+// prepareCreate は登録する前に棚・タグを確かめ、表紙画像があれば thumbnailUrl を画像の URL にしておく（ID が決まる前なので付けはしない）。
+// 登録時の readPages は読書記録を足しても消えないよう baseReadPages にも入れる。
+func (s *BookSvc) prepareCreate(ctx context.Context, book *entity.Book) error {
+	book.BaseReadPages = book.ReadPages
+	if err := s.labels.labelBook(ctx, book); err != nil {
+		return err
+	}
+	if book.ThumbnailID != "" {
+		t, err := s.thumbnails.find(ctx, book.ThumbnailID, 0)
+		if err != nil {
+			return err
+		}
+		book.ThumbnailUrl = t.URL
+	}
+	return nil
+}
+
+// attachCreated は登録した本に表紙画像を付ける。確認してから付けるまでに別の本に付けられた・GC で消えた場合はエラーになるので、
+// 呼び出し側で作った本を取り消す。
+func (s *BookSvc) attachCreated(ctx context.Context, book *entity.Book) error {
+	if book.ThumbnailID == "" {
+		return nil
+	}
+	_, err := s.thumbnails.Attach(ctx, book.ThumbnailID, book.ID)
+	return err
+}
+
+// UpdateBook は edit で本を書き換えて保存する。読んでから書くまでは repository のトランザクションの中で行い、
+// その時点の version が pre（If-Match）に合わなければ ErrPreconditionFailed を返す。edit が error を返せばそのまま返す。
+// edit で thumbnailId が変わったら新しい画像を付けて thumbnailUrl をその URL にし、使われなくなった前の画像を消す。
+// status が変わったら edit の遷移として履歴に残す。棚・タグは CreateBook と同じく確かめる。
+// status・readPages を直接書き換えられるのは登録時の入力の誤りを直すためで、読書記録か edit 以外の遷移の履歴がある本では
+// ErrInvalidTransition を返す（status は遷移の操作で、readPages は読書記録で変える）。
+func (s *BookSvc) UpdateBook(ctx context.Context, id int, pre *Precondition, edit func(book *entity.Book) error) (*entity.Book, error) {
+	u := &bookUpdate{BookEdit: BookEdit{ID: id, Pre: pre, Edit: edit}}
+	if err := s.prepareUpdate(ctx, u); err != nil {
+		return nil, err
+	}
+	book, err := s.repo.Update(ctx, id, u.editor())
+	if err != nil {
+		return nil, err
+	}
+	s.removeReplaced(ctx, u, book)
 	return book, nil
 }
+
+// BookEdit は1冊分の更新。Pre は If-Match（なければ nil）で、Edit で本を書き換える。
+type BookEdit struct {
+	ID   int
+	Pre  *Precondition
+	Edit func(book *entity.Book) error
+}
+
+// bookUpdate は UpdateBook・UpdateBooks で1冊を更新する途中の状態。
+type bookUpdate struct {
+	BookEdit
+	thumbnail *entity.Thumbnail // prepareUpdate で付けた新しい画像
+	old       string            // editor で書き換える前の画像
+	progress  int               // prepareUpdate で status・readPages を書き換えてよいと確かめた本の version。確かめていなければ 0
+}
+
+// prepareUpdate は棚・タグの確認と画像を付けるのがトランザクションの外なので、先に今の本に edit を当てて確かめておく。
+func (s *BookSvc) prepareUpdate(ctx context.Context, u *bookUpdate) error {
+	if u.Pre == nil && s.requireIfMatch {
+		return ErrPreconditionRequired
+	}
+	current, err := s.repo.FindByID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if !u.Pre.matches(current) {
+		return ErrPreconditionFailed
+	}
+	preview := *current
+	if err := u.Edit(&preview); err != nil {
+		return err
+	}
+	if err := s.labels.labelBook(ctx, &preview); err != nil {
+		return err
+	}
+	if preview.Status != current.Status || preview.ReadPages != current.ReadPages {
+		if err := s.checkProgressEditable(ctx, current.ID); err != nil {
+			return err
+		}
+		u.progress = current.Version
+	}
+	if preview.ThumbnailID != "" && preview.ThumbnailID != current.ThumbnailID {
+		// このあとトランザクションが失敗しても画像はこの本に付いたままなので、同じ thumbnailId でやり直せる
+		if u.thumbnail, err = s.thumbnails.Attach(ctx, preview.ThumbnailID, u.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProgressEditable は本の status・readPages を直接書き換えてよいか確かめる。読書記録か、edit 以外の遷移の履歴があればいけない。
+func (s *BookSvc) checkProgressEditable(ctx context.Context, bookID int) error {
+	sessions, err := s.sessionRepo.FindByBookID(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if len(sessions) > 0 {
+		return fmt.Errorf("%w: status and readPages of a book with reading sessions change only through sessions and status actions", ErrInvalidTransition)
+	}
+	changes, err := s.statusRepo.FindByBookID(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if c.Action != entity.TransitionEdit {
+			return fmt.Errorf("%w: status and readPages of a book with status history change only through sessions and status actions", ErrInvalidTransition)
+		}
+	}
+	return nil
+}
+
+// editor は repository のトランザクションの中で最新の本に edit を当てる。
+func (u *bookUpdate) editor() repository.BookEditor {
+	check := checkPrecondition(u.Pre)
+	return func(book *entity.Book) (*entity.StatusChange, error) {
+		if err := check(book); err != nil {
+			return nil, err
+		}
+		u.old = book.ThumbnailID
+		from, readPages, version := book.Status, book.ReadPages, book.Version
+		if err := u.Edit(book); err != nil {
+			return nil, err
+		}
+		if (book.Status != from || book.ReadPages != readPages) && u.progress != version {
+			// 確かめてから書くまでに読書記録や遷移で本が変わった
+			return nil, ErrPreconditionFailed
+		}
+		if book.ReadPages != readPages {
+			// 読書記録のない本なので、直した readPages をそのまま記録なしで読んだページにする
+			book.BaseReadPages = book.ReadPages
+		}
+		if book.ThumbnailID != "" && book.ThumbnailID != u.old {
+			if u.thumbnail == nil || u.thumbnail.ID != book.ThumbnailID {
+				// 確かめてから書くまでに本が変わり、付けていない画像を指すことになった
+				return nil, ErrPreconditionFailed
+			}
+			book.ThumbnailUrl = u.thumbnail.URL
+		}
+		now := time.Now()
+		book.UpdatedAt = now
+		if book.Status == from {
+			return nil, nil
+		}
+		return &entity.StatusChange{
+			Action:    entity.TransitionEdit,
+			From:      from,
+			To:        book.Status,
+			ReadPages: book.ReadPages,
+			ChangedAt: now,
+		}, nil
+	}
+}
+
+// removeReplaced は保存した本で使われなくなった前の画像を消す。
+func (s *BookSvc) removeReplaced(ctx context.Context, u *bookUpdate, book *entity.Book) {
+	if u.old != "" && u.old != book.ThumbnailID {
+		if err := s.thumbnails.Remove(ctx, u.old); err != nil {
+			log.Printf("book: remove old thumbnail %s of book %d: %v", u.old, book.ID, err)
+		}
+	}
+}
+
+// DeleteBook は本をゴミ箱に入れる。読書記録などの子エンティティと表紙画像は、完全に消すまで残す。
+// UpdateBook と同じく、消す時点の version が pre（If-Match）に合わなければ ErrPreconditionFailed を返す。
+func (s *BookSvc) DeleteBook(ctx context.Context, id int, pre *Precondition) error {
+	if pre == nil && s.requireIfMatch {
+		return ErrPreconditionRequired
+	}
+	_, err := s.repo.Trash(ctx, id, time.Now(), checkPrecondition(pre))
+	return err
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// ErrBatchAborted は atomic な一括操作で、ほかの項目が失敗したために保存しなかった項目のエラー。controller で 424 に変換する。
+var ErrBatchAborted = errors.New("not saved because another item in the batch failed")
+
+// BookBatchResult は一括操作の1冊分の結果。Err が nil なら Book に保存した本が入る。
+type BookBatchResult struct {
+	Book *entity.Book
+	Err  error
+}
+
+// CreateBooks は books をまとめて登録する。棚・タグ・表紙画像は CreateBook と同じく1冊ずつ確かめ、通らなかった本は Err に入れて登録しない。
+// checkDuplicate なら登録済みの本から同じらしい本を探し、あれば *DuplicateError にする（バッチの中どうしは比べない）。
+// atomic なら1冊でも失敗すれば1冊も登録せず、ほかの本の Err は ErrBatchAborted にする。
+func (s *BookSvc) CreateBooks(ctx context.Context, books []*entity.Book, checkDuplicate, atomic bool) ([]BookBatchResult, error) {
+	results := make([]BookBatchResult, len(books))
+	var existing []entity.Book
+	if checkDuplicate {
+		var err error
+		if existing, err = s.repo.FindAll(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var valid []*entity.Book
+	var index []int
+	thumbnails := map[string]bool{}
+	for i, book := range books {
+		if checkDuplicate {
+			if candidates := FindDuplicates(book, existing); len(candidates) > 0 {
+				results[i].Err = &DuplicateError{Candidates: candidates}
+				continue
+			}
+		}
+		if book.ThumbnailID != "" && thumbnails[book.ThumbnailID] {
+			// 同じ画像は1冊にしか付けられない
+			results[i].Err = repository.ErrThumbnailInUse
+			continue
+		}
+		if err := s.prepareCreate(ctx, book); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if book.ThumbnailID != "" {
+			thumbnails[book.ThumbnailID] = true
+		}
+		valid = append(valid, book)
+		index = append(index, i)
+	}
+	if atomic && len(valid) < len(books) {
+		return abortBatch(results), nil
+	}
+	if len(valid) > 0 {
+		if err := s.repo.CreateMulti(ctx, valid); err != nil {
+			return nil, err
+		}
+	}
+
+	// 画像を付けられなかった本は CreateBook と同じく取り消す。atomic ならすべて取り消し、付けた画像も本と一緒に消す
+	var rollback []int
+	var attached []*entity.Book
+	for j, book := range valid {
+		if err := s.attachCreated(ctx, book); err != nil {
+			results[index[j]].Err = err
+			rollback = append(rollback, book.ID)
+			continue
+		}
+		results[index[j]].Book = book
+		attached = append(attached, book)
+	}
+	if atomic && len(rollback) > 0 {
+		for _, book := range attached {
+			rollback = append(rollback, book.ID)
+		}
+		results = abortBatch(results)
+	} else {
+		attached = nil
+	}
+	if len(rollback) > 0 {
+		if err := s.repo.DeleteMulti(ctx, rollback); err != nil {
+			log.Printf("book: rollback batch create %v: %v", rollback, err)
+		}
+	}
+	for _, book := range attached {
+		s.removeThumbnail(ctx, book.ID, book.ThumbnailID)
+	}
+	return results, nil
+}
+
+// UpdateBooks は edits をまとめて保存する。1冊ずつの確かめ方は UpdateBook と同じで、通らなかった本は Err に入れて保存しない。
+// 保存は repository の1つのトランザクションで行い、atomic なら1冊でも失敗すれば1冊も保存せず、ほかの本の Err は ErrBatchAborted にする。
+func (s *BookSvc) UpdateBooks(ctx context.Context, edits []BookEdit, atomic bool) ([]BookBatchResult, error) {
+	results := make([]BookBatchResult, len(edits))
+	var updates []*bookUpdate
+	var ids, index []int
+	for i, e := range edits {
+		u := &bookUpdate{BookEdit: e}
+		if err := s.prepareUpdate(ctx, u); err != nil {
+			results[i].Err = err
+			continue
+		}
+		updates = append(updates, u)
+		ids = append(ids, e.ID)
+		index = append(index, i)
+	}
+	if atomic && len(updates) < len(edits) {
+		return abortBatch(results), nil
+	}
+	books, errs, err := s.updateMulti(ctx, ids, atomic, func(j int) repository.BookEditor {
+		return updates[j].editor()
+	})
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range index {
+		results[i].Err = errs[j]
+		if books != nil && books[j] != nil {
+			results[i].Book = books[j]
+			s.removeReplaced(ctx, updates[j], books[j])
+		}
+	}
+	if atomic && books == nil {
+		results = abortBatch(results)
+	}
+	return results, nil
+}
+
+// TrashBooks は edits の本をまとめてゴミ箱に入れる（Edit は使わない）。If-Match の扱いは DeleteBook と同じで、atomic は UpdateBooks と同じ。
+func (s *BookSvc) TrashBooks(ctx context.Context, edits []BookEdit, atomic bool) ([]BookBatchResult, error) {
+	results := make([]BookBatchResult, len(edits))
+	var checks []repository.BookCheck
+	var ids, index []int
+	for i, e := range edits {
+		if e.Pre == nil && s.requireIfMatch {
+			results[i].Err = ErrPreconditionRequired
+			continue
+		}
+		checks = append(checks, checkPrecondition(e.Pre))
+		ids = append(ids, e.ID)
+		index = append(index, i)
+	}
+	if atomic && len(ids) < len(edits) {
+		return abortBatch(results), nil
+	}
+	now := time.Now()
+	books, errs, err := s.updateMulti(ctx, ids, atomic, func(j int) repository.BookEditor {
+		return func(book *entity.Book) (*entity.StatusChange, error) {
+			if err := checks[j](book); err != nil {
+				return nil, err
+			}
+			book.DeletedAt = now
+			return nil, nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range index {
+		results[i].Err = errs[j]
+		if books != nil {
+			results[i].Book = books[j]
+		}
+	}
+	if atomic && books == nil {
+		results = abortBatch(results)
+	}
+	return results, nil
+}
+
+// updateMulti は editor(j) で ids[j] の本を書き換える repository の UpdateMulti を呼ぶ。ids が空なら何もしない。
+func (s *BookSvc) updateMulti(ctx context.Context, ids []int, atomic bool, editor func(j int) repository.BookEditor) ([]*entity.Book, []error, error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	editors := make([]repository.BookEditor, len(ids))
+	for j := range ids {
+		editors[j] = editor(j)
+	}
+	return s.repo.UpdateMulti(ctx, ids, func(j int, book *entity.Book) (*entity.StatusChange, error) {
+		return editors[j](book)
+	}, atomic)
+}
+
+// abortBatch は atomic な一括操作で何も保存しなかったときの結果にする。失敗していない項目は ErrBatchAborted にする。
+func abortBatch(results []BookBatchResult) []BookBatchResult {
+	for i := range results {
+		results[i].Book = nil
+		if results[i].Err == nil {
+			results[i].Err = ErrBatchAborted
+		}
+	}
+	return results
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// TestUpdateBookProgress は、status・readPages を直接書き換えられるのが読書記録と edit 以外の遷移の履歴がない本だけであることを確かめる。
+func TestUpdateBookProgress(t *testing.T) {
+	s := newMemoryBookService()
+	ctx := auth.WithUser(context.Background(), &entity.User{ID: 1})
+
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	newBook := func(t *testing.T) *entity.Book {
+		t.Helper()
+		book, err := s.CreateBook(ctx, &entity.Book{Title: t.Name(), Author: "a", TotalPages: 100, Publisher: "p", Status: entity.StatusUnread, CreatedAt: now, UpdatedAt: now})
+		if err != nil {
+			t.Fatalf("CreateBook: %v", err)
+		}
+		return book
+	}
+	setStatus := func(status entity.Status, readPages int) func(book *entity.Book) error {
+		return func(book *entity.Book) error {
+			book.Status, book.ReadPages = status, readPages
+			return nil
+		}
+	}
+
+	t.Run("book without history", func(t *testing.T) {
+		book := newBook(t)
+		if _, err := s.UpdateBook(ctx, book.ID, nil, setStatus(entity.StatusReading, 10)); err != nil {
+			t.Fatalf("UpdateBook: %v", err)
+		}
+		// edit の遷移の履歴しかなければ、もう一度直せる
+		if _, err := s.UpdateBook(ctx, book.ID, nil, setStatus(entity.StatusUnread, 0)); err != nil {
+			t.Errorf("UpdateBook after an edit: %v", err)
+		}
+	})
+
+	t.Run("book with status history", func(t *testing.T) {
+		book := newBook(t)
+		if _, _, err := s.ChangeStatus(ctx, book.ID, entity.TransitionStart); err != nil {
+			t.Fatalf("ChangeStatus: %v", err)
+		}
+		if _, err := s.UpdateBook(ctx, book.ID, nil, setStatus(entity.StatusUnread, 0)); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("UpdateBook status: err = %v, want ErrInvalidTransition", err)
+		}
+		if _, err := s.UpdateBook(ctx, book.ID, nil, setStatus(entity.StatusReading, 50)); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("UpdateBook readPages: err = %v, want ErrInvalidTransition", err)
+		}
+		edited, err := s.UpdateBook(ctx, book.ID, nil, func(book *entity.Book) error {
+			book.EncounterNote = "note"
+			return nil
+		})
+		if err != nil || edited.EncounterNote != "note" {
+			t.Errorf("UpdateBook other field = %v, %v; want the note saved", edited, err)
+		}
+	})
+
+	t.Run("book with sessions", func(t *testing.T) {
+		book := newBook(t)
+		if _, err := s.AddReadingSession(ctx, book.ID, &entity.ReadingSession{StartPage: 1, EndPage: 10, StartedAt: now, EndedAt: now.Add(time.Hour)}); err != nil {
+			t.Fatalf("AddReadingSession: %v", err)
+		}
+		if _, err := s.UpdateBook(ctx, book.ID, nil, setStatus(entity.StatusUnread, 0)); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("UpdateBook: err = %v, want ErrInvalidTransition", err)
+		}
+	})
+}
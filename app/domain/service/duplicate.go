@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+const (
+	minTitleSimilarity  = 0.85 // タイトルがこれ以上似ていれば同じ本の候補にする
+	minAuthorSimilarity = 0.8  // 著者がどちらにもあるときは、著者もこれ以上似ていること
+	maxDuplicates       = 5    // 返す候補の最大数
+)
+
+// ErrDuplicateBook は登録しようとした本と同じらしい本がすでにあるときに返す。controller で 409 に変換する。
+// 候補は *DuplicateError で取り出す。
+var ErrDuplicateBook = errors.New("the book may already exist")
+
+// ErrMergeSameBook は同じ本どうしをまとめようとしたときに返す。controller で 400 に変換する。
+var ErrMergeSameBook = errors.New("cannot merge a book into itself")
+
+// DuplicateReason は重複の候補にした理由。
+type DuplicateReason string
+
+const (
+	DuplicateByISBN        DuplicateReason = "isbn"         // ISBN が同じ
+	DuplicateByTitleAuthor DuplicateReason = "title_author" // 正規化したタイトル・著者が似ている
+)
+
+// Duplicate は重複の候補の本。Score は 0〜1 で、ISBN が同じなら 1。
+type Duplicate struct {
+	Book   entity.Book
+	Reason DuplicateReason
+	Score  float64
+}
+
+// DuplicateError は重複の候補を持つエラー。errors.Is(err, ErrDuplicateBook) で判定できる。
+type DuplicateError struct {
+	Candidates []Duplicate
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%v: %d candidate(s)", ErrDuplicateBook, len(e.Candidates))
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicateBook
+}
+
+// CheckDuplicate はログイン中のユーザーの本から book と同じらしいものを探し、あれば *DuplicateError を返す。
+func (s *BookSvc) CheckDuplicate(ctx context.Context, book *entity.Book) error {
+	books, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	if candidates := FindDuplicates(book, books); len(candidates) > 0 {
+		return &DuplicateError{Candidates: candidates}
+	}
+	return nil
+}
+
+// FindDuplicates は books の中から book と同じらしい本を、似ている順に maxDuplicates 件まで返す。
+// ISBN が両方にあればそれだけで決め（違えば別の版として候補にしない）、なければ正規化したタイトルと著者の近さで決める。
+func FindDuplicates(book *entity.Book, books []entity.Book) []Duplicate {
+	title := normalizeForMatch(book.Title)
+	author := normalizeForMatch(book.Author)
+	var out []Duplicate
+	for _, b := range books {
+		if book.ID != 0 && b.ID == book.ID {
+			// 自分自身は除く（まだ登録していない本どうしは比べる）
+			continue
+		}
+		if book.ISBN != "" && b.ISBN != "" {
+			if book.ISBN == b.ISBN {
+				out = append(out, Duplicate{Book: b, Reason: DuplicateByISBN, Score: 1})
+			}
+			continue
+		}
+		score := similarity(title, normalizeForMatch(b.Title))
+		if score < minTitleSimilarity {
+			continue
+		}
+		if other := normalizeForMatch(b.Author); len(author) > 0 && len(other) > 0 {
+			a := similarity(author, other)
+			if a < minAuthorSimilarity {
+				continue
+			}
+			score = (score + a) / 2
+		}
+		out = append(out, Duplicate{Book: b, Reason: DuplicateByTitleAuthor, Score: math.Round(score*100) / 100})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > maxDuplicates {
+		out = out[:maxDuplicates]
+	}
+	return out
+}
+
+// normalizeForMatch は全角英数・半角カナを NFKC でそろえ、小文字にし、カタカナをひらがなにして、
+// 文字と数字以外（空白・記号・長音符など）を落とす。検索インデックスの正規化に合わせている。
+func normalizeForMatch(s string) []rune {
+	var out []rune
+	for _, r := range norm.NFKC.String(s) {
+		r = unicode.ToLower(r)
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 'ァ' - 'ぁ'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// similarity は編集距離を長い方の文字数で割って 1 から引いたもの（0〜1）。どちらも空なら 0。
+func similarity(a, b []rune) float64 {
+	n := max(len(a), len(b))
+	if n == 0 {
+		return 0
+	}
+	return 1 - float64(editDistance(a, b))/float64(n)
+}
+
+// editDistance は a と b のレーベンシュタイン距離。
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// MergeBooks は sourceID の本を targetID の本にまとめる。読書記録・status の履歴・レビュー・ハイライトは target に移し、
+// readPages と status は移した後の読書記録から数え直す（mergeReadingProgress）。本の項目は target のものを残し、target で空の項目だけ source から埋め、
+// 棚とタグは両方のものを合わせる。表紙は target になければ source の外部の URL を使い、source にアップロードした画像は消す。
+func (s *BookSvc) MergeBooks(ctx context.Context, targetID, sourceID int) (*entity.Book, error) {
+	if targetID == sourceID {
+		return nil, ErrMergeSameBook
+	}
+	var removed string
+	book, err := s.repo.Merge(ctx, targetID, sourceID, func(target, source *entity.Book, sessions []entity.ReadingSession) (*entity.StatusChange, error) {
+		mergeBook(target, source)
+		removed = source.ThumbnailID
+		return mergeReadingProgress(target, source, sessions, time.Now()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if removed != "" {
+		// source はもう消えているので、画像の削除に失敗してもエラーにはしない
+		if err := s.thumbnails.Remove(ctx, removed); err != nil {
+			log.Printf("book: remove thumbnail %s of merged book %d: %v", removed, sourceID, err)
+		}
+	}
+	return book, nil
+}
+
+// mergeReadingProgress は合わせた読書記録から target の readPages と status を数え直す。
+// readPages はどちらの本の readPages も下回らないよう、多いほうを記録なしで読んだページとして残す。
+// 記録が1つもなければ（completed で登録した本・CSV から取り込んだ本など）target の readPages・status をそのまま使う。
+func mergeReadingProgress(target, source *entity.Book, sessions []entity.ReadingSession, now time.Time) *entity.StatusChange {
+	floor := max(target.ReadPages, source.ReadPages)
+	target.BaseReadPages = max(target.BaseReadPages, source.BaseReadPages, floor)
+	if len(sessions) == 0 && target.ReadPages >= floor {
+		target.UpdatedAt = now
+		return nil
+	}
+	return applyReadingProgress(target, sessions, now)
+}
+
+// mergeBook は target の空の項目を source で埋め、棚・タグを合わせる。
+func mergeBook(target, source *entity.Book) {
+	if target.Publisher == "" {
+		target.Publisher = source.Publisher
+	}
+	if target.ISBN == "" {
+		target.ISBN = source.ISBN
+	}
+	if target.ThumbnailUrl == "" && source.ThumbnailID == "" {
+		target.ThumbnailUrl = source.ThumbnailUrl
+	}
+	if target.TargetCompleteDate.IsZero() {
+		target.TargetCompleteDate = source.TargetCompleteDate
+	}
+	if target.EncounterNote == "" {
+		target.EncounterNote = source.EncounterNote
+	}
+	if target.TargetPagesPerDay == 0 {
+		target.TargetPagesPerDay = source.TargetPagesPerDay
+	}
+	if source.CreatedAt.Before(target.CreatedAt) {
+		target.CreatedAt = source.CreatedAt
+	}
+	target.Shelves = mergeLabels(target.Shelves, source.Shelves)
+	target.Tags = mergeLabels(target.Tags, source.Tags)
+}
+
+func mergeLabels(a, b []string) []string {
+	for _, name := range b {
+		if !slices.Contains(a, name) {
+			a = append(a, name)
+		}
+	}
+	return a
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// TestMergeBooksReadingProgress は、まとめた本の readPages と status が読書記録のない本でも崩れないことを確かめる。
+func TestMergeBooksReadingProgress(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	newBook := func(status entity.Status, readPages int) *entity.Book {
+		return &entity.Book{Title: "t", Author: "a", TotalPages: 300, Publisher: "p", Status: status, ReadPages: readPages, CreatedAt: now, UpdatedAt: now}
+	}
+	tests := []struct {
+		name          string
+		target        *entity.Book
+		source        *entity.Book
+		session       *entity.ReadingSession // source に付ける読書記録
+		legacy        bool                   // baseReadPages を持たない（入れる前に保存した）本にする
+		wantReadPages int
+		wantStatus    entity.Status
+		wantHistory   int // target の status の履歴の数
+	}{
+		{
+			name:          "two completed books without sessions",
+			target:        newBook(entity.StatusCompleted, 300),
+			source:        newBook(entity.StatusCompleted, 300),
+			wantReadPages: 300,
+			wantStatus:    entity.StatusCompleted,
+		},
+		{
+			name:          "two completed books saved before baseReadPages",
+			target:        newBook(entity.StatusCompleted, 300),
+			source:        newBook(entity.StatusCompleted, 300),
+			legacy:        true,
+			wantReadPages: 300,
+			wantStatus:    entity.StatusCompleted,
+		},
+		{
+			name:          "target has fewer pages than source",
+			target:        newBook(entity.StatusUnread, 0),
+			source:        newBook(entity.StatusReading, 120),
+			wantReadPages: 120,
+			wantStatus:    entity.StatusReading,
+			wantHistory:   1,
+		},
+		{
+			name:          "sessions do not drop below the readPages of either book",
+			target:        newBook(entity.StatusReading, 150),
+			source:        newBook(entity.StatusUnread, 0),
+			session:       &entity.ReadingSession{StartPage: 1, EndPage: 20, StartedAt: now, EndedAt: now.Add(time.Hour)},
+			wantReadPages: 150,
+			wantStatus:    entity.StatusReading,
+			wantHistory:   1, // source の記録を足したときの unread → reading
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMemoryBookService()
+			ctx := auth.WithUser(context.Background(), &entity.User{ID: 1})
+			create := func(book *entity.Book) *entity.Book {
+				t.Helper()
+				if tt.legacy {
+					if err := s.repo.Create(ctx, book); err != nil {
+						t.Fatalf("Create: %v", err)
+					}
+					return book
+				}
+				book, err := s.CreateBook(ctx, book)
+				if err != nil {
+					t.Fatalf("CreateBook: %v", err)
+				}
+				return book
+			}
+			target, source := create(tt.target), create(tt.source)
+			if tt.session != nil {
+				if _, err := s.AddReadingSession(ctx, source.ID, tt.session); err != nil {
+					t.Fatalf("AddReadingSession: %v", err)
+				}
+			}
+			merged, err := s.MergeBooks(ctx, target.ID, source.ID)
+			if err != nil {
+				t.Fatalf("MergeBooks: %v", err)
+			}
+			if merged.ReadPages != tt.wantReadPages || merged.Status != tt.wantStatus {
+				t.Errorf("MergeBooks = {ReadPages: %d, Status: %s}, want {%d, %s}", merged.ReadPages, merged.Status, tt.wantReadPages, tt.wantStatus)
+			}
+			history, err := s.statusRepo.FindByBookID(ctx, target.ID)
+			if err != nil {
+				t.Fatalf("FindByBookID: %v", err)
+			}
+			if len(history) != tt.wantHistory {
+				t.Errorf("status history = %+v, want %d changes", history, tt.wantHistory)
+			}
+		})
+	}
+}
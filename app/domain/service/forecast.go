@@ -0,0 +1,150 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ForecastStatus は目標日に間に合いそうかどうか。
+type ForecastStatus string
+
+const (
+	ForecastCompleted  ForecastStatus = "completed"   // 読み終わっている
+	ForecastOnTrack    ForecastStatus = "on_track"    // 今のペース（記録がなければ目標ペース）で目標日までに読み終わる
+	ForecastBehind     ForecastStatus = "behind"      // 今のペースでは目標日に間に合わない
+	ForecastOverdue    ForecastStatus = "overdue"     // 目標日を過ぎている
+	ForecastNotStarted ForecastStatus = "not_started" // 記録も目標ページ数/日もなく見込みが立たない
+)
+
+// Forecast は読了見込みの計算結果。日付はすべて暦日で、NormalizedDate と同じくその日の 00:00:00Z で持つ。
+type Forecast struct {
+	Status              ForecastStatus
+	Today               time.Time
+	TargetCompleteDate  time.Time
+	RemainingPages      int
+	DaysLeft            int // 今日を含めた目標日までの日数。過ぎていれば 0
+	RequiredPagesPerDay int // 目標日に間に合わせるのに必要な1日あたりのページ数
+	CurrentPagesPerDay  float64
+	TargetPagesPerDay   int
+	// 見込みの読了日。ペースが分からないときは nil
+	ProjectedFinishAtCurrentPace *time.Time
+	ProjectedFinishAtTargetPace  *time.Time
+	Plan                         []PlanDay
+}
+
+// PlanDay は日ごとの読書計画。EndPage はその日に読み終える位置。
+type PlanDay struct {
+	Date    time.Time
+	Pages   int
+	EndPage int
+}
+
+// ForecastSvc は読了見込みを計算するドメインサービス。永続化に依存しない純粋な計算だけを行う。
+type ForecastSvc struct{}
+
+func NewForecastService() *ForecastSvc {
+	return &ForecastSvc{}
+}
+
+// Forecast は book と読書記録から、loc のタイムゾーンで見た今日（now）を起点に読了見込みを計算する。
+// targetCompleteDate は UTC に正規化された暦日として扱い、loc の今日の暦日と比べる。
+func (s *ForecastSvc) Forecast(book entity.Book, sessions []entity.ReadingSession, now time.Time, loc *time.Location) *Forecast {
+	today := civilDate(now, loc)
+	target := civilDate(book.TargetCompleteDate, time.UTC)
+	f := &Forecast{
+		Today:              today,
+		TargetCompleteDate: target,
+		RemainingPages:     max(book.TotalPages-book.ReadPages, 0),
+		TargetPagesPerDay:  book.TargetPagesPerDay,
+	}
+	if d := daysBetween(today, target) + 1; d > 0 {
+		f.DaysLeft = d
+	}
+	if f.RemainingPages == 0 {
+		f.Status = ForecastCompleted
+		return f
+	}
+	if f.DaysLeft > 0 {
+		f.RequiredPagesPerDay = ceilDiv(f.RemainingPages, f.DaysLeft)
+	}
+
+	// 今のペース = 再読開始以降の最初の記録の日から今日までの1日あたりの readPages
+	if start, ok := firstSessionDate(sessions, book.ReadingRestartedAt, loc); ok && book.ReadPages > 0 {
+		days := max(daysBetween(start, today)+1, 1)
+		f.CurrentPagesPerDay = float64(book.ReadPages) / float64(days)
+		finish := addDays(today, int(math.Ceil(float64(f.RemainingPages)/f.CurrentPagesPerDay))-1)
+		f.ProjectedFinishAtCurrentPace = &finish
+	}
+	if book.TargetPagesPerDay > 0 {
+		finish := addDays(today, ceilDiv(f.RemainingPages, book.TargetPagesPerDay)-1)
+		f.ProjectedFinishAtTargetPace = &finish
+	}
+
+	projected := f.ProjectedFinishAtCurrentPace
+	if projected == nil {
+		projected = f.ProjectedFinishAtTargetPace
+	}
+	switch {
+	case f.DaysLeft == 0:
+		f.Status = ForecastOverdue
+	case projected == nil:
+		f.Status = ForecastNotStarted
+	case projected.After(target):
+		f.Status = ForecastBehind
+	default:
+		f.Status = ForecastOnTrack
+	}
+
+	if f.DaysLeft > 0 {
+		f.Plan = plan(today, book.ReadPages, book.TotalPages, max(f.RequiredPagesPerDay, book.TargetPagesPerDay))
+	}
+	return f
+}
+
+// plan は今日から pagesPerDay ずつ読み進めて読了するまでの日ごとの計画。
+func plan(today time.Time, readPages, totalPages, pagesPerDay int) []PlanDay {
+	var days []PlanDay
+	for page, i := readPages, 0; page < totalPages; i++ {
+		pages := min(pagesPerDay, totalPages-page)
+		page += pages
+		days = append(days, PlanDay{Date: addDays(today, i), Pages: pages, EndPage: page})
+	}
+	return days
+}
+
+func firstSessionDate(sessions []entity.ReadingSession, since time.Time, loc *time.Location) (time.Time, bool) {
+	var first time.Time
+	for _, s := range sessions {
+		if s.StartedAt.Before(since) {
+			continue
+		}
+		if first.IsZero() || s.StartedAt.Before(first) {
+			first = s.StartedAt
+		}
+	}
+	if first.IsZero() {
+		return time.Time{}, false
+	}
+	return civilDate(first, loc), true
+}
+
+// civilDate は t を loc で見たときの暦日を 00:00:00Z で返す。
+func civilDate(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween は civilDate 同士の日数差（b - a）。
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func addDays(t time.Time, days int) time.Time {
+	return t.AddDate(0, 0, days)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
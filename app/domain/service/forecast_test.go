@@ -0,0 +1,201 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // テストを動かす環境に tzdata がなくても ?tz= と同じ IANA 名を読めるようにする
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestForecast(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	// 6/6 から 6/10 の5日で 50 ページ = 10 ページ/日。残り 50 ページは 6/14 に読み終わる
+	sessions := []entity.ReadingSession{{StartedAt: time.Date(2024, 6, 6, 9, 0, 0, 0, time.UTC)}}
+	newBook := func(readPages, targetPagesPerDay int, target time.Time) entity.Book {
+		return entity.Book{TotalPages: 100, ReadPages: readPages, TargetPagesPerDay: targetPagesPerDay, TargetCompleteDate: target}
+	}
+	tests := []struct {
+		name     string
+		book     entity.Book
+		sessions []entity.ReadingSession
+		want     ForecastStatus
+		daysLeft int
+		required int
+		current  float64
+		finish   time.Time // 今のペースでの読了見込み。ゼロ値なら nil
+		plan     int       // 計画の日数
+	}{
+		{
+			name:     "on track",
+			book:     newBook(50, 0, date(2024, 6, 20)),
+			sessions: sessions,
+			want:     ForecastOnTrack,
+			daysLeft: 11,
+			required: 5,
+			current:  10,
+			finish:   date(2024, 6, 14),
+			plan:     10,
+		},
+		{
+			name:     "finishes on the target date",
+			book:     newBook(50, 0, date(2024, 6, 14)),
+			sessions: sessions,
+			want:     ForecastOnTrack,
+			daysLeft: 5,
+			required: 10,
+			current:  10,
+			finish:   date(2024, 6, 14),
+			plan:     5,
+		},
+		{
+			name:     "behind",
+			book:     newBook(50, 0, date(2024, 6, 12)),
+			sessions: sessions,
+			want:     ForecastBehind,
+			daysLeft: 3,
+			required: 17,
+			current:  10,
+			finish:   date(2024, 6, 14),
+			plan:     3,
+		},
+		{
+			name:     "past target date",
+			book:     newBook(50, 0, date(2024, 6, 9)),
+			sessions: sessions,
+			want:     ForecastOverdue,
+			current:  10,
+			finish:   date(2024, 6, 14),
+		},
+		{
+			name:     "target date is today",
+			book:     newBook(50, 0, date(2024, 6, 10)),
+			sessions: sessions,
+			want:     ForecastBehind,
+			daysLeft: 1,
+			required: 50,
+			current:  10,
+			finish:   date(2024, 6, 14),
+			plan:     1,
+		},
+		{
+			name:     "zero pages per day without sessions",
+			book:     newBook(0, 0, date(2024, 6, 20)),
+			want:     ForecastNotStarted,
+			daysLeft: 11,
+			required: 10,
+			plan:     10,
+		},
+		{
+			name:     "zero pages read with a session",
+			book:     newBook(0, 0, date(2024, 6, 20)),
+			sessions: sessions,
+			want:     ForecastNotStarted,
+			daysLeft: 11,
+			required: 10,
+			plan:     10,
+		},
+		{
+			name:     "target pace without sessions",
+			book:     newBook(0, 20, date(2024, 6, 20)),
+			want:     ForecastOnTrack,
+			daysLeft: 11,
+			required: 10,
+			plan:     5,
+		},
+		{
+			name:     "completed",
+			book:     newBook(100, 0, date(2024, 6, 1)),
+			sessions: sessions,
+			want:     ForecastCompleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewForecastService().Forecast(tt.book, tt.sessions, now, time.UTC)
+			if f.Status != tt.want {
+				t.Errorf("Status = %s, want %s", f.Status, tt.want)
+			}
+			if f.DaysLeft != tt.daysLeft {
+				t.Errorf("DaysLeft = %d, want %d", f.DaysLeft, tt.daysLeft)
+			}
+			if f.RequiredPagesPerDay != tt.required {
+				t.Errorf("RequiredPagesPerDay = %d, want %d", f.RequiredPagesPerDay, tt.required)
+			}
+			if f.CurrentPagesPerDay != tt.current {
+				t.Errorf("CurrentPagesPerDay = %v, want %v", f.CurrentPagesPerDay, tt.current)
+			}
+			switch got := f.ProjectedFinishAtCurrentPace; {
+			case tt.finish.IsZero() && got != nil:
+				t.Errorf("ProjectedFinishAtCurrentPace = %v, want nil", got)
+			case !tt.finish.IsZero() && (got == nil || !got.Equal(tt.finish)):
+				t.Errorf("ProjectedFinishAtCurrentPace = %v, want %v", got, tt.finish)
+			}
+			if len(f.Plan) != tt.plan {
+				t.Fatalf("len(Plan) = %d, want %d", len(f.Plan), tt.plan)
+			}
+			if tt.plan > 0 {
+				first, last := f.Plan[0], f.Plan[len(f.Plan)-1]
+				if !first.Date.Equal(date(2024, 6, 10)) {
+					t.Errorf("Plan[0].Date = %v, want 2024-06-10", first.Date)
+				}
+				if last.EndPage != tt.book.TotalPages {
+					t.Errorf("last Plan EndPage = %d, want %d", last.EndPage, tt.book.TotalPages)
+				}
+			}
+		})
+	}
+}
+
+// TestForecastTimeZone は ?tz= の日付が UTC と変わる時刻で、今日と目標日までの日数が tz の暦日で決まることを確かめる。
+func TestForecastTimeZone(t *testing.T) {
+	// UTC では 6/10 の 20:00、東京では 6/11 の 5:00、ロサンゼルスでは 6/10 の 13:00
+	now := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	// UTC の 6/10 23:30 に始めた記録は、東京では 6/11 の記録
+	lateSession := []entity.ReadingSession{{StartedAt: time.Date(2024, 6, 10, 23, 30, 0, 0, time.UTC)}}
+	book := entity.Book{TotalPages: 100, ReadPages: 20, TargetCompleteDate: date(2024, 6, 11)}
+	tests := []struct {
+		tz       string
+		now      time.Time
+		sessions []entity.ReadingSession
+		today    time.Time
+		daysLeft int
+		want     ForecastStatus
+	}{
+		{tz: "UTC", now: now, today: date(2024, 6, 10), daysLeft: 2, want: ForecastNotStarted},
+		{tz: "Asia/Tokyo", now: now, today: date(2024, 6, 11), daysLeft: 1, want: ForecastNotStarted},
+		{tz: "America/Los_Angeles", now: now, today: date(2024, 6, 10), daysLeft: 2, want: ForecastNotStarted},
+		// 東京の 6/12 0:30 は UTC ではまだ 6/11 なので、UTC なら目標日の当日、東京なら過ぎている
+		{tz: "UTC", now: time.Date(2024, 6, 11, 15, 30, 0, 0, time.UTC), today: date(2024, 6, 11), daysLeft: 1, want: ForecastNotStarted},
+		{tz: "Asia/Tokyo", now: time.Date(2024, 6, 11, 15, 30, 0, 0, time.UTC), today: date(2024, 6, 12), daysLeft: 0, want: ForecastOverdue},
+		// 記録の日も tz の暦日で数える: 東京では今日（6/11）の1日で 20 ページ読んだので、残り 80 ページは 6/14 になる
+		{tz: "Asia/Tokyo", now: time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), sessions: lateSession, today: date(2024, 6, 11), daysLeft: 1, want: ForecastBehind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tz+" at "+tt.now.Format(time.RFC3339), func(t *testing.T) {
+			f := NewForecastService().Forecast(book, tt.sessions, tt.now, mustLoadLocation(t, tt.tz))
+			if !f.Today.Equal(tt.today) {
+				t.Errorf("Today = %v, want %v", f.Today, tt.today)
+			}
+			if f.DaysLeft != tt.daysLeft {
+				t.Errorf("DaysLeft = %d, want %d", f.DaysLeft, tt.daysLeft)
+			}
+			if f.Status != tt.want {
+				t.Errorf("Status = %s, want %s", f.Status, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package service
+
+import "github.com/sora-00/booktracker-api/app/domain/repository"
+
+// newMemoryBookService はインメモリの repository でつないだ BookSvc を返す。表紙画像のファイルは扱わない。
+func newMemoryBookService() *BookSvc {
+	books := repository.NewMemoryBookRepo()
+	labels := NewLabelService(repository.NewMemoryShelfRepo(books), repository.NewMemoryTagRepo(books))
+	thumbnails := NewThumbnailService(repository.NewMemoryThumbnailRepo(), nil)
+	return NewService(books, repository.NewMemoryReadingSessionRepo(books), repository.NewMemoryStatusChangeRepo(books), thumbnails, labels, false)
+}
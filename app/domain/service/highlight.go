@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// ErrHighlightPageOutOfRange はハイライトのページが本の totalPages を超えているときに返す。controller で 400 に変換する。
+var ErrHighlightPageOutOfRange = errors.New("page must not exceed totalPages")
+
+// HighlightSvc は本のハイライト（引用）を扱う。
+type HighlightSvc struct {
+	repo repository.HighlightRepo
+}
+
+func NewHighlightService(repo repository.HighlightRepo) *HighlightSvc {
+	return &HighlightSvc{repo: repo}
+}
+
+// AddHighlight はハイライトを追加する。ページは本の totalPages まで。
+func (s *HighlightSvc) AddHighlight(ctx context.Context, bookID int, highlight *entity.Highlight) error {
+	return s.repo.Create(ctx, bookID, highlight, checkHighlightPage(highlight.Page))
+}
+
+// UpdateHighlight は変更済みのハイライトを保存する。
+func (s *HighlightSvc) UpdateHighlight(ctx context.Context, highlight *entity.Highlight) error {
+	highlight.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, highlight, checkHighlightPage(highlight.Page))
+}
+
+func checkHighlightPage(page int) repository.BookCheck {
+	return func(book *entity.Book) error {
+		if page > book.TotalPages {
+			return ErrHighlightPageOutOfRange
+		}
+		return nil
+	}
+}
+
+// DailyHighlight は date（利用者のタイムゾーンでの日付の 00:00:00Z）に見返すハイライトを1件選ぶ。なければ nil。
+// ハイライトをユーザーごとに決まった順にシャッフルし、1日1件ずつ順に出すので、
+// ハイライトが増減しない限り全件を一巡するまで同じものは出ない。同じ日なら何度呼んでも同じものを返す。
+func (s *HighlightSvc) DailyHighlight(ctx context.Context, userID int, date time.Time) (*entity.Highlight, error) {
+	highlights, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(highlights) == 0 {
+		return nil, nil
+	}
+	n := len(highlights)
+	day := int(date.Unix() / (24 * 60 * 60))
+	cycle, pos := day/n, day%n
+	perm := rand.New(rand.NewPCG(uint64(userID), uint64(cycle))).Perm(n)
+	return &highlights[perm[pos]], nil
+}
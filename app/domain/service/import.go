@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+const (
+	importChunkSize    = 100              // CreateBooks に1回で渡し、結果をまとめて保存する行数
+	importJobRetention = 24 * time.Hour   // 終わった取り込みの結果を見られる期間
+	importJobStale     = 10 * time.Minute // running のまま進み具合がこれより長く書かれなければ、止まったものとして扱う
+)
+
+// ImportItem は CSV の1行から作った登録する本。Err があれば入力の不備で登録しない。
+// FinishedOn があり本が completed なら、読み終えた日として status の履歴に残す。Review があればレビューとして書く。
+type ImportItem struct {
+	Row        int
+	Book       *entity.Book
+	FinishedOn time.Time
+	Review     *entity.Review
+	Err        error
+}
+
+// ImportSvc は CSV の取り込みを非同期に進める。進み具合と行の結果は塊ごとに ImportJobRepo に保存するので、
+// 取り込みを進めているのと別のインスタンスにも進み具合を聞ける。
+// 取り込みを進めているインスタンスが止まると、その取り込みは importJobStale の後に failed になる（登録した本は残る）。
+type ImportSvc struct {
+	books   *BookSvc
+	reviews *ReviewSvc
+	jobs    repository.ImportJobRepo
+}
+
+func NewImportService(books *BookSvc, reviews *ReviewSvc, jobs repository.ImportJobRepo) *ImportSvc {
+	return &ImportSvc{books: books, reviews: reviews, jobs: jobs}
+}
+
+// StartImport は items の取り込みを始め、終わるのを待たずに返す。取り込みはリクエストが終わっても続くよう、
+// ctx のキャンセルを切り離した context で進める。
+// 登録済みの本と、CSV の前の行で登録した本に同じらしい本があれば *DuplicateError にして登録しない。
+// dryRun なら何も保存せず、登録するはずの本を結果に入れる。登録した本ごとに created を呼ぶ（検索インデックス用）。
+func (s *ImportSvc) StartImport(ctx context.Context, format string, dryRun bool, items []ImportItem, created func(ctx context.Context, book *entity.Book)) (*entity.ImportJob, error) {
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.jobs.DeleteFinishedBefore(ctx, now.Add(-importJobRetention)); err != nil {
+		log.Printf("import: delete old jobs: %v", err)
+	}
+	job := &entity.ImportJob{
+		ID:        id,
+		Format:    format,
+		DryRun:    dryRun,
+		State:     entity.ImportJobRunning,
+		Total:     len(items),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	started := *job
+
+	go s.run(context.WithoutCancel(ctx), job, items, created)
+	return &started, nil
+}
+
+// FindImportJob はログイン中のユーザーの取り込みの今の状態を返す。
+func (s *ImportSvc) FindImportJob(ctx context.Context, id string) (*entity.ImportJob, error) {
+	job, err := s.jobs.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch {
+	case job.State != entity.ImportJobRunning && now.Sub(job.FinishedAt) > importJobRetention:
+		return nil, repository.ErrImportJobNotFound
+	case job.State == entity.ImportJobRunning && now.Sub(job.UpdatedAt) > importJobStale:
+		job.State = entity.ImportJobFailed
+		job.Error = "import stopped before finishing (the server may have restarted)"
+		job.FinishedAt = job.UpdatedAt
+	}
+	return job, nil
+}
+
+func (s *ImportSvc) run(ctx context.Context, job *entity.ImportJob, items []ImportItem, created func(ctx context.Context, book *entity.Book)) {
+	err := s.importRows(ctx, job, items, created)
+	job.FinishedAt = time.Now()
+	job.UpdatedAt = job.FinishedAt
+	job.State = entity.ImportJobSucceeded
+	if err != nil {
+		log.Printf("import %s: %v", job.ID, err)
+		job.State, job.Error = entity.ImportJobFailed, err.Error()
+	}
+	if err := s.jobs.Update(ctx, job, nil); err != nil {
+		log.Printf("import %s: save state: %v", job.ID, err)
+	}
+}
+
+// importRows は items を importChunkSize 行ずつ CreateBooks で登録し、そのたびに行の結果と job の進み具合を保存する。
+func (s *ImportSvc) importRows(ctx context.Context, job *entity.ImportJob, items []ImportItem, created func(ctx context.Context, book *entity.Book)) error {
+	existing, err := s.books.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(items); start += importChunkSize {
+		chunk := items[start:min(start+importChunkSize, len(items))]
+		results := make([]importRowResult, len(chunk))
+		var pending []*entity.Book
+		var index, deferred []int
+		for i, item := range chunk {
+			results[i].Row = item.Row
+			if item.Err != nil {
+				results[i].Err = item.Err
+				continue
+			}
+			if candidates := FindDuplicates(item.Book, existing); len(candidates) > 0 {
+				results[i].Err = &DuplicateError{Candidates: candidates}
+				continue
+			}
+			if candidates := FindDuplicates(item.Book, bookValues(pending)); len(candidates) > 0 {
+				// 同じ塊の前の行と同じらしい。候補の ID は登録してから決まるので、あとで探し直す
+				results[i].Err = &DuplicateError{Candidates: candidates}
+				deferred = append(deferred, i)
+				continue
+			}
+			pending = append(pending, item.Book)
+			index = append(index, i)
+		}
+		if err := s.createRows(ctx, job.DryRun, chunk, results, index, created); err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Err == nil && r.Book != nil {
+				existing = append(existing, *r.Book)
+			}
+		}
+		// 前の行が登録できなかったなら、その行と同じらしいだけの行は登録し直す。登録し直す行どうしが同じらしければ、先の行を登録してから探し直す
+		for len(deferred) > 0 {
+			var retry, next []int
+			var retryBooks []*entity.Book
+			for _, i := range deferred {
+				if candidates := FindDuplicates(chunk[i].Book, existing); len(candidates) > 0 {
+					results[i].Err = &DuplicateError{Candidates: candidates}
+					continue
+				}
+				if len(FindDuplicates(chunk[i].Book, bookValues(retryBooks))) > 0 {
+					next = append(next, i)
+					continue
+				}
+				results[i].Err = nil
+				retry, retryBooks = append(retry, i), append(retryBooks, chunk[i].Book)
+			}
+			if err := s.createRows(ctx, job.DryRun, chunk, results, retry, created); err != nil {
+				return err
+			}
+			for _, i := range retry {
+				if results[i].Err == nil {
+					existing = append(existing, *results[i].Book)
+				}
+			}
+			deferred = next
+		}
+
+		rows := make([]entity.ImportRow, len(results))
+		for i, r := range results {
+			rows[i] = entity.ImportRow{Row: r.Row, Book: r.Book, Error: importRowError(r.Err)}
+		}
+		job.Processed += len(chunk)
+		job.UpdatedAt = time.Now()
+		if err := s.jobs.Update(ctx, job, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRows は chunk の index の行を CreateBooks でまとめて登録し、results に結果を入れる。dryRun なら登録するはずの本を入れる。
+func (s *ImportSvc) createRows(ctx context.Context, dryRun bool, chunk []ImportItem, results []importRowResult, index []int, created func(ctx context.Context, book *entity.Book)) error {
+	if len(index) == 0 {
+		return nil
+	}
+	books := make([]*entity.Book, len(index))
+	for j, i := range index {
+		books[j] = chunk[i].Book
+	}
+	if dryRun {
+		for j, i := range index {
+			results[i].Book = books[j]
+		}
+		return nil
+	}
+	saved, err := s.books.CreateBooks(ctx, books, false, false)
+	if err != nil {
+		return err
+	}
+	for j, r := range saved {
+		i := index[j]
+		results[i].Err = r.Err
+		if r.Err != nil {
+			continue
+		}
+		results[i].Book = s.importHistory(ctx, r.Book, chunk[i])
+		created(ctx, results[i].Book)
+	}
+	return nil
+}
+
+// importRowResult は処理中の1行分の結果。Err が nil なら Book に登録した本（dryRun なら登録するはずの本）が入る。
+type importRowResult struct {
+	Row  int
+	Book *entity.Book
+	Err  error
+}
+
+// importRowKinds は行の登録で返ることのある決まったエラー。保存した行の結果から ImportRowErr で元に戻せるよう、Kind に文言を残す。
+var importRowKinds = []error{ErrUnknownShelf, ErrThumbnailNotFound, repository.ErrThumbnailInUse}
+
+// importRowError は行のエラーを保存できる形にする。入力の不備は usecase で *entity.ImportRowError にしてある。
+func importRowError(err error) *entity.ImportRowError {
+	if err == nil {
+		return nil
+	}
+	var rowErr *entity.ImportRowError
+	if errors.As(err, &rowErr) {
+		return rowErr
+	}
+	e := &entity.ImportRowError{Message: err.Error()}
+	var dup *DuplicateError
+	if errors.As(err, &dup) {
+		for _, c := range dup.Candidates {
+			e.Candidates = append(e.Candidates, entity.ImportRowCandidate{Book: c.Book, Reason: string(c.Reason), Score: c.Score})
+		}
+		return e
+	}
+	for _, kind := range importRowKinds {
+		if errors.Is(err, kind) {
+			e.Kind = kind.Error()
+			break
+		}
+	}
+	return e
+}
+
+// ImportRowErr は保存した行のエラーを、本を1冊ずつ登録したときと同じ error に戻す。入力の不備（Fields）は usecase で戻す。
+func ImportRowErr(e *entity.ImportRowError) error {
+	if len(e.Candidates) > 0 {
+		dup := &DuplicateError{}
+		for _, c := range e.Candidates {
+			dup.Candidates = append(dup.Candidates, Duplicate{Book: c.Book, Reason: DuplicateReason(c.Reason), Score: c.Score})
+		}
+		return dup
+	}
+	for _, kind := range importRowKinds {
+		if e.Kind == kind.Error() {
+			return &importKindError{message: e.Message, kind: kind}
+		}
+	}
+	return errors.New(e.Message)
+}
+
+// importKindError は保存した文言のまま、errors.Is で決まったエラーと判定できるようにする。
+type importKindError struct {
+	message string
+	kind    error
+}
+
+func (e *importKindError) Error() string {
+	return e.message
+}
+
+func (e *importKindError) Unwrap() error {
+	return e.kind
+}
+
+// importHistory は登録した本の読み終えた日を status の履歴に、評価をレビューに残す。
+// 失敗しても本は登録できているので、ログに出すだけにする。
+func (s *ImportSvc) importHistory(ctx context.Context, book *entity.Book, item ImportItem) *entity.Book {
+	if book.Status == entity.StatusCompleted && !item.FinishedOn.IsZero() {
+		updated, _, err := s.books.statusRepo.Transition(ctx, book.ID, func(book *entity.Book) (*entity.StatusChange, error) {
+			return &entity.StatusChange{
+				Action:    entity.TransitionImport,
+				From:      entity.StatusUnread,
+				To:        entity.StatusCompleted,
+				ReadPages: book.ReadPages,
+				ChangedAt: item.FinishedOn,
+			}, nil
+		})
+		if err != nil {
+			log.Printf("import: record finished date of book %d: %v", book.ID, err)
+		} else {
+			book = updated
+		}
+	}
+	if item.Review != nil {
+		if err := s.reviews.PutReview(ctx, book.ID, item.Review, true); err != nil {
+			log.Printf("import: put review of book %d: %v", book.ID, err)
+		}
+	}
+	return book
+}
+
+func bookValues(books []*entity.Book) []entity.Book {
+	out := make([]entity.Book, len(books))
+	for i, book := range books {
+		out[i] = *book
+	}
+	return out
+}
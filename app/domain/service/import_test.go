@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// TestImportRowsRetriesDeferredDuplicates は、同じ塊の前の行と同じらしいので後回しにした行が、
+// 前の行が登録できなかったときに登録し直されることを確かめる。
+func TestImportRowsRetriesDeferredDuplicates(t *testing.T) {
+	bookSvc := newMemoryBookService()
+	jobs := repository.NewMemoryImportJobRepo()
+	s := NewImportService(bookSvc, NewReviewService(repository.NewMemoryReviewRepo(bookSvc.repo), bookSvc.statusRepo), jobs)
+	ctx := auth.WithUser(context.Background(), &entity.User{ID: 1})
+
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	newItem := func(row int, shelves ...string) ImportItem {
+		return ImportItem{Row: row, Book: &entity.Book{
+			Title: "Dune", Author: "Frank Herbert", TotalPages: 604, Publisher: "Ace",
+			Status: entity.StatusUnread, Shelves: shelves, CreatedAt: now, UpdatedAt: now,
+		}}
+	}
+	// 2行目はない棚を指定していて登録できない。3行目は2行目と同じらしいが登録し直し、4行目は3行目と同じらしいので登録しない
+	items := []ImportItem{newItem(2, "missing"), newItem(3), newItem(4)}
+	job := &entity.ImportJob{ID: "job", State: entity.ImportJobRunning, Total: len(items)}
+	if err := jobs.Create(ctx, job); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := s.importRows(ctx, job, items, func(context.Context, *entity.Book) {}); err != nil {
+		t.Fatalf("importRows: %v", err)
+	}
+	got, err := jobs.FindByID(ctx, "job")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if len(got.Rows) != 3 {
+		t.Fatalf("len(Rows) = %d, want 3", len(got.Rows))
+	}
+
+	if err := ImportRowErr(got.Rows[0].Error); !errors.Is(err, ErrUnknownShelf) {
+		t.Errorf("row 2: err = %v, want ErrUnknownShelf", err)
+	}
+	saved := got.Rows[1]
+	if saved.Error != nil || saved.Book == nil || saved.Book.ID == 0 {
+		t.Fatalf("row 3 = {Book: %+v, Error: %+v}, want a saved book", saved.Book, saved.Error)
+	}
+	dup := got.Rows[2].Error
+	if dup == nil || len(dup.Candidates) != 1 || dup.Candidates[0].Book.ID != saved.Book.ID {
+		t.Errorf("row 4: error = %+v, want a duplicate of book %d", dup, saved.Book.ID)
+	}
+	all, err := bookSvc.repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(all) != 1 {
+		t.Errorf("saved %d books, want 1", len(all))
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// ErrUnknownShelf は本を存在しない棚に入れようとしたときに返す。controller で 400 に変換する。
+var ErrUnknownShelf = errors.New("shelf does not exist")
+
+// LabelSvc は本に付ける棚・タグを扱う。棚は先に作っておいたものだけ、タグは自由に付けられる。
+type LabelSvc struct {
+	shelves repository.ShelfRepo
+	tags    repository.TagRepo
+}
+
+func NewLabelService(shelves repository.ShelfRepo, tags repository.TagRepo) *LabelSvc {
+	return &LabelSvc{shelves: shelves, tags: tags}
+}
+
+// labelBook は本の棚がすべて存在するかを確かめ、まだないタグを作る。
+func (s *LabelSvc) labelBook(ctx context.Context, book *entity.Book) error {
+	if len(book.Shelves) > 0 {
+		shelves, err := s.shelves.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		names := map[string]bool{}
+		for _, shelf := range shelves {
+			names[shelf.Name] = true
+		}
+		for _, name := range book.Shelves {
+			if !names[name] {
+				return fmt.Errorf("%w: %q", ErrUnknownShelf, name)
+			}
+		}
+	}
+	return s.tags.Ensure(ctx, book.Tags)
+}
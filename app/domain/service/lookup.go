@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/infra/lookup"
+)
+
+// 取り込む表紙画像の最大サイズ（アップロードと同じ 10MB）
+const maxCoverSize = 10 << 20
+
+// errCoverTooLarge は取り込もうとした表紙画像が maxCoverSize を超えていたときのエラー。
+var errCoverTooLarge = errors.New("cover image is larger than 10MB")
+
+// ISBNLookup は ISBN から引いた書誌情報。表紙を取り込んだときは Thumbnail に記録した画像が入る。
+// 表紙の取り込みに失敗しても書誌情報は返し、CoverError に理由を入れる。
+type ISBNLookup struct {
+	Metadata   *lookup.Metadata
+	Thumbnail  *entity.Thumbnail
+	CoverError error
+}
+
+// LookupSvc は ISBN から本の書誌情報を引き、必要なら表紙画像を ThumbnailStore に取り込む。
+type LookupSvc struct {
+	provider   lookup.MetadataProvider
+	thumbnails *ThumbnailSvc
+	client     *http.Client
+}
+
+func NewLookupService(provider lookup.MetadataProvider, thumbnails *ThumbnailSvc) *LookupSvc {
+	return &LookupSvc{provider: provider, thumbnails: thumbnails, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// LookupISBN は isbn（ISBN-13）の書誌情報を引く。cover が true で表紙の URL があれば、
+// 画像をダウンロードしてアップロードと同じように保存し、どの本にも付いていない画像として記録する。
+func (s *LookupSvc) LookupISBN(ctx context.Context, isbn string, cover bool) (*ISBNLookup, error) {
+	m, err := s.provider.Lookup(ctx, isbn)
+	if err != nil {
+		return nil, err
+	}
+	res := &ISBNLookup{Metadata: m}
+	if cover && m.CoverURL != "" {
+		res.Thumbnail, res.CoverError = s.importCover(ctx, m.CoverURL)
+	}
+	return res, nil
+}
+
+func (s *LookupSvc) importCover(ctx context.Context, url string) (*entity.Thumbnail, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cover: %s returned %s", req.URL.Host, res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxCoverSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxCoverSize {
+		return nil, errCoverTooLarge
+	}
+	thumbnail, _, err := s.thumbnails.Upload(ctx, bytes.NewReader(data))
+	return thumbnail, err
+}
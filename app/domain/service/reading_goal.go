@@ -0,0 +1,146 @@
+package service
+
+import (
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// GoalStatus は読書目標の進み具合。
+type GoalStatus string
+
+const (
+	GoalNotStarted GoalStatus = "not_started" // 期間がまだ始まっていない
+	GoalAhead      GoalStatus = "ahead"       // 今日までの予定以上に進んでいる
+	GoalBehind     GoalStatus = "behind"      // 今日までの予定に届いていない
+	GoalAchieved   GoalStatus = "achieved"    // 目標に達した
+	GoalMissed     GoalStatus = "missed"      // 期間が終わり、目標に届かなかった
+)
+
+// GoalProgress は読書目標の進み具合の計算結果。日付はすべて暦日で、その日の 00:00:00Z で持つ。
+type GoalProgress struct {
+	Status         GoalStatus
+	Today          time.Time
+	Target         int
+	Actual         int     // 期間の初めから今日まで（期間が終わっていれば最終日まで）の実績
+	ExpectedToday  float64 // 予定どおりなら今日までに達しているはずの値
+	DaysTotal      int
+	DaysLeft       int // 今日を含めた期間の残り日数。過ぎていれば 0
+	RequiredPerDay int // 残りの日数で目標に届くのに必要な1日あたりの量
+	Curve          []GoalCurvePoint
+}
+
+// GoalCurvePoint は期間の日ごとの予定と実績（どちらも期間の初めからの累計）。
+// Actual は今日より後の日は nil。
+type GoalCurvePoint struct {
+	Date     time.Time
+	Expected float64
+	Actual   *int
+}
+
+// GoalSvc は読書目標の進み具合を計算するドメインサービス。永続化に依存しない純粋な計算だけを行う。
+type GoalSvc struct{}
+
+func NewGoalService() *GoalSvc {
+	return &GoalSvc{}
+}
+
+// Progress は goal の期間の実績を、loc のタイムゾーンで見た暦日ごとに数える。
+// books は status の履歴から completed になった日で数え、その後の読書記録の削除で completed でなくなったものは数えない。
+// pages は読書記録のページ数を記録の終了日で数える。changes は本ごとに changedAt 順に並んでいること。
+func (s *GoalSvc) Progress(goal entity.ReadingGoal, changes []entity.StatusChange, sessions []entity.ReadingSession, now time.Time, loc *time.Location) *GoalProgress {
+	start := civilDate(goal.StartDate, time.UTC)
+	end := civilDate(goal.EndDate, time.UTC)
+	today := civilDate(now, loc)
+	p := &GoalProgress{
+		Today:     today,
+		Target:    goal.Target,
+		DaysTotal: daysBetween(start, end) + 1,
+	}
+
+	daily := make([]int, p.DaysTotal)
+	count := func(t time.Time, n int) {
+		if i := daysBetween(start, civilDate(t, loc)); i >= 0 && i < p.DaysTotal {
+			daily[i] += n
+		}
+	}
+	switch goal.Metric {
+	case entity.GoalBooks:
+		for _, c := range completions(changes) {
+			count(c.ChangedAt, 1)
+		}
+	case entity.GoalPages:
+		for _, session := range sessions {
+			count(session.EndedAt, session.EndPage-session.StartPage+1)
+		}
+	}
+
+	// 今日が期間のどこにあるか（期間の前なら -1、後なら最終日）
+	last := min(daysBetween(start, today), p.DaysTotal-1)
+	cumulative := 0
+	p.Curve = make([]GoalCurvePoint, p.DaysTotal)
+	for i := range daily {
+		p.Curve[i] = GoalCurvePoint{
+			Date:     addDays(start, i),
+			Expected: float64(goal.Target) * float64(i+1) / float64(p.DaysTotal),
+		}
+		if i <= last {
+			cumulative += daily[i]
+			actual := cumulative
+			p.Curve[i].Actual = &actual
+		}
+	}
+	p.Actual = cumulative
+	if last >= 0 {
+		p.ExpectedToday = p.Curve[last].Expected
+	}
+	if d := daysBetween(today, end) + 1; d > 0 {
+		p.DaysLeft = min(d, p.DaysTotal)
+	}
+	remaining := max(goal.Target-p.Actual, 0)
+	if p.DaysLeft > 0 {
+		p.RequiredPerDay = ceilDiv(remaining, p.DaysLeft)
+	}
+
+	switch {
+	case remaining == 0:
+		p.Status = GoalAchieved
+	case last < 0:
+		p.Status = GoalNotStarted
+	case p.DaysLeft == 0:
+		p.Status = GoalMissed
+	case float64(p.Actual) >= p.ExpectedToday:
+		p.Status = GoalAhead
+	default:
+		p.Status = GoalBehind
+	}
+	return p
+}
+
+// completions は本が completed になった遷移の一覧。読書記録の削除（progress の遷移）や PATCH での修正（edit の遷移）で
+// completed から戻ったものは除く。
+func completions(changes []entity.StatusChange) []entity.StatusChange {
+	pending := map[int]int{} // bookID → done の中の添字
+	var done []entity.StatusChange
+	var dropped []bool
+	for _, c := range changes {
+		switch {
+		case c.To == entity.StatusCompleted:
+			pending[c.BookID] = len(done)
+			done = append(done, c)
+			dropped = append(dropped, false)
+		case c.From == entity.StatusCompleted && (c.Action == entity.TransitionProgress || c.Action == entity.TransitionEdit):
+			if i, ok := pending[c.BookID]; ok {
+				dropped[i] = true
+				delete(pending, c.BookID)
+			}
+		}
+	}
+	out := make([]entity.StatusChange, 0, len(done))
+	for i, c := range done {
+		if !dropped[i] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
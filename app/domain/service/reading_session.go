@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ErrSessionPageOutOfRange は読書記録のページが本の totalPages を超えているときに返す。controller で 400 に変換する。
+var ErrSessionPageOutOfRange = errors.New("endPage must not exceed totalPages")
+
+// AddReadingSession は読書記録を追加し、同じトランザクションで Book の readPages と status を再計算する。
+func (s *BookSvc) AddReadingSession(ctx context.Context, bookID int, session *entity.ReadingSession) (*entity.Book, error) {
+	if session == nil {
+		return nil, errors.New("session is required")
+	}
+	return s.sessionRepo.Create(ctx, bookID, session, func(book *entity.Book, sessions []entity.ReadingSession) (*entity.StatusChange, error) {
+		if session.EndPage > book.TotalPages {
+			return nil, ErrSessionPageOutOfRange
+		}
+		return applyReadingProgress(book, sessions, time.Now()), nil
+	})
+}
+
+// DeleteReadingSession は読書記録を削除し、残りの記録から Book の readPages と status を再計算する。
+func (s *BookSvc) DeleteReadingSession(ctx context.Context, bookID, sessionID int) (*entity.Book, error) {
+	return s.sessionRepo.Delete(ctx, bookID, sessionID, func(book *entity.Book, sessions []entity.ReadingSession) (*entity.StatusChange, error) {
+		return applyReadingProgress(book, sessions, time.Now()), nil
+	})
+}
+
+// applyReadingProgress は読書記録から readPages を数え直し、status を unread → reading → completed に合わせる。
+// status が変わったときはその履歴を返す。paused / abandoned は手動の遷移でだけ動かす（0 ページ・読了になったときを除く）。
+func applyReadingProgress(book *entity.Book, sessions []entity.ReadingSession, now time.Time) *entity.StatusChange {
+	book.ReadPages = countReadPages(sessions, book)
+	book.UpdatedAt = now
+
+	next := book.Status
+	switch {
+	case book.Status == entity.StatusAbandoned:
+		// 読むのをやめた本は記録が増減しても status を変えない
+	case book.ReadPages >= book.TotalPages:
+		next = entity.StatusCompleted
+	case book.ReadPages == 0:
+		next = entity.StatusUnread
+	case book.Status != entity.StatusPaused:
+		next = entity.StatusReading
+	}
+	if next == book.Status {
+		return nil
+	}
+	change := &entity.StatusChange{
+		Action:    entity.TransitionProgress,
+		From:      book.Status,
+		To:        next,
+		ReadPages: book.ReadPages,
+		ChangedAt: now,
+	}
+	book.Status = next
+	return change
+}
+
+// countReadPages は再読開始（readingRestartedAt）以降の記録について、同じページを何度読んでも1回として数える
+// （ページ範囲の和集合の大きさ）。記録なしで入力した baseReadPages は 1 ページ目からの範囲として和集合に入れる。
+func countReadPages(sessions []entity.ReadingSession, book *entity.Book) int {
+	ranges := make([][2]int, 0, len(sessions)+1)
+	if base := min(book.BaseReadPages, book.TotalPages); base > 0 {
+		ranges = append(ranges, [2]int{1, base})
+	}
+	for _, s := range sessions {
+		if s.StartedAt.Before(book.ReadingRestartedAt) {
+			continue
+		}
+		start, end := s.StartPage, min(s.EndPage, book.TotalPages)
+		if start < 1 {
+			start = 1
+		}
+		if start <= end {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	pages := 0
+	curStart, curEnd := 0, -1
+	for _, r := range ranges {
+		if r[0] > curEnd+1 {
+			pages += curEnd - curStart + 1
+			curStart, curEnd = r[0], r[1]
+			continue
+		}
+		curEnd = max(curEnd, r[1])
+	}
+	pages += curEnd - curStart + 1
+	return pages
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+func TestCountReadPages(t *testing.T) {
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	session := func(start, end int) entity.ReadingSession {
+		return entity.ReadingSession{StartPage: start, EndPage: end, StartedAt: day}
+	}
+	tests := []struct {
+		name     string
+		base     int
+		restart  time.Time
+		sessions []entity.ReadingSession
+		want     int
+	}{
+		{name: "no sessions", want: 0},
+		{name: "base only", base: 120, want: 120},
+		{name: "overlapping sessions count once", sessions: []entity.ReadingSession{session(1, 20), session(10, 30)}, want: 30},
+		{name: "gap between sessions", sessions: []entity.ReadingSession{session(1, 10), session(21, 30)}, want: 20},
+		{name: "session after base", base: 120, sessions: []entity.ReadingSession{session(121, 140)}, want: 140},
+		{name: "session inside base", base: 120, sessions: []entity.ReadingSession{session(50, 60)}, want: 120},
+		{name: "session apart from base", base: 100, sessions: []entity.ReadingSession{session(151, 160)}, want: 110},
+		{name: "base beyond totalPages", base: 500, want: 300},
+		{name: "sessions before restart are ignored", restart: day.Add(time.Hour), sessions: []entity.ReadingSession{session(1, 50)}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &entity.Book{TotalPages: 300, BaseReadPages: tt.base, ReadingRestartedAt: tt.restart}
+			if got := countReadPages(tt.sessions, book); got != tt.want {
+				t.Errorf("countReadPages = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReadingSessionKeepsInitialReadPages は、登録時に入力した readPages が読書記録の追加・削除で消えないことを確かめる。
+func TestReadingSessionKeepsInitialReadPages(t *testing.T) {
+	s := newMemoryBookService()
+	ctx := auth.WithUser(context.Background(), &entity.User{ID: 1})
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	book, err := s.CreateBook(ctx, &entity.Book{Title: "t", Author: "a", TotalPages: 300, Publisher: "p", Status: entity.StatusReading, ReadPages: 120, CreatedAt: now, UpdatedAt: now})
+	if err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	session := &entity.ReadingSession{StartPage: 121, EndPage: 140, StartedAt: now, EndedAt: now.Add(time.Hour)}
+	updated, err := s.AddReadingSession(ctx, book.ID, session)
+	if err != nil {
+		t.Fatalf("AddReadingSession: %v", err)
+	}
+	if updated.ReadPages != 140 || updated.Status != entity.StatusReading {
+		t.Errorf("after AddReadingSession = {ReadPages: %d, Status: %s}, want {140, reading}", updated.ReadPages, updated.Status)
+	}
+	updated, err = s.DeleteReadingSession(ctx, book.ID, session.ID)
+	if err != nil {
+		t.Fatalf("DeleteReadingSession: %v", err)
+	}
+	if updated.ReadPages != 120 || updated.Status != entity.StatusReading {
+		t.Errorf("after DeleteReadingSession = {ReadPages: %d, Status: %s}, want {120, reading}", updated.ReadPages, updated.Status)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/infra/notify"
+)
+
+// ReminderSvc は目標ページ数/日に届いていない読書中の本を、ユーザーのタイムゾーンの決まった時刻に知らせる。
+// 確かめた日は送る前に User の remindedOn に書くので、インスタンスがいくつあっても、再起動しても1日1回しか送らない。
+type ReminderSvc struct {
+	users    repository.UserRepo
+	books    repository.BookRepo
+	sessions repository.ReadingSessionRepo
+	notifier notify.Notifier
+	hour     int // ユーザーのタイムゾーンで何時以降に送るか
+}
+
+func NewReminderService(users repository.UserRepo, books repository.BookRepo, sessions repository.ReadingSessionRepo, notifier notify.Notifier, hour int) *ReminderSvc {
+	return &ReminderSvc{
+		users:    users,
+		books:    books,
+		sessions: sessions,
+		notifier: notifier,
+		hour:     hour,
+	}
+}
+
+// RunReminders は interval ごとに SendReminders を呼ぶ。ctx が終わるまで戻らない。
+func (s *ReminderSvc) RunReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.SendReminders(ctx, now)
+			if err != nil {
+				log.Printf("reminder: %v", err)
+			}
+			if n > 0 {
+				log.Printf("reminder: sent %d reminders", n)
+			}
+		}
+	}
+}
+
+// SendReminders は dailyReminder を有効にしたユーザーのうち、そのユーザーのタイムゾーンで hour 時を過ぎていて、
+// 今日まだ確かめていない人の本を確かめ、届いていない本があればリマインダーを送る。送った数を返す。
+// 1人で失敗しても他のユーザーは続け、失敗した人は次の回にもう一度確かめる。
+func (s *ReminderSvc) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	users, err := s.users.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var firstErr error
+	for i := range users {
+		user := &users[i]
+		if !user.DailyReminder {
+			continue
+		}
+		loc := UserLocation(user)
+		today := civilDate(now, loc)
+		if now.In(loc).Hour() < s.hour || user.RemindedOn.Equal(today) {
+			continue
+		}
+		ok, err := s.remindOnce(auth.WithUser(ctx, user), user, today, loc)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("user %d: %w", user.ID, err)
+			}
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, firstErr
+}
+
+// remindOnce は remindedOn を今日にしてから remind する。ほかのインスタンスが先に今日にしていれば何もしない。
+// remind に失敗したら次の回にもう一度確かめられるよう remindedOn を戻す。
+func (s *ReminderSvc) remindOnce(ctx context.Context, user *entity.User, today time.Time, loc *time.Location) (bool, error) {
+	claimed, err := s.users.SetRemindedOn(ctx, user.ID, user.RemindedOn, today)
+	if err != nil || !claimed {
+		return false, err
+	}
+	ok, err := s.remind(ctx, user, today, loc)
+	if err != nil {
+		if _, rerr := s.users.SetRemindedOn(ctx, user.ID, today, user.RemindedOn); rerr != nil {
+			log.Printf("reminder: reset remindedOn of user %d: %v", user.ID, rerr)
+		}
+		return false, err
+	}
+	return ok, nil
+}
+
+// remind は user の今日の本を確かめ、届いていない本があれば送る。送ったかどうかを返す。
+func (s *ReminderSvc) remind(ctx context.Context, user *entity.User, today time.Time, loc *time.Location) (bool, error) {
+	books, err := s.books.FindAll(ctx)
+	if err != nil {
+		return false, err
+	}
+	sessions, err := s.sessions.FindAll(ctx)
+	if err != nil {
+		return false, err
+	}
+	unmet := unmetTargets(books, sessions, today, loc)
+	if len(unmet) == 0 {
+		return false, nil
+	}
+	err = s.notifier.Notify(ctx, notify.Notification{
+		UserID: user.ID,
+		Email:  user.Email,
+		Name:   user.Name,
+		Date:   today.Format("2006-01-02"),
+		Books:  unmet,
+	})
+	return err == nil, err
+}
+
+// unmetTargets は読書中で目標ページ数/日があり、date（loc の暦日）に終わった読書記録のページ数がそれに届いていない本。
+func unmetTargets(books []entity.Book, sessions []entity.ReadingSession, date time.Time, loc *time.Location) []notify.NotificationBook {
+	read := map[int]int{}
+	for _, session := range sessions {
+		if civilDate(session.EndedAt, loc).Equal(date) {
+			read[session.BookID] += session.EndPage - session.StartPage + 1
+		}
+	}
+	var unmet []notify.NotificationBook
+	for _, book := range books {
+		if book.Status != entity.StatusReading || book.TargetPagesPerDay <= 0 || read[book.ID] >= book.TargetPagesPerDay {
+			continue
+		}
+		unmet = append(unmet, notify.NotificationBook{
+			BookID:            book.ID,
+			Title:             book.Title,
+			TargetPagesPerDay: book.TargetPagesPerDay,
+			PagesRead:         read[book.ID],
+		})
+	}
+	return unmet
+}
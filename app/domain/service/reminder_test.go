@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/infra/notify"
+)
+
+// countingNotifier は送ったリマインダーを数える。err があれば送らずに返す。
+type countingNotifier struct {
+	sent []notify.Notification
+	err  error
+}
+
+func (n *countingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// TestSendRemindersOncePerDay は、同じ repository を使うインスタンスが2つあっても（再起動しても）1日1回しか送らず、
+// 送れなかったときは次の回にもう一度送ることを確かめる。
+func TestSendRemindersOncePerDay(t *testing.T) {
+	bookSvc := newMemoryBookService()
+	users := repository.NewMemoryUserRepo()
+	user := &entity.User{Email: "a@example.com", DailyReminder: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+	ctx := auth.WithUser(context.Background(), user)
+	now := time.Date(2024, 6, 10, 22, 0, 0, 0, time.UTC)
+	if _, err := bookSvc.CreateBook(ctx, &entity.Book{Title: "t", Author: "a", TotalPages: 300, Publisher: "p", Status: entity.StatusReading, TargetPagesPerDay: 20, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	notifier := &countingNotifier{}
+	newInstance := func() *ReminderSvc {
+		return NewReminderService(users, bookSvc.repo, bookSvc.sessionRepo, notifier, 21)
+	}
+	send := func(s *ReminderSvc, at time.Time) (int, error) {
+		return s.SendReminders(context.Background(), at)
+	}
+
+	// 送れなければ remindedOn を戻し、次の回に送る
+	notifier.err = errors.New("smtp down")
+	if _, err := send(newInstance(), now); err == nil {
+		t.Fatal("SendReminders with a failing notifier: err = nil")
+	}
+	notifier.err = nil
+	a, b := newInstance(), newInstance()
+	if n, err := send(a, now.Add(10*time.Minute)); err != nil || n != 1 {
+		t.Fatalf("SendReminders on instance a = %d, %v; want 1", n, err)
+	}
+	if n, err := send(b, now.Add(10*time.Minute)); err != nil || n != 0 {
+		t.Errorf("SendReminders on instance b = %d, %v; want 0", n, err)
+	}
+	if n, err := send(newInstance(), now.Add(time.Hour)); err != nil || n != 0 {
+		t.Errorf("SendReminders after a restart = %d, %v; want 0", n, err)
+	}
+	if n, err := send(b, now.Add(24*time.Hour)); err != nil || n != 1 {
+		t.Errorf("SendReminders the next day = %d, %v; want 1", n, err)
+	}
+	if len(notifier.sent) != 2 {
+		t.Errorf("sent %d reminders, want 2", len(notifier.sent))
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// ErrBookNotCompleted は読み終えていない本にレビューを書こうとしたときに返す。controller で 409 に変換する。
+var ErrBookNotCompleted = errors.New("book is not completed")
+
+// RatingStats は本の評価と、ログイン中のユーザーのレビュー全体の集計。
+type RatingStats struct {
+	Rating       *float64        // この本の評価。レビューがなければ nil
+	Count        int             // レビューした本の数
+	Average      float64         // 評価の平均（小数第2位まで）。レビューがなければ 0
+	Distribution map[float64]int // 評価（0.5〜5）ごとの件数
+}
+
+// ReviewSvc は読み終えた本のレビューを扱う。
+type ReviewSvc struct {
+	reviews    repository.ReviewRepo
+	statusRepo repository.StatusChangeRepo
+}
+
+func NewReviewService(reviews repository.ReviewRepo, statusRepo repository.StatusChangeRepo) *ReviewSvc {
+	return &ReviewSvc{reviews: reviews, statusRepo: statusRepo}
+}
+
+// PutReview は本のレビューを書く（すでにあれば書き換える）。本が completed でなければ ErrBookNotCompleted だが、
+// force なら status を問わず書ける（status を付ける前に読んだ本など）。
+// finishedOn がなければ最後に completed になった日（一度もなければ今日）にする。
+func (s *ReviewSvc) PutReview(ctx context.Context, bookID int, review *entity.Review, force bool) error {
+	if review.FinishedOn.IsZero() {
+		finished, err := s.lastCompleted(ctx, bookID)
+		if err != nil {
+			return err
+		}
+		review.FinishedOn = finished
+	}
+	return s.reviews.Put(ctx, bookID, review, func(book *entity.Book) error {
+		if !force && book.Status != entity.StatusCompleted {
+			return fmt.Errorf("%w: book is %s (use force=true to review anyway)", ErrBookNotCompleted, book.Status)
+		}
+		return nil
+	})
+}
+
+// lastCompleted は status の履歴から最後に completed になった日を返す。
+func (s *ReviewSvc) lastCompleted(ctx context.Context, bookID int) (time.Time, error) {
+	changes, err := s.statusRepo.FindByBookID(ctx, bookID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	at := time.Now()
+	for _, c := range changes {
+		if c.To == entity.StatusCompleted {
+			at = c.ChangedAt
+		}
+	}
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// RatingStats は bookID の本の評価と、ユーザーのレビュー全体の件数・平均・分布を返す。
+func (s *ReviewSvc) RatingStats(ctx context.Context, bookID int) (*RatingStats, error) {
+	reviews, err := s.reviews.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &RatingStats{Distribution: map[float64]int{}}
+	sum := 0.0
+	for _, r := range reviews {
+		if r.BookID == bookID {
+			rating := r.Rating
+			stats.Rating = &rating
+		}
+		stats.Count++
+		sum += r.Rating
+		stats.Distribution[r.Rating]++
+	}
+	if stats.Count > 0 {
+		stats.Average = math.Round(sum/float64(stats.Count)*100) / 100
+	}
+	return stats, nil
+}
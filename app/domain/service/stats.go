@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+const (
+	defaultStatsDays = 365  // from を省略したときの期間（to を含めて1年）
+	maxStatsDays     = 3660 // 期間の最大日数（およそ10年）
+	topStatsCount    = 10   // 著者・出版社のランキングの件数
+)
+
+// ErrInvalidDateRange は統計の期間が不正（from が to より後・長すぎる）なときに返す。controller で 400 に変換する。
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// Stats は読書の統計。日付はすべて暦日で、その日の 00:00:00Z で持つ。
+// ByStatus は期間に関係なく今の status ごとの冊数、それ以外は期間の中の記録から数える。
+type Stats struct {
+	From           time.Time
+	To             time.Time
+	TotalBooks     int
+	ByStatus       map[entity.Status]int
+	PagesRead      int
+	PagesPerDay    []PagesBucket
+	PagesPerWeek   []PagesBucket // 月曜始まり。最初と最後の週は期間の中の日だけ数える
+	PagesPerMonth  []PagesBucket
+	BooksFinished  int
+	BooksAbandoned int
+	// 以下は数える本がなければ nil
+	CompletionRate      *float64 // 期間の中で読み終えた / (読み終えた + 読むのをやめた)
+	AverageDaysToFinish *float64 // 読み始めて（最初の読書記録か reading になった日）から読み終えるまでの日数（両端を含む）
+	AverageBookLength   *float64 // 期間の中で読み終えた本の totalPages の平均
+	TopAuthors          []NameCount
+	TopPublishers       []NameCount
+	Heatmap             []HeatmapDay
+}
+
+// PagesBucket は日・週・月ごとに読んだページ数。Start はその日・週・月の初日。
+type PagesBucket struct {
+	Start time.Time
+	Pages int
+}
+
+// NameCount は著者・出版社ごとの、期間の中で読み終えた本の冊数。
+type NameCount struct {
+	Name  string
+	Books int
+}
+
+// HeatmapDay はカレンダーのヒートマップの1日分。Level は 0（記録なし）〜4 で、期間の中で一番読んだ日を 4 とする。
+type HeatmapDay struct {
+	Date     time.Time
+	Pages    int
+	Sessions int
+	Level    int
+}
+
+// StatsSvc は読書の統計を計算するドメインサービス。永続化に依存しない純粋な計算だけを行う。
+type StatsSvc struct{}
+
+func NewStatsService() *StatsSvc {
+	return &StatsSvc{}
+}
+
+// Stats は books・status の履歴・読書記録から、from〜to（どちらも含む暦日）の統計を loc のタイムゾーンで数える。
+// to を省略（ゼロ値）すると loc で見た今日（now）、from を省略すると to までの1年間にする。
+// changes は本ごとに changedAt 順に並んでいること。
+func (s *StatsSvc) Stats(books []entity.Book, changes []entity.StatusChange, sessions []entity.ReadingSession, from, to, now time.Time, loc *time.Location) (*Stats, error) {
+	if to.IsZero() {
+		to = civilDate(now, loc)
+	}
+	if from.IsZero() {
+		from = addDays(to, -(defaultStatsDays - 1))
+	}
+	days := daysBetween(from, to) + 1
+	if days < 1 {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidDateRange)
+	}
+	if days > maxStatsDays {
+		return nil, fmt.Errorf("%w: the range must be at most %d days", ErrInvalidDateRange, maxStatsDays)
+	}
+	st := &Stats{
+		From:       from,
+		To:         to,
+		TotalBooks: len(books),
+		ByStatus: map[entity.Status]int{
+			entity.StatusUnread:    0,
+			entity.StatusReading:   0,
+			entity.StatusPaused:    0,
+			entity.StatusCompleted: 0,
+			entity.StatusAbandoned: 0,
+		},
+	}
+	byID := make(map[int]*entity.Book, len(books))
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+		st.ByStatus[books[i].Status]++
+	}
+	inRange := func(t time.Time) (int, bool) {
+		i := daysBetween(from, civilDate(t, loc))
+		return i, i >= 0 && i < days
+	}
+
+	// 読書記録: 終了日で日ごとに数える
+	pages := make([]int, days)
+	counts := make([]int, days)
+	for _, session := range sessions {
+		if i, ok := inRange(session.EndedAt); ok {
+			pages[i] += session.EndPage - session.StartPage + 1
+			counts[i]++
+		}
+	}
+	st.PagesPerDay = make([]PagesBucket, days)
+	st.Heatmap = make([]HeatmapDay, days)
+	maxPages := 0
+	for i := range pages {
+		maxPages = max(maxPages, pages[i])
+	}
+	for i := range pages {
+		date := addDays(from, i)
+		st.PagesRead += pages[i]
+		st.PagesPerDay[i] = PagesBucket{Start: date, Pages: pages[i]}
+		st.Heatmap[i] = HeatmapDay{Date: date, Pages: pages[i], Sessions: counts[i], Level: heatmapLevel(pages[i], maxPages)}
+		week := addDays(date, -((int(date.Weekday()) + 6) % 7))
+		st.PagesPerWeek = addToBucket(st.PagesPerWeek, week, pages[i])
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		st.PagesPerMonth = addToBucket(st.PagesPerMonth, month, pages[i])
+	}
+
+	// 読み終えた本: 読み始めからの日数・長さ・著者・出版社
+	starts := readingStarts(changes, sessions)
+	var totalDays, totalLength int
+	authors := map[string]map[int]bool{}
+	publishers := map[string]map[int]bool{}
+	for _, c := range completions(changes) {
+		book, ok := byID[c.BookID]
+		if _, in := inRange(c.ChangedAt); !ok || !in {
+			continue
+		}
+		st.BooksFinished++
+		start := book.CreatedAt
+		if t, ok := starts[changeKey{c.BookID, c.ID}]; ok {
+			start = t
+		}
+		totalDays += max(daysBetween(civilDate(start, loc), civilDate(c.ChangedAt, loc)), 0) + 1
+		totalLength += book.TotalPages
+		countName(authors, book.Author, book.ID)
+		countName(publishers, book.Publisher, book.ID)
+	}
+	for _, c := range changes {
+		if _, in := inRange(c.ChangedAt); in && c.To == entity.StatusAbandoned && byID[c.BookID] != nil {
+			st.BooksAbandoned++
+		}
+	}
+	if st.BooksFinished > 0 {
+		st.AverageDaysToFinish = ratio(totalDays, st.BooksFinished)
+		st.AverageBookLength = ratio(totalLength, st.BooksFinished)
+	}
+	if n := st.BooksFinished + st.BooksAbandoned; n > 0 {
+		st.CompletionRate = ratio(st.BooksFinished, n)
+	}
+	st.TopAuthors = topNames(authors)
+	st.TopPublishers = topNames(publishers)
+	return st, nil
+}
+
+// changeKey は status の遷移を見分けるキー。Datastore の ID は親（本）の中でだけ一意なので本の ID と組にする。
+type changeKey struct {
+	bookID, id int
+}
+
+// readingStarts は読み終えた遷移ごとの読み始めの日時。
+// 前に読み終えてから（初めてなら最初から）の、reading になった遷移と読書記録の開始のうち一番早いもの。
+func readingStarts(changes []entity.StatusChange, sessions []entity.ReadingSession) map[changeKey]time.Time {
+	sessionsByBook := map[int][]time.Time{}
+	for _, session := range sessions {
+		sessionsByBook[session.BookID] = append(sessionsByBook[session.BookID], session.StartedAt)
+	}
+	completed := map[changeKey]bool{}
+	for _, c := range completions(changes) {
+		completed[changeKey{c.BookID, c.ID}] = true
+	}
+	starts := map[changeKey]time.Time{}
+	cycle := map[int]time.Time{} // bookID → 前に読み終えた日時
+	first := map[int]time.Time{} // bookID → 今の読み始め（reading になった遷移）
+	for _, c := range changes {
+		if c.To == entity.StatusReading {
+			if _, ok := first[c.BookID]; !ok {
+				first[c.BookID] = c.ChangedAt
+			}
+		}
+		key := changeKey{c.BookID, c.ID}
+		if !completed[key] {
+			continue
+		}
+		start, ok := first[c.BookID]
+		for _, t := range sessionsByBook[c.BookID] {
+			if t.After(cycle[c.BookID]) && !t.After(c.ChangedAt) && (!ok || t.Before(start)) {
+				start, ok = t, true
+			}
+		}
+		if ok {
+			starts[key] = start
+		}
+		cycle[c.BookID] = c.ChangedAt
+		delete(first, c.BookID)
+	}
+	return starts
+}
+
+// heatmapLevel は pages を期間の最大値に対する割合で 1〜4 の4段階にする（0 ページは 0）。
+func heatmapLevel(pages, maxPages int) int {
+	if pages <= 0 || maxPages <= 0 {
+		return 0
+	}
+	return min(ceilDiv(pages*4, maxPages), 4)
+}
+
+// addToBucket は start の区切りに pages を足す。区切りは日付順に足されるので、最後のものと同じか新しいかだけ見る。
+func addToBucket(buckets []PagesBucket, start time.Time, pages int) []PagesBucket {
+	if n := len(buckets); n > 0 && buckets[n-1].Start.Equal(start) {
+		buckets[n-1].Pages += pages
+		return buckets
+	}
+	return append(buckets, PagesBucket{Start: start, Pages: pages})
+}
+
+func countName(names map[string]map[int]bool, name string, bookID int) {
+	if name == "" {
+		return
+	}
+	if names[name] == nil {
+		names[name] = map[int]bool{}
+	}
+	names[name][bookID] = true
+}
+
+// topNames は冊数の多い順（同じなら名前順）に topStatsCount 件まで返す。
+func topNames(names map[string]map[int]bool) []NameCount {
+	out := make([]NameCount, 0, len(names))
+	for name, books := range names {
+		out = append(out, NameCount{Name: name, Books: len(books)})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Books != out[j].Books {
+			return out[i].Books > out[j].Books
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > topStatsCount {
+		out = out[:topStatsCount]
+	}
+	return out
+}
+
+// ratio は a / b を小数第2位までに丸めたもの。
+func ratio(a, b int) *float64 {
+	r := math.Round(float64(a)/float64(b)*100) / 100
+	return &r
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+func TestStatsRange(t *testing.T) {
+	// UTC では 6/10 の 20:00、東京ではもう 6/11
+	now := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		tz       string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  error
+	}{
+		{name: "defaults to the year up to today", tz: "UTC", wantFrom: date(2023, 6, 12), wantTo: date(2024, 6, 10)},
+		{name: "today is in the time zone", tz: "Asia/Tokyo", wantFrom: date(2023, 6, 13), wantTo: date(2024, 6, 11)},
+		{name: "from defaults to a year before to", to: date(2024, 1, 31), tz: "UTC", wantFrom: date(2023, 2, 1), wantTo: date(2024, 1, 31)},
+		{name: "to defaults to today", from: date(2024, 6, 1), tz: "UTC", wantFrom: date(2024, 6, 1), wantTo: date(2024, 6, 10)},
+		{name: "single day", from: date(2024, 6, 1), to: date(2024, 6, 1), tz: "UTC", wantFrom: date(2024, 6, 1), wantTo: date(2024, 6, 1)},
+		{name: "from after to", from: date(2024, 6, 2), to: date(2024, 6, 1), tz: "UTC", wantErr: ErrInvalidDateRange},
+		{name: "too long", from: date(2010, 1, 1), to: date(2024, 1, 1), tz: "UTC", wantErr: ErrInvalidDateRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := NewStatsService().Stats(nil, nil, nil, tt.from, tt.to, now, mustLoadLocation(t, tt.tz))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if !st.From.Equal(tt.wantFrom) || !st.To.Equal(tt.wantTo) {
+				t.Errorf("range = %s..%s, want %s..%s", st.From.Format(time.DateOnly), st.To.Format(time.DateOnly), tt.wantFrom.Format(time.DateOnly), tt.wantTo.Format(time.DateOnly))
+			}
+			if days := daysBetween(st.From, st.To) + 1; len(st.PagesPerDay) != days || len(st.Heatmap) != days {
+				t.Errorf("len(PagesPerDay) = %d, len(Heatmap) = %d, want %d", len(st.PagesPerDay), len(st.Heatmap), days)
+			}
+		})
+	}
+}
+
+func TestStatsBuckets(t *testing.T) {
+	// 期間は 1/31（水）〜 2/6（火）。1/29 の記録は期間の外
+	session := func(day time.Time, pages int) entity.ReadingSession {
+		at := day.Add(21 * time.Hour)
+		return entity.ReadingSession{BookID: 1, StartPage: 1, EndPage: pages, StartedAt: at.Add(-time.Hour), EndedAt: at}
+	}
+	sessions := []entity.ReadingSession{
+		session(date(2024, 1, 29), 10),
+		session(date(2024, 1, 31), 20),
+		session(date(2024, 2, 4), 5),
+		session(date(2024, 2, 5), 25),
+		session(date(2024, 2, 5), 15),
+		session(date(2024, 2, 6), 30),
+	}
+	st, err := NewStatsService().Stats(nil, nil, sessions, date(2024, 1, 31), date(2024, 2, 6), time.Now(), time.UTC)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if st.PagesRead != 95 {
+		t.Errorf("PagesRead = %d, want 95", st.PagesRead)
+	}
+	// 最初の週は月曜（1/29）から始まるが、期間の中の 1/31 からだけ数える
+	wantWeeks := []PagesBucket{{Start: date(2024, 1, 29), Pages: 25}, {Start: date(2024, 2, 5), Pages: 70}}
+	if !reflect.DeepEqual(st.PagesPerWeek, wantWeeks) {
+		t.Errorf("PagesPerWeek = %v, want %v", st.PagesPerWeek, wantWeeks)
+	}
+	wantMonths := []PagesBucket{{Start: date(2024, 1, 1), Pages: 20}, {Start: date(2024, 2, 1), Pages: 75}}
+	if !reflect.DeepEqual(st.PagesPerMonth, wantMonths) {
+		t.Errorf("PagesPerMonth = %v, want %v", st.PagesPerMonth, wantMonths)
+	}
+	wantHeatmap := []HeatmapDay{
+		{Date: date(2024, 1, 31), Pages: 20, Sessions: 1, Level: 2},
+		{Date: date(2024, 2, 1)},
+		{Date: date(2024, 2, 2)},
+		{Date: date(2024, 2, 3)},
+		{Date: date(2024, 2, 4), Pages: 5, Sessions: 1, Level: 1},
+		{Date: date(2024, 2, 5), Pages: 40, Sessions: 2, Level: 4},
+		{Date: date(2024, 2, 6), Pages: 30, Sessions: 1, Level: 3},
+	}
+	if !reflect.DeepEqual(st.Heatmap, wantHeatmap) {
+		t.Errorf("Heatmap = %v, want %v", st.Heatmap, wantHeatmap)
+	}
+}
+
+func TestHeatmapLevel(t *testing.T) {
+	tests := []struct {
+		pages, maxPages, want int
+	}{
+		{pages: 0, maxPages: 40, want: 0},
+		{pages: 0, maxPages: 0, want: 0},
+		{pages: 1, maxPages: 40, want: 1},
+		{pages: 10, maxPages: 40, want: 1},
+		{pages: 11, maxPages: 40, want: 2},
+		{pages: 20, maxPages: 40, want: 2},
+		{pages: 30, maxPages: 40, want: 3},
+		{pages: 31, maxPages: 40, want: 4},
+		{pages: 40, maxPages: 40, want: 4},
+		{pages: 3, maxPages: 3, want: 4},
+	}
+	for _, tt := range tests {
+		if got := heatmapLevel(tt.pages, tt.maxPages); got != tt.want {
+			t.Errorf("heatmapLevel(%d, %d) = %d, want %d", tt.pages, tt.maxPages, got, tt.want)
+		}
+	}
+}
+
+func TestReadingStarts(t *testing.T) {
+	change := func(id int, action entity.Transition, from, to entity.Status, at time.Time) entity.StatusChange {
+		return entity.StatusChange{ID: id, BookID: 1, Action: action, From: from, To: to, ChangedAt: at}
+	}
+	session := func(at time.Time) entity.ReadingSession {
+		return entity.ReadingSession{BookID: 1, StartedAt: at, EndedAt: at.Add(time.Hour)}
+	}
+	tests := []struct {
+		name     string
+		changes  []entity.StatusChange
+		sessions []entity.ReadingSession
+		want     map[changeKey]time.Time // 読み終えた遷移の ID ごとの読み始め
+	}{
+		{
+			name: "started by transition",
+			changes: []entity.StatusChange{
+				change(1, entity.TransitionStart, entity.StatusUnread, entity.StatusReading, date(2024, 1, 1)),
+				change(2, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 1, 10)),
+			},
+			sessions: []entity.ReadingSession{session(date(2024, 1, 3))},
+			want:     map[changeKey]time.Time{{1, 2}: date(2024, 1, 1)},
+		},
+		{
+			name: "session before the transition",
+			changes: []entity.StatusChange{
+				change(1, entity.TransitionStart, entity.StatusUnread, entity.StatusReading, date(2024, 1, 5)),
+				change(2, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 1, 10)),
+			},
+			sessions: []entity.ReadingSession{session(date(2024, 1, 2))},
+			want:     map[changeKey]time.Time{{1, 2}: date(2024, 1, 2)},
+		},
+		{
+			name: "sessions only",
+			changes: []entity.StatusChange{
+				change(1, entity.TransitionProgress, entity.StatusReading, entity.StatusCompleted, date(2024, 1, 10)),
+			},
+			sessions: []entity.ReadingSession{session(date(2024, 1, 4)), session(date(2024, 1, 8))},
+			want:     map[changeKey]time.Time{{1, 1}: date(2024, 1, 4)},
+		},
+		{
+			name: "no start",
+			changes: []entity.StatusChange{
+				change(1, entity.TransitionImport, entity.StatusUnread, entity.StatusCompleted, date(2024, 1, 10)),
+			},
+			want: map[changeKey]time.Time{},
+		},
+		{
+			name: "reread starts a new cycle",
+			changes: []entity.StatusChange{
+				change(1, entity.TransitionStart, entity.StatusUnread, entity.StatusReading, date(2024, 1, 1)),
+				change(2, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 1, 10)),
+				change(3, entity.TransitionReread, entity.StatusCompleted, entity.StatusReading, date(2024, 2, 1)),
+				change(4, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 2, 5)),
+			},
+			// 1回目の記録は2回目の読み始めに数えない
+			sessions: []entity.ReadingSession{session(date(2024, 1, 3)), session(date(2024, 2, 2))},
+			want:     map[changeKey]time.Time{{1, 2}: date(2024, 1, 1), {1, 4}: date(2024, 2, 1)},
+		},
+		{
+			name: "reread with sessions before the reread transition",
+			changes: []entity.StatusChange{
+				change(1, entity.TransitionStart, entity.StatusUnread, entity.StatusReading, date(2024, 1, 1)),
+				change(2, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 1, 10)),
+				change(3, entity.TransitionReread, entity.StatusCompleted, entity.StatusReading, date(2024, 2, 1)),
+				change(4, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 2, 5)),
+			},
+			sessions: []entity.ReadingSession{session(date(2024, 1, 20))},
+			want:     map[changeKey]time.Time{{1, 2}: date(2024, 1, 1), {1, 4}: date(2024, 1, 20)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readingStarts(tt.changes, tt.sessions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readingStarts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsCompletions(t *testing.T) {
+	from, to := date(2024, 1, 1), date(2024, 12, 31)
+	book := func(id int, author, publisher string, pages int) entity.Book {
+		return entity.Book{ID: id, Author: author, Publisher: publisher, TotalPages: pages, CreatedAt: date(2023, 12, 1)}
+	}
+	change := func(bookID, id int, action entity.Transition, from, to entity.Status, at time.Time) entity.StatusChange {
+		return entity.StatusChange{ID: id, BookID: bookID, Action: action, From: from, To: to, ChangedAt: at}
+	}
+	finished := func(bookID int, start, finish time.Time) []entity.StatusChange {
+		return []entity.StatusChange{
+			change(bookID, 1, entity.TransitionStart, entity.StatusUnread, entity.StatusReading, start),
+			change(bookID, 2, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, finish),
+		}
+	}
+	abandoned := func(bookID int, at time.Time) entity.StatusChange {
+		return change(bookID, 9, entity.TransitionAbandon, entity.StatusReading, entity.StatusAbandoned, at)
+	}
+	rate := func(r float64) *float64 { return &r }
+	tests := []struct {
+		name      string
+		books     []entity.Book
+		changes   []entity.StatusChange
+		finished  int
+		abandoned int
+		rate      *float64
+		avgDays   *float64
+		avgLength *float64
+		authors   []NameCount
+	}{
+		{
+			name:  "nothing finished",
+			books: []entity.Book{book(1, "A", "P", 100)},
+		},
+		{
+			name:      "finished and abandoned",
+			books:     []entity.Book{book(1, "A", "P", 100), book(2, "A", "P", 300), book(3, "B", "Q", 200), book(4, "C", "Q", 50)},
+			changes:   append(append(append(finished(1, date(2024, 1, 1), date(2024, 1, 10)), finished(2, date(2024, 2, 1), date(2024, 2, 2))...), finished(3, date(2024, 3, 1), date(2024, 3, 3))...), abandoned(4, date(2024, 4, 1))),
+			finished:  3,
+			abandoned: 1,
+			rate:      rate(0.75),
+			avgDays:   rate(5),
+			avgLength: rate(200),
+			authors:   []NameCount{{Name: "A", Books: 2}, {Name: "B", Books: 1}},
+		},
+		{
+			name:      "only abandoned",
+			books:     []entity.Book{book(1, "A", "P", 100)},
+			changes:   []entity.StatusChange{abandoned(1, date(2024, 4, 1))},
+			abandoned: 1,
+			rate:      rate(0),
+		},
+		{
+			name:  "outside the range",
+			books: []entity.Book{book(1, "A", "P", 100), book(2, "B", "P", 100)},
+			changes: append(finished(1, date(2023, 12, 20), date(2023, 12, 31)),
+				abandoned(2, date(2025, 1, 1))),
+		},
+		{
+			name:  "reread counts twice",
+			books: []entity.Book{book(1, "A", "P", 100)},
+			changes: append(finished(1, date(2024, 1, 1), date(2024, 1, 4)),
+				change(1, 3, entity.TransitionReread, entity.StatusCompleted, entity.StatusReading, date(2024, 2, 1)),
+				change(1, 4, entity.TransitionFinish, entity.StatusReading, entity.StatusCompleted, date(2024, 2, 2))),
+			finished:  2,
+			rate:      rate(1),
+			avgDays:   rate(3),
+			avgLength: rate(100),
+			authors:   []NameCount{{Name: "A", Books: 1}},
+		},
+		{
+			name:  "completion undone by editing readPages",
+			books: []entity.Book{book(1, "A", "P", 100)},
+			changes: append(finished(1, date(2024, 1, 1), date(2024, 1, 4)),
+				change(1, 3, entity.TransitionEdit, entity.StatusCompleted, entity.StatusReading, date(2024, 1, 5))),
+		},
+		{
+			name:    "deleted book",
+			changes: finished(1, date(2024, 1, 1), date(2024, 1, 4)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := NewStatsService().Stats(tt.books, tt.changes, nil, from, to, time.Now(), time.UTC)
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if st.BooksFinished != tt.finished || st.BooksAbandoned != tt.abandoned {
+				t.Errorf("finished, abandoned = %d, %d, want %d, %d", st.BooksFinished, st.BooksAbandoned, tt.finished, tt.abandoned)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want *float64
+			}{
+				{"CompletionRate", st.CompletionRate, tt.rate},
+				{"AverageDaysToFinish", st.AverageDaysToFinish, tt.avgDays},
+				{"AverageBookLength", st.AverageBookLength, tt.avgLength},
+			} {
+				if (f.got == nil) != (f.want == nil) || f.got != nil && *f.got != *f.want {
+					t.Errorf("%s = %v, want %v", f.name, deref(f.got), deref(f.want))
+				}
+			}
+			if len(st.TopAuthors) != len(tt.authors) || len(tt.authors) > 0 && !reflect.DeepEqual(st.TopAuthors, tt.authors) {
+				t.Errorf("TopAuthors = %v, want %v", st.TopAuthors, tt.authors)
+			}
+		})
+	}
+}
+
+func deref(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
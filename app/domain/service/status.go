@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ErrInvalidTransition は現在の status からはできない操作のときに返す。controller で 409 に変換する。
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+type transitionRule struct {
+	from []entity.Status
+	to   entity.Status
+}
+
+// transitionRules は手動で行える status の遷移。ここにない遷移はすべて拒否する。
+var transitionRules = map[entity.Transition]transitionRule{
+	entity.TransitionStart: {
+		from: []entity.Status{entity.StatusUnread, entity.StatusPaused},
+		to:   entity.StatusReading,
+	},
+	entity.TransitionPause: {
+		from: []entity.Status{entity.StatusReading},
+		to:   entity.StatusPaused,
+	},
+	entity.TransitionFinish: {
+		from: []entity.Status{entity.StatusReading, entity.StatusPaused},
+		to:   entity.StatusCompleted,
+	},
+	entity.TransitionAbandon: {
+		from: []entity.Status{entity.StatusUnread, entity.StatusReading, entity.StatusPaused},
+		to:   entity.StatusAbandoned,
+	},
+	entity.TransitionReread: {
+		from: []entity.Status{entity.StatusCompleted, entity.StatusAbandoned},
+		to:   entity.StatusReading,
+	},
+}
+
+// ChangeStatus は操作 action で本の status を遷移させ、遷移履歴と同じトランザクションで保存する。
+func (s *BookSvc) ChangeStatus(ctx context.Context, bookID int, action entity.Transition) (*entity.Book, *entity.StatusChange, error) {
+	return s.statusRepo.Transition(ctx, bookID, func(book *entity.Book) (*entity.StatusChange, error) {
+		return transition(book, action, time.Now())
+	})
+}
+
+// transition は遷移ルールを確認して book を書き換え、履歴を返す。
+func transition(book *entity.Book, action entity.Transition, now time.Time) (*entity.StatusChange, error) {
+	rule, ok := transitionRules[action]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidTransition, action)
+	}
+	if !slices.Contains(rule.from, book.Status) {
+		return nil, fmt.Errorf("%w: cannot %s a book that is %s", ErrInvalidTransition, action, book.Status)
+	}
+	switch action {
+	case entity.TransitionFinish:
+		if book.ReadPages != book.TotalPages {
+			return nil, fmt.Errorf("%w: completed requires readPages == totalPages", ErrInvalidTransition)
+		}
+	case entity.TransitionReread:
+		// これより前の読書記録は readPages に数えない
+		book.ReadPages = 0
+		book.BaseReadPages = 0
+		book.ReadingRestartedAt = now
+	}
+	change := &entity.StatusChange{
+		Action:    action,
+		From:      book.Status,
+		To:        rule.to,
+		ReadPages: book.ReadPages,
+		ChangedAt: now,
+	}
+	book.Status = rule.to
+	book.UpdatedAt = now
+	return change, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// Streak は読書の連続記録。読書記録のある日（記録の終了日）が続いた日数を数える。日付はすべて暦日で、その日の 00:00:00Z で持つ。
+type Streak struct {
+	Today time.Time
+	// Current は今日か昨日まで続いている連続日数。今日まだ読んでいなくても、昨日まで続いていれば途切れていない
+	Current      int
+	CurrentStart *time.Time
+	ReadToday    bool
+	Longest      int
+	LongestStart *time.Time
+	LongestEnd   *time.Time
+	LastReadOn   *time.Time
+	DaysRead     int // 読書記録のある日の数
+}
+
+// StreakSvc は連続記録を計算するドメインサービス。永続化に依存しない純粋な計算だけを行う。
+type StreakSvc struct{}
+
+func NewStreakService() *StreakSvc {
+	return &StreakSvc{}
+}
+
+// Streak は読書記録を loc のタイムゾーンで暦日に分け、loc で見た今日（now）までの連続記録を数える。
+// NormalizedDate のように UTC でそろえると、日本時間の朝の記録が前の日になってしまうので、ユーザーのタイムゾーンで分ける。
+func (s *StreakSvc) Streak(sessions []entity.ReadingSession, now time.Time, loc *time.Location) *Streak {
+	today := civilDate(now, loc)
+	st := &Streak{Today: today}
+	seen := map[time.Time]bool{}
+	var days []time.Time
+	for _, session := range sessions {
+		d := civilDate(session.EndedAt, loc)
+		if d.After(today) || seen[d] {
+			continue
+		}
+		seen[d] = true
+		days = append(days, d)
+	}
+	if len(days) == 0 {
+		return st
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	st.DaysRead = len(days)
+
+	start := days[0]
+	for i, d := range days {
+		if i > 0 && daysBetween(days[i-1], d) != 1 {
+			start = d
+		}
+		if n := daysBetween(start, d) + 1; n > st.Longest {
+			st.Longest = n
+			st.LongestStart, st.LongestEnd = ptrTime(start), ptrTime(d)
+		}
+	}
+	last := days[len(days)-1]
+	st.LastReadOn = ptrTime(last)
+	st.ReadToday = last.Equal(today)
+	if daysBetween(last, today) <= 1 {
+		st.Current = daysBetween(start, last) + 1
+		st.CurrentStart = ptrTime(start)
+	}
+	return st
+}
+
+// UserLocation はユーザーのタイムゾーン。未設定・読めない名前なら UTC。
+func UserLocation(user *entity.User) *time.Location {
+	if user == nil || user.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+func TestStreak(t *testing.T) {
+	// 記録の終了日時（UTC）
+	endedAt := func(times ...string) []entity.ReadingSession {
+		var sessions []entity.ReadingSession
+		for _, s := range times {
+			end, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				t.Fatalf("parse %q: %v", s, err)
+			}
+			sessions = append(sessions, entity.ReadingSession{StartedAt: end.Add(-time.Hour), EndedAt: end})
+		}
+		return sessions
+	}
+	// UTC では 6/10 の 20:00。東京では 6/11 の 5:00、ニューヨークでは 6/10 の 16:00
+	now := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		tz           string
+		sessions     []entity.ReadingSession
+		today        time.Time
+		current      int
+		currentStart time.Time
+		readToday    bool
+		longest      int
+		longestStart time.Time
+		daysRead     int
+	}{
+		{
+			name:  "no sessions",
+			tz:    "UTC",
+			today: date(2024, 6, 10),
+		},
+		{
+			name:         "read today",
+			tz:           "UTC",
+			sessions:     endedAt("2024-06-08T10:00:00Z", "2024-06-09T10:00:00Z", "2024-06-10T10:00:00Z", "2024-06-10T12:00:00Z"),
+			today:        date(2024, 6, 10),
+			current:      3,
+			currentStart: date(2024, 6, 8),
+			readToday:    true,
+			longest:      3,
+			longestStart: date(2024, 6, 8),
+			daysRead:     3,
+		},
+		{
+			name:         "not yet today but read yesterday",
+			tz:           "UTC",
+			sessions:     endedAt("2024-06-08T10:00:00Z", "2024-06-09T10:00:00Z"),
+			today:        date(2024, 6, 10),
+			current:      2,
+			currentStart: date(2024, 6, 8),
+			longest:      2,
+			longestStart: date(2024, 6, 8),
+			daysRead:     2,
+		},
+		{
+			name:         "broken streak",
+			tz:           "UTC",
+			sessions:     endedAt("2024-06-01T10:00:00Z", "2024-06-02T10:00:00Z", "2024-06-03T10:00:00Z", "2024-06-08T10:00:00Z"),
+			today:        date(2024, 6, 10),
+			longest:      3,
+			longestStart: date(2024, 6, 1),
+			daysRead:     4,
+		},
+		{
+			// 東京では 6/11 5:00。UTC の 6/9 16:00・6/10 16:00 はそれぞれ東京の 6/10 1:00・6/11 1:00
+			name:         "late UTC sessions are the next day in Tokyo",
+			tz:           "Asia/Tokyo",
+			sessions:     endedAt("2024-06-09T16:00:00Z", "2024-06-10T16:00:00Z"),
+			today:        date(2024, 6, 11),
+			current:      2,
+			currentStart: date(2024, 6, 10),
+			readToday:    true,
+			longest:      2,
+			longestStart: date(2024, 6, 10),
+			daysRead:     2,
+		},
+		{
+			// 同じ記録も UTC で分けると 6/9・6/10 になり、今日（6/10）読んだことになる
+			name:         "same sessions in UTC",
+			tz:           "UTC",
+			sessions:     endedAt("2024-06-09T16:00:00Z", "2024-06-10T16:00:00Z"),
+			today:        date(2024, 6, 10),
+			current:      2,
+			currentStart: date(2024, 6, 9),
+			readToday:    true,
+			longest:      2,
+			longestStart: date(2024, 6, 9),
+			daysRead:     2,
+		},
+		{
+			// UTC では別の日（6/9 23:00・6/10 1:00）でも、ニューヨークではどちらも 6/9 の夜
+			name:         "two UTC days are one day in New York",
+			tz:           "America/New_York",
+			sessions:     endedAt("2024-06-09T23:00:00Z", "2024-06-10T01:00:00Z"),
+			today:        date(2024, 6, 10),
+			current:      1,
+			currentStart: date(2024, 6, 9),
+			longest:      1,
+			longestStart: date(2024, 6, 9),
+			daysRead:     1,
+		},
+		{
+			// 東京の今日（6/11）より後の記録は数えない
+			name:         "future sessions are ignored",
+			tz:           "Asia/Tokyo",
+			sessions:     endedAt("2024-06-10T01:00:00Z", "2024-06-11T16:00:00Z"),
+			today:        date(2024, 6, 11),
+			current:      1,
+			currentStart: date(2024, 6, 10),
+			longest:      1,
+			longestStart: date(2024, 6, 10),
+			daysRead:     1,
+		},
+		{
+			// ニューヨークは 3/10 に夏時間になる（その日は 23 時間）。それでも1日ずつ続いている
+			name:         "across a daylight saving change",
+			tz:           "America/New_York",
+			sessions:     endedAt("2024-03-09T23:00:00Z", "2024-03-10T23:00:00Z", "2024-03-11T23:00:00Z"),
+			today:        date(2024, 6, 10),
+			longest:      3,
+			longestStart: date(2024, 3, 9),
+			daysRead:     3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := NewStreakService().Streak(tt.sessions, now, mustLoadLocation(t, tt.tz))
+			if !st.Today.Equal(tt.today) {
+				t.Errorf("Today = %v, want %v", st.Today, tt.today)
+			}
+			if st.Current != tt.current || st.ReadToday != tt.readToday {
+				t.Errorf("Current, ReadToday = %d, %v, want %d, %v", st.Current, st.ReadToday, tt.current, tt.readToday)
+			}
+			if !equalDate(st.CurrentStart, tt.currentStart) {
+				t.Errorf("CurrentStart = %v, want %v", st.CurrentStart, tt.currentStart)
+			}
+			if st.Longest != tt.longest || !equalDate(st.LongestStart, tt.longestStart) {
+				t.Errorf("Longest = %d from %v, want %d from %v", st.Longest, st.LongestStart, tt.longest, tt.longestStart)
+			}
+			if st.DaysRead != tt.daysRead {
+				t.Errorf("DaysRead = %d, want %d", st.DaysRead, tt.daysRead)
+			}
+		})
+	}
+}
+
+// equalDate は got が want と同じ日か、want がゼロ値で got が nil なら true。
+func equalDate(got *time.Time, want time.Time) bool {
+	if want.IsZero() {
+		return got == nil
+	}
+	return got != nil && got.Equal(want)
+}
+
+func TestUserLocation(t *testing.T) {
+	tests := []struct {
+		user *entity.User
+		want string
+	}{
+		{user: nil, want: "UTC"},
+		{user: &entity.User{}, want: "UTC"},
+		{user: &entity.User{TimeZone: "Asia/Tokyo"}, want: "Asia/Tokyo"},
+		{user: &entity.User{TimeZone: "Mars/Olympus_Mons"}, want: "UTC"},
+	}
+	for _, tt := range tests {
+		if got := UserLocation(tt.user).String(); got != tt.want {
+			t.Errorf("UserLocation(%+v) = %s, want %s", tt.user, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/infra/imaging"
+	"github.com/sora-00/booktracker-api/app/infra/storage"
+)
+
+// ErrThumbnailNotFound は本に付けようとした thumbnailId がない（または他のユーザーの画像）ときに返す。controller で 400 に変換する。
+var ErrThumbnailNotFound = errors.New("thumbnail not found")
+
+// ThumbnailSvc はアップロードした表紙画像の記録とファイルをまとめて扱う。
+// 本に付いていない画像は猶予期間を過ぎたら CollectGarbage で消す。
+type ThumbnailSvc struct {
+	repo  repository.ThumbnailRepo
+	store storage.ThumbnailStore
+}
+
+func NewThumbnailService(repo repository.ThumbnailRepo, store storage.ThumbnailStore) *ThumbnailSvc {
+	return &ThumbnailSvc{repo: repo, store: store}
+}
+
+// Upload は画像をサイズ違い（list / detail / original）に再エンコードして ThumbnailStore に保存し、
+// どの本にも付いていない状態で記録する。variants はサイズ名ごとの URL。
+// 画像形式はファイル名ではなく中身の先頭バイトで判定し、画像でなければ imaging.ErrNotImage を返す。
+func (s *ThumbnailSvc) Upload(ctx context.Context, r io.Reader) (*entity.Thumbnail, map[string]string, error) {
+	outputs, err := imaging.Process(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := randomID()
+	if err != nil {
+		return nil, nil, err
+	}
+	variants := map[string]string{}
+	files := make([]string, 0, len(outputs))
+	for _, out := range outputs {
+		name := ThumbnailFileName(id, out.Variant, out.Ext)
+		if err := s.store.Put(ctx, name, bytes.NewReader(out.Data), out.ContentType); err != nil {
+			return nil, nil, fmt.Errorf("put %s: %w", name, err)
+		}
+		variants[out.Variant.Name] = s.store.URL(name)
+		files = append(files, name)
+	}
+	thumbnail := &entity.Thumbnail{
+		ID:         id,
+		URL:        variants[imaging.VariantOriginal.Name],
+		Files:      files,
+		UploadedAt: time.Now(),
+	}
+	if err := s.Register(ctx, thumbnail); err != nil {
+		return nil, nil, fmt.Errorf("register %s: %w", id, err)
+	}
+	return thumbnail, variants, nil
+}
+
+// Register は ThumbnailStore に保存し終えた画像を、どの本にも付いていない状態で記録する。
+func (s *ThumbnailSvc) Register(ctx context.Context, thumbnail *entity.Thumbnail) error {
+	if thumbnail.UploadedAt.IsZero() {
+		thumbnail.UploadedAt = time.Now()
+	}
+	return s.repo.Create(ctx, thumbnail)
+}
+
+// Attach は画像を本に付ける。同じ本に付け直すのはそのまま成功する。
+func (s *ThumbnailSvc) Attach(ctx context.Context, id string, bookID int) (*entity.Thumbnail, error) {
+	t, err := s.repo.Attach(ctx, id, bookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrThumbnailNotFound
+	}
+	return t, err
+}
+
+// find は本に付ける前の確認用。別の本に付いていれば ErrThumbnailInUse。
+func (s *ThumbnailSvc) find(ctx context.Context, id string, bookID int) (*entity.Thumbnail, error) {
+	t, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrThumbnailNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.BookID != 0 && t.BookID != bookID {
+		return nil, repository.ErrThumbnailInUse
+	}
+	return t, nil
+}
+
+// Remove は画像の記録とファイル（サイズ違いすべて）を消す。もう消えているものは無視する。
+func (s *ThumbnailSvc) Remove(ctx context.Context, id string) error {
+	t, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.deleteFiles(ctx, *t)
+}
+
+// CollectGarbage は before より前にアップロードされ、どの本にも付いていない画像を全ユーザー分消し、消した件数を返す。
+// 記録を先に消すので、ファイルの削除に失敗しても本に付けられることはない（ファイルだけが残る）。
+func (s *ThumbnailSvc) CollectGarbage(ctx context.Context, before time.Time) (int, error) {
+	deleted, err := s.repo.DeleteUnattached(ctx, before)
+	for _, t := range deleted {
+		if err := s.deleteFiles(ctx, t); err != nil {
+			log.Printf("thumbnail gc: delete files of %s: %v", t.ID, err)
+		}
+	}
+	return len(deleted), err
+}
+
+// RunGC は interval ごとに、アップロードから grace を過ぎても本に付いていない画像を消す。ctx が終わるまで戻らない。
+func (s *ThumbnailSvc) RunGC(ctx context.Context, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.CollectGarbage(ctx, now.Add(-grace))
+			if err != nil {
+				log.Printf("thumbnail gc: %v", err)
+			}
+			if n > 0 {
+				log.Printf("thumbnail gc: deleted %d unattached thumbnails", n)
+			}
+		}
+	}
+}
+
+func (s *ThumbnailSvc) deleteFiles(ctx context.Context, t entity.Thumbnail) error {
+	var firstErr error
+	for _, name := range t.Files {
+		if err := s.store.Delete(ctx, name); err != nil && !errors.Is(err, storage.ErrNotFound) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ThumbnailFileName は保存するファイル名。original は "<id>.<ext>"（以前のアップロードと同じ）、
+// それ以外は "<id>_<variant>.<ext>"。
+func ThumbnailFileName(id string, v imaging.Variant, ext string) string {
+	if v.Name == imaging.VariantOriginal.Name {
+		return id + ext
+	}
+	return id + "_" + v.Name + ext
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+)
+
+// FindTrash はゴミ箱の本を、ゴミ箱に入れた新しい順に返す。
+func (s *BookSvc) FindTrash(ctx context.Context) ([]entity.Book, error) {
+	return s.repo.FindTrashed(ctx)
+}
+
+// RestoreBook はゴミ箱の本を戻す。ゴミ箱になければ repository.ErrTrashNotFound。
+func (s *BookSvc) RestoreBook(ctx context.Context, id int) (*entity.Book, error) {
+	return s.repo.Restore(ctx, id)
+}
+
+// PurgeBook はゴミ箱の本を子エンティティごと完全に消し、付いていた表紙画像も消す。ゴミ箱になければ repository.ErrTrashNotFound。
+func (s *BookSvc) PurgeBook(ctx context.Context, id int) error {
+	var thumbnailID string
+	err := s.repo.Delete(ctx, id, func(book *entity.Book) error {
+		if book.DeletedAt.IsZero() {
+			return repository.ErrTrashNotFound
+		}
+		thumbnailID = book.ThumbnailID
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return repository.ErrTrashNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.removeThumbnail(ctx, id, thumbnailID)
+	return nil
+}
+
+// PurgeTrash は before より前にゴミ箱に入れた本を全ユーザー分完全に消し、消した件数を返す。
+func (s *BookSvc) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	purged, err := s.repo.PurgeTrashed(ctx, before)
+	for _, p := range purged {
+		// 表紙画像の記録はユーザーごとなので、本のユーザーとして消す
+		s.removeThumbnail(auth.WithUser(ctx, &entity.User{ID: p.UserID}), p.Book.ID, p.Book.ThumbnailID)
+	}
+	return len(purged), err
+}
+
+// RunTrashPurge は interval ごとに、ゴミ箱に入れてから retention を過ぎた本を完全に消す。ctx が終わるまで戻らない。
+func (s *BookSvc) RunTrashPurge(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.PurgeTrash(ctx, now.Add(-retention))
+			if err != nil {
+				log.Printf("trash purge: %v", err)
+			}
+			if n > 0 {
+				log.Printf("trash purge: deleted %d books", n)
+			}
+		}
+	}
+}
+
+// removeThumbnail は完全に消した本の表紙画像を消す。本はもう消えているので、失敗してもエラーにはしない。
+func (s *BookSvc) removeThumbnail(ctx context.Context, bookID int, thumbnailID string) {
+	if thumbnailID == "" {
+		return
+	}
+	if err := s.thumbnails.Remove(ctx, thumbnailID); err != nil {
+		log.Printf("book: remove thumbnail %s of book %d: %v", thumbnailID, bookID, err)
+	}
+}
@@ -0,0 +1,190 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Variant は保存する画像のサイズ違い。MaxSize は長辺の上限（px）。
+type Variant struct {
+	Name    string
+	MaxSize int
+}
+
+// Variants はアップロードされた表紙画像から作るサイズ違い。
+// original は元画像の長辺を 1600px までに縮めたもので、元画像そのものは保存しない。
+var (
+	VariantList     = Variant{Name: "list", MaxSize: 96}
+	VariantDetail   = Variant{Name: "detail", MaxSize: 320}
+	VariantOriginal = Variant{Name: "original", MaxSize: 1600}
+	Variants        = []Variant{VariantList, VariantDetail, VariantOriginal}
+)
+
+// MaxFileSize は受け付ける画像ファイルの大きさの上限（バイト）。
+const MaxFileSize = 10 << 20
+
+// 展開後のサイズで弾く上限（画素数）。小さなファイルで巨大な画像を展開させる攻撃を防ぐ
+const maxPixels = 50_000_000
+
+var (
+	// ErrNotImage は先頭のバイト列が対応している画像形式（JPEG / PNG / GIF / WebP）でないときに返す。
+	ErrNotImage = errors.New("file is not a supported image (jpeg, png, gif, webp)")
+	// ErrImageTooLarge は展開後の画素数が大きすぎるときに返す。
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+	// ErrFileTooLarge はファイルが MaxFileSize より大きいときに返す。
+	ErrFileTooLarge = errors.New("image file is larger than 10MB")
+)
+
+// Output は再エンコードした1サイズ分の画像。
+type Output struct {
+	Variant     Variant
+	Data        []byte
+	ContentType string
+	Ext         string
+}
+
+// Sniff は先頭のバイト列（マジックナンバー）から画像形式を判定する。拡張子や Content-Type は信用しない。
+func Sniff(head []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg", true
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "png", true
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return "gif", true
+	case len(head) >= 12 && bytes.Equal(head[0:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		return "webp", true
+	}
+	return "", false
+}
+
+// Process は画像を展開して Variants の各サイズに縮小し、再エンコードする。
+// 再エンコードするので EXIF などのメタデータは残らない（JPEG の向きだけは画素に反映してから捨てる）。
+// 透過のない画像は JPEG、透過のある画像は PNG にする。GIF は1コマ目だけを使う。
+// MaxFileSize より先は読まずに ErrFileTooLarge を返す。
+func Process(r io.Reader) ([]Output, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(12)
+	format, ok := Sniff(head)
+	if !ok {
+		return nil, ErrNotImage
+	}
+	data, err := io.ReadAll(io.LimitReader(br, MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	cfg, err := decodeConfig(format, data)
+	if err != nil {
+		return nil, ErrNotImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+	src, err := decode(format, data)
+	if err != nil {
+		return nil, ErrNotImage
+	}
+
+	// 大きい写真をそのまま回転させると重いので、先に original の大きさまで縮めてから向きを直す
+	base := resize(src, VariantOriginal.MaxSize)
+	if format == "jpeg" {
+		base = applyOrientation(base, jpegOrientation(data))
+	}
+	opaque := isOpaque(base)
+
+	outputs := make([]Output, 0, len(Variants))
+	for _, v := range Variants {
+		img := resize(base, v.MaxSize)
+		out, err := encode(img, opaque)
+		if err != nil {
+			return nil, err
+		}
+		out.Variant = v
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+func decodeConfig(format string, data []byte) (image.Config, error) {
+	r := bytes.NewReader(data)
+	switch format {
+	case "jpeg":
+		return jpeg.DecodeConfig(r)
+	case "png":
+		return png.DecodeConfig(r)
+	case "gif":
+		return gif.DecodeConfig(r)
+	default:
+		return webp.DecodeConfig(r)
+	}
+}
+
+func decode(format string, data []byte) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch format {
+	case "jpeg":
+		return jpeg.Decode(r)
+	case "png":
+		return png.Decode(r)
+	case "gif":
+		return gif.Decode(r)
+	default:
+		return webp.Decode(r)
+	}
+}
+
+// resize は長辺が maxSize 以下になるように縦横比を保って縮小する。小さい画像は拡大しない。
+func resize(src image.Image, maxSize int) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxSize || h > maxSize {
+		if w >= h {
+			w, h = maxSize, max(h*maxSize/w, 1)
+		} else {
+			w, h = max(w*maxSize/h, 1), maxSize
+		}
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func isOpaque(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xFF {
+			return false
+		}
+	}
+	return true
+}
+
+func encode(img *image.NRGBA, opaque bool) (Output, error) {
+	var buf bytes.Buffer
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return Output{}, err
+		}
+		return Output{Data: buf.Bytes(), ContentType: "image/jpeg", Ext: ".jpg"}, nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return Output{}, err
+	}
+	return Output{Data: buf.Bytes(), ContentType: "image/png", Ext: ".png"}, nil
+}
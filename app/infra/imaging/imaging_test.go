@@ -0,0 +1,247 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name   string
+		head   []byte
+		format string
+		ok     bool
+	}{
+		{name: "jpeg", head: []byte{0xFF, 0xD8, 0xFF, 0xE0}, format: "jpeg", ok: true},
+		{name: "png", head: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), format: "png", ok: true},
+		{name: "gif87a", head: []byte("GIF87a"), format: "gif", ok: true},
+		{name: "gif89a", head: []byte("GIF89a"), format: "gif", ok: true},
+		{name: "webp", head: []byte("RIFF\x24\x00\x00\x00WEBP"), format: "webp", ok: true},
+		{name: "riff that is not webp", head: []byte("RIFF\x24\x00\x00\x00WAVE")},
+		{name: "short riff", head: []byte("RIFF")},
+		{name: "text", head: []byte("<svg xmlns=")},
+		{name: "pdf", head: []byte("%PDF-1.7")},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := Sniff(tt.head)
+			if format != tt.format || ok != tt.ok {
+				t.Errorf("Sniff = %q, %v; want %q, %v", format, ok, tt.format, tt.ok)
+			}
+		})
+	}
+}
+
+// newImage は左半分が赤、右半分が青の w×h の画像。alpha が 0xFF でなければ全体をその透明度にする。
+func newImage(w, h int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 0xFF, A: alpha}
+			if x >= w/2 {
+				c = color.NRGBA{B: 0xFF, A: alpha}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation は JPEG の SOI の直後に Orientation タグだけの EXIF（APP1）を入れる。
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")  // ビッグエンディアン、IFD は 8 バイト目から
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // エントリーは1つ
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0) // 次の IFD はない
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(seg)+2))
+	out = append(out, seg...)
+	return append(out, data[2:]...)
+}
+
+func decodeOutput(t *testing.T, out Output) image.Image {
+	t.Helper()
+	img, _, err := image.Decode(bytes.NewReader(out.Data))
+	if err != nil {
+		t.Fatalf("decode %s: %v", out.Variant.Name, err)
+	}
+	return img
+}
+
+func TestProcessSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		want map[string][2]int // Variant.Name → 幅・高さ
+	}{
+		{
+			name: "wide image is shrunk to each size",
+			w:    2000, h: 1000,
+			want: map[string][2]int{"list": {96, 48}, "detail": {320, 160}, "original": {1600, 800}},
+		},
+		{
+			name: "tall image is shrunk by its height",
+			w:    100, h: 400,
+			want: map[string][2]int{"list": {24, 96}, "detail": {80, 320}, "original": {100, 400}},
+		},
+		{
+			name: "small image is not upscaled",
+			w:    50, h: 80,
+			want: map[string][2]int{"list": {50, 80}, "detail": {50, 80}, "original": {50, 80}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := Process(bytes.NewReader(encodePNG(t, newImage(tt.w, tt.h, 0xFF))))
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if len(outputs) != len(Variants) {
+				t.Fatalf("len(outputs) = %d, want %d", len(outputs), len(Variants))
+			}
+			for i, out := range outputs {
+				if out.Variant != Variants[i] {
+					t.Errorf("outputs[%d].Variant = %v, want %v", i, out.Variant, Variants[i])
+				}
+				b := decodeOutput(t, out).Bounds()
+				if got := [2]int{b.Dx(), b.Dy()}; got != tt.want[out.Variant.Name] {
+					t.Errorf("%s = %dx%d, want %dx%d", out.Variant.Name, got[0], got[1], tt.want[out.Variant.Name][0], tt.want[out.Variant.Name][1])
+				}
+			}
+		})
+	}
+}
+
+// TestProcessFormat は透過のない画像が JPEG、透過のある画像が PNG になることを確かめる。
+func TestProcessFormat(t *testing.T) {
+	var gifData bytes.Buffer
+	paletted := image.NewPaletted(image.Rect(0, 0, 10, 10), color.Palette{color.Black, color.White})
+	if err := gif.Encode(&gifData, paletted, nil); err != nil {
+		t.Fatalf("gif.Encode: %v", err)
+	}
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		ext         string
+	}{
+		{name: "opaque png", data: encodePNG(t, newImage(10, 10, 0xFF)), contentType: "image/jpeg", ext: ".jpg"},
+		{name: "transparent png", data: encodePNG(t, newImage(10, 10, 0x80)), contentType: "image/png", ext: ".png"},
+		{name: "jpeg", data: encodeJPEG(t, newImage(10, 10, 0xFF)), contentType: "image/jpeg", ext: ".jpg"},
+		{name: "gif", data: gifData.Bytes(), contentType: "image/jpeg", ext: ".jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := Process(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			for _, out := range outputs {
+				if out.ContentType != tt.contentType || out.Ext != tt.ext {
+					t.Errorf("%s = %s (%s), want %s (%s)", out.Variant.Name, out.ContentType, out.Ext, tt.contentType, tt.ext)
+				}
+				if out.ContentType == "image/png" {
+					if _, _, _, a := decodeOutput(t, out).At(0, 0).RGBA(); a == 0xFFFF {
+						t.Errorf("%s lost its transparency", out.Variant.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestProcessOrientation は JPEG の EXIF の向きが画素に反映されることを確かめる。元の画像は 40×20 で左が赤・右が青。
+func TestProcessOrientation(t *testing.T) {
+	isRed := func(c color.Color) bool {
+		r, _, b, _ := c.RGBA()
+		return r > 0xC000 && b < 0x4000
+	}
+	tests := []struct {
+		orientation uint16
+		w, h        int
+		redAt       image.Point // 赤いはずの位置
+	}{
+		{orientation: 1, w: 40, h: 20, redAt: image.Pt(2, 10)},
+		{orientation: 3, w: 40, h: 20, redAt: image.Pt(37, 10)}, // 180 度回転で赤が右に
+		{orientation: 6, w: 20, h: 40, redAt: image.Pt(10, 2)},  // 時計回りに 90 度で赤が上に
+		{orientation: 8, w: 20, h: 40, redAt: image.Pt(10, 37)}, // 反時計回りに 90 度で赤が下に
+		{orientation: 2, w: 40, h: 20, redAt: image.Pt(37, 10)}, // 左右反転で赤が右に
+		{orientation: 9, w: 40, h: 20, redAt: image.Pt(2, 10)},  // 範囲外の値はそのまま
+	}
+	src := encodeJPEG(t, newImage(40, 20, 0xFF))
+	for _, tt := range tests {
+		data := withOrientation(src, tt.orientation)
+		outputs, err := Process(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("orientation %d: Process: %v", tt.orientation, err)
+		}
+		img := decodeOutput(t, outputs[len(outputs)-1])
+		if b := img.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: size = %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if !isRed(img.At(tt.redAt.X, tt.redAt.Y)) {
+			t.Errorf("orientation %d: pixel at %v = %v, want red", tt.orientation, tt.redAt, img.At(tt.redAt.X, tt.redAt.Y))
+		}
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	// 画面の大きさだけを 10000×10000 にした GIF。展開する前に画素数で弾く
+	bomb := []byte("GIF89a")
+	bomb = binary.LittleEndian.AppendUint16(bomb, 10000)
+	bomb = binary.LittleEndian.AppendUint16(bomb, 10000)
+	bomb = append(bomb, 0, 0, 0, ';')
+
+	tooBig := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, MaxFileSize)...)
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "not an image", data: []byte("hello, world"), want: ErrNotImage},
+		{name: "broken png", data: []byte("\x89PNG\r\n\x1a\nbroken"), want: ErrNotImage},
+		{name: "decompression bomb", data: bomb, want: ErrImageTooLarge},
+		{name: "file over MaxFileSize", data: tooBig, want: ErrFileTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("Process: err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
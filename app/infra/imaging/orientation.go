@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation は JPEG の EXIF（APP1）から Orientation タグ（0x0112）を読む。見つからなければ 1（そのまま）。
+func jpegOrientation(data []byte) int {
+	// SOI の後ろのマーカーを SOS まで順に見る
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA { // SOS 以降は画像データ
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + size
+		if size < 2 || end > len(data) {
+			return 1
+		}
+		if marker == 0xE1 {
+			if o, ok := exifOrientation(data[i+4 : end]); ok {
+				return o
+			}
+		}
+		i = end
+	}
+	return 1
+}
+
+func exifOrientation(seg []byte) (int, bool) {
+	if !bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+		return 0, false
+	}
+	tiff := seg[6:]
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 0, false
+	}
+	n := int(order.Uint16(tiff[ifd : ifd+2]))
+	for j := 0; j < n; j++ {
+		e := ifd + 2 + j*12
+		if e+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[e:e+2]) == 0x0112 {
+			o := int(order.Uint16(tiff[e+8 : e+10]))
+			if o >= 1 && o <= 8 {
+				return o, true
+			}
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// applyOrientation は EXIF の Orientation（1〜8）に合わせて画素を回転・反転する。
+func applyOrientation(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 左右反転
+				sx, sy = w-1-x, y
+			case 3: // 180 度回転
+				sx, sy = w-1-x, h-1-y
+			case 4: // 上下反転
+				sx, sy = x, h-1-y
+			case 5: // 転置
+				sx, sy = y, x
+			case 6: // 時計回りに 90 度
+				sx, sy = y, h-1-x
+			case 7: // 反転して時計回りに 270 度
+				sx, sy = w-1-y, h-1-x
+			case 8: // 反時計回りに 90 度
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
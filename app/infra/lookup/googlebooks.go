@@ -0,0 +1,72 @@
+package lookup
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultGoogleBooksBaseURL = "https://www.googleapis.com"
+
+// GoogleBooks は Google Books API の volumes 検索（q=isbn:）で引く。
+type GoogleBooks struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewGoogleBooks(baseURL, apiKey string, client *http.Client) *GoogleBooks {
+	if baseURL == "" {
+		baseURL = defaultGoogleBooksBaseURL
+	}
+	return &GoogleBooks{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, client: client}
+}
+
+type googleBooksVolumes struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo struct {
+			Title         string   `json:"title"`
+			Authors       []string `json:"authors"`
+			Publisher     string   `json:"publisher"`
+			PageCount     int      `json:"pageCount"`
+			PublishedDate string   `json:"publishedDate"`
+			ImageLinks    struct {
+				SmallThumbnail string `json:"smallThumbnail"`
+				Thumbnail      string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func (p *GoogleBooks) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	q := url.Values{"q": {"isbn:" + isbn}}
+	if p.apiKey != "" {
+		q.Set("key", p.apiKey)
+	}
+	var res googleBooksVolumes
+	if err := getJSON(ctx, p.client, p.baseURL+"/books/v1/volumes?"+q.Encode(), &res); err != nil {
+		return nil, err
+	}
+	if len(res.Items) == 0 || res.Items[0].VolumeInfo.Title == "" {
+		return nil, ErrNotFound
+	}
+	info := res.Items[0].VolumeInfo
+	m := &Metadata{
+		ISBN:          isbn,
+		Title:         info.Title,
+		Authors:       info.Authors,
+		Publisher:     info.Publisher,
+		PageCount:     info.PageCount,
+		PublishedDate: info.PublishedDate,
+		Source:        "googlebooks",
+	}
+	for _, cover := range []string{info.ImageLinks.Thumbnail, info.ImageLinks.SmallThumbnail} {
+		if cover != "" {
+			m.CoverURL = httpsURL(cover)
+			break
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,85 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGoogleBooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKey  string
+		status  int
+		body    string
+		want    *Metadata
+		wantErr error
+	}{
+		{
+			name:   "found",
+			status: http.StatusOK,
+			body: `{"totalItems": 1, "items": [{"volumeInfo": {
+				"title": "Understanding Compilers",
+				"authors": ["A. Author"],
+				"publisher": "Plenum",
+				"pageCount": 324,
+				"publishedDate": "1980-01-01",
+				"imageLinks": {"smallThumbnail": "http://books.example/s.jpg", "thumbnail": "http://books.example/t.jpg"}
+			}}]}`,
+			// 表紙の http:// は https:// にそろえる
+			want: &Metadata{
+				ISBN:          "9780306406157",
+				Title:         "Understanding Compilers",
+				Authors:       []string{"A. Author"},
+				Publisher:     "Plenum",
+				PageCount:     324,
+				PublishedDate: "1980-01-01",
+				CoverURL:      "https://books.example/t.jpg",
+				Source:        "googlebooks",
+			},
+		},
+		{
+			name:   "with api key",
+			apiKey: "secret",
+			status: http.StatusOK,
+			body:   `{"totalItems": 1, "items": [{"volumeInfo": {"title": "T", "imageLinks": {"smallThumbnail": "https://books.example/s.jpg"}}}]}`,
+			want:   &Metadata{ISBN: "9780306406157", Title: "T", CoverURL: "https://books.example/s.jpg", Source: "googlebooks"},
+		},
+		{name: "not found", status: http.StatusOK, body: `{"totalItems": 0}`, wantErr: ErrNotFound},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{"error": {}}`, wantErr: ErrUnavailable},
+		{name: "invalid JSON", status: http.StatusOK, body: `{"items": [`, wantErr: ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if r.URL.Path != "/books/v1/volumes" || q.Get("q") != "isbn:9780306406157" {
+					t.Errorf("request = %s, want /books/v1/volumes?q=isbn:9780306406157", r.URL)
+				}
+				if got := q.Get("key"); got != tt.apiKey {
+					t.Errorf("key = %q, want %q", got, tt.apiKey)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got, err := NewGoogleBooks(srv.URL, tt.apiKey, srv.Client()).Lookup(context.Background(), "9780306406157")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package lookup
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultOpenLibraryBaseURL = "https://openlibrary.org"
+
+// OpenLibrary は Open Library の Books API（jscmd=data）で引く。
+type OpenLibrary struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOpenLibrary(baseURL string, client *http.Client) *OpenLibrary {
+	if baseURL == "" {
+		baseURL = defaultOpenLibraryBaseURL
+	}
+	return &OpenLibrary{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+type openLibraryName struct {
+	Name string `json:"name"`
+}
+
+type openLibraryBook struct {
+	Title         string            `json:"title"`
+	Subtitle      string            `json:"subtitle"`
+	Authors       []openLibraryName `json:"authors"`
+	Publishers    []openLibraryName `json:"publishers"`
+	NumberOfPages int               `json:"number_of_pages"`
+	PublishDate   string            `json:"publish_date"`
+	Cover         struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *OpenLibrary) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	key := "ISBN:" + isbn
+	q := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+	var res map[string]openLibraryBook
+	if err := getJSON(ctx, p.client, p.baseURL+"/api/books?"+q.Encode(), &res); err != nil {
+		return nil, err
+	}
+	book, ok := res[key]
+	if !ok || book.Title == "" {
+		return nil, ErrNotFound
+	}
+	m := &Metadata{
+		ISBN:          isbn,
+		Title:         book.Title,
+		PageCount:     book.NumberOfPages,
+		PublishedDate: book.PublishDate,
+		Source:        "openlibrary",
+	}
+	for _, a := range book.Authors {
+		m.Authors = append(m.Authors, a.Name)
+	}
+	if len(book.Publishers) > 0 {
+		m.Publisher = book.Publishers[0].Name
+	}
+	for _, cover := range []string{book.Cover.Large, book.Cover.Medium, book.Cover.Small} {
+		if cover != "" {
+			m.CoverURL = cover
+			break
+		}
+	}
+	return m, nil
+}
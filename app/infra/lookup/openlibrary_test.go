@@ -0,0 +1,89 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestOpenLibrary(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    *Metadata
+		wantErr error
+	}{
+		{
+			name:   "found",
+			status: http.StatusOK,
+			body: `{"ISBN:9780306406157": {
+				"title": "Understanding Compilers",
+				"authors": [{"name": "A. Author"}, {"name": "B. Author"}],
+				"publishers": [{"name": "Plenum"}, {"name": "Other"}],
+				"number_of_pages": 324,
+				"publish_date": "1980",
+				"cover": {"small": "https://covers.example/s.jpg", "large": "https://covers.example/l.jpg"}
+			}}`,
+			want: &Metadata{
+				ISBN:          "9780306406157",
+				Title:         "Understanding Compilers",
+				Authors:       []string{"A. Author", "B. Author"},
+				Publisher:     "Plenum",
+				PageCount:     324,
+				PublishedDate: "1980",
+				CoverURL:      "https://covers.example/l.jpg",
+				Source:        "openlibrary",
+			},
+		},
+		{
+			name:   "missing fields",
+			status: http.StatusOK,
+			body:   `{"ISBN:9780306406157": {"title": "Title only", "cover": {"small": "https://covers.example/s.jpg"}}}`,
+			want:   &Metadata{ISBN: "9780306406157", Title: "Title only", CoverURL: "https://covers.example/s.jpg", Source: "openlibrary"},
+		},
+		{name: "not found", status: http.StatusOK, body: `{}`, wantErr: ErrNotFound},
+		{name: "no title", status: http.StatusOK, body: `{"ISBN:9780306406157": {}}`, wantErr: ErrNotFound},
+		{name: "server error", status: http.StatusInternalServerError, body: `oops`, wantErr: ErrUnavailable},
+		{name: "invalid JSON", status: http.StatusOK, body: `<html>`, wantErr: ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if r.URL.Path != "/api/books" || q.Get("bibkeys") != "ISBN:9780306406157" || q.Get("format") != "json" || q.Get("jscmd") != "data" {
+					t.Errorf("request = %s, want /api/books?bibkeys=ISBN:9780306406157&format=json&jscmd=data", r.URL)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got, err := NewOpenLibrary(srv.URL+"/", srv.Client()).Lookup(context.Background(), "9780306406157")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenLibraryUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	_, err := NewOpenLibrary(srv.URL, srv.Client()).Lookup(context.Background(), "9780306406157")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,158 @@
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// MetadataProvider は ISBN から本の書誌情報を引く外部サービス。isbn は ISBN-13（ハイフンなし）で渡す。
+type MetadataProvider interface {
+	Lookup(ctx context.Context, isbn string) (*Metadata, error)
+}
+
+// Metadata は外部サービスから引いた書誌情報。分からない項目は空・0。
+type Metadata struct {
+	ISBN          string
+	Title         string
+	Authors       []string
+	Publisher     string
+	PageCount     int
+	PublishedDate string // サービスの表記のまま（"2020" / "2020-04-01" / "April 1, 2020" など）
+	CoverURL      string
+	Source        string // 引いたサービスの名前（openlibrary / googlebooks）
+}
+
+var (
+	// ErrNotFound はどのサービスにもその ISBN の本がないときに返す。controller で 404 に変換する。
+	ErrNotFound = errors.New("no book found for the isbn")
+	// ErrUnavailable は外部サービスに接続できない・エラーを返したときに返す。controller で 502 に変換する。
+	ErrUnavailable = errors.New("book metadata service unavailable")
+)
+
+// NewMetadataProvider は環境変数 METADATA_PROVIDERS（カンマ区切りで openlibrary / googlebooks、既定は両方この順）で
+// 選んだサービスを順に引く MetadataProvider を返す。
+// 接続先は OPENLIBRARY_BASE_URL / GOOGLE_BOOKS_BASE_URL で変えられる。GOOGLE_BOOKS_API_KEY があれば付けて引く。
+func NewMetadataProvider() (MetadataProvider, error) {
+	names := os.Getenv("METADATA_PROVIDERS")
+	if names == "" {
+		names = "openlibrary,googlebooks"
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	var providers []MetadataProvider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "openlibrary":
+			providers = append(providers, NewOpenLibrary(os.Getenv("OPENLIBRARY_BASE_URL"), client))
+		case "googlebooks":
+			providers = append(providers, NewGoogleBooks(os.Getenv("GOOGLE_BOOKS_BASE_URL"), os.Getenv("GOOGLE_BOOKS_API_KEY"), client))
+		default:
+			return nil, fmt.Errorf("unknown metadata provider %q (use openlibrary or googlebooks)", name)
+		}
+	}
+	return NewChain(providers...), nil
+}
+
+// Chain は複数のサービスを順に引く。最初に見つかったものを使い、そこに足りない項目（ページ数・表紙など）は後のサービスで埋める。
+// どこにもなければ ErrNotFound、見つからないうえに失敗したサービスがあれば ErrUnavailable を返す。
+type Chain struct {
+	providers []MetadataProvider
+}
+
+func NewChain(providers ...MetadataProvider) *Chain {
+	return &Chain{providers: providers}
+}
+
+func (c *Chain) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	var found *Metadata
+	var failed error
+	for _, p := range c.providers {
+		m, err := p.Lookup(ctx, isbn)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+		if found == nil {
+			found = m
+		} else {
+			found.fill(m)
+		}
+		if found.complete() {
+			break
+		}
+	}
+	switch {
+	case found != nil:
+		return found, nil
+	case failed != nil:
+		return nil, failed
+	}
+	return nil, ErrNotFound
+}
+
+func (m *Metadata) complete() bool {
+	return m.Title != "" && len(m.Authors) > 0 && m.Publisher != "" && m.PageCount > 0 && m.CoverURL != ""
+}
+
+// fill は m の空の項目を other で埋める。
+func (m *Metadata) fill(other *Metadata) {
+	if m.Title == "" {
+		m.Title = other.Title
+	}
+	if len(m.Authors) == 0 {
+		m.Authors = other.Authors
+	}
+	if m.Publisher == "" {
+		m.Publisher = other.Publisher
+	}
+	if m.PageCount == 0 {
+		m.PageCount = other.PageCount
+	}
+	if m.PublishedDate == "" {
+		m.PublishedDate = other.PublishedDate
+	}
+	if m.CoverURL == "" {
+		m.CoverURL = other.CoverURL
+	}
+}
+
+// getJSON は url を GET して JSON を v に読む。接続できない・2xx 以外は ErrUnavailable を包んで返す。
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("%w: %s returned %s", ErrUnavailable, req.URL.Host, res.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("%w: invalid response from %s: %v", ErrUnavailable, req.URL.Host, err)
+	}
+	return nil
+}
+
+// httpsURL は表紙の http:// の URL を https:// にする。Google Books は https でも同じ画像を返すのに http:// の URL を返すので、混在コンテンツにならないようにそろえる。
+func httpsURL(u string) string {
+	if rest, ok := strings.CutPrefix(u, "http://"); ok {
+		return "https://" + rest
+	}
+	return u
+}
@@ -0,0 +1,138 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeProvider は決まった結果を返し、呼ばれた回数を数える。
+type fakeProvider struct {
+	m     *Metadata
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	m := *p.m
+	return &m, nil
+}
+
+func TestChain(t *testing.T) {
+	complete := &Metadata{Title: "T", Authors: []string{"A"}, Publisher: "P", PageCount: 100, CoverURL: "https://c/1.jpg", Source: "first"}
+	partial := &Metadata{Title: "T", Authors: []string{"A"}, Source: "first"}
+	other := &Metadata{Title: "Other title", Authors: []string{"B"}, Publisher: "Q", PageCount: 200, PublishedDate: "2020", CoverURL: "https://c/2.jpg", Source: "second"}
+	unavailable := fmt.Errorf("%w: example.com returned 503", ErrUnavailable)
+	tests := []struct {
+		name      string
+		providers []*fakeProvider
+		want      *Metadata
+		wantErr   error
+		calls     []int
+	}{
+		{
+			name:      "first complete result wins",
+			providers: []*fakeProvider{{m: complete}, {m: other}},
+			want:      complete,
+			calls:     []int{1, 0},
+		},
+		{
+			name:      "missing fields are filled by later providers",
+			providers: []*fakeProvider{{m: partial}, {m: other}},
+			want:      &Metadata{Title: "T", Authors: []string{"A"}, Publisher: "Q", PageCount: 200, PublishedDate: "2020", CoverURL: "https://c/2.jpg", Source: "first"},
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "falls back when not found",
+			providers: []*fakeProvider{{err: ErrNotFound}, {m: other}},
+			want:      other,
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "falls back when unavailable",
+			providers: []*fakeProvider{{err: unavailable}, {m: other}},
+			want:      other,
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "partial result is kept when the rest fail",
+			providers: []*fakeProvider{{m: partial}, {err: unavailable}},
+			want:      partial,
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "not found anywhere",
+			providers: []*fakeProvider{{err: ErrNotFound}, {err: ErrNotFound}},
+			wantErr:   ErrNotFound,
+			calls:     []int{1, 1},
+		},
+		{
+			name:      "unavailable wins over not found",
+			providers: []*fakeProvider{{err: ErrNotFound}, {err: unavailable}},
+			wantErr:   ErrUnavailable,
+			calls:     []int{1, 1},
+		},
+		{
+			name:    "no providers",
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var providers []MetadataProvider
+			for _, p := range tt.providers {
+				providers = append(providers, p)
+			}
+			got, err := NewChain(providers...).Lookup(context.Background(), "9780306406157")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			} else if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup = %+v, want %+v", got, tt.want)
+			}
+			for i, p := range tt.providers {
+				if p.calls != tt.calls[i] {
+					t.Errorf("provider %d called %d times, want %d", i, p.calls, tt.calls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewMetadataProvider(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    int
+		wantErr bool
+	}{
+		{env: "", want: 2},
+		{env: "openlibrary", want: 1},
+		{env: "googlebooks, openlibrary", want: 2},
+		{env: "amazon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("METADATA_PROVIDERS", tt.env)
+		p, err := NewMetadataProvider()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("METADATA_PROVIDERS=%q: err = nil, want an error", tt.env)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("METADATA_PROVIDERS=%q: %v", tt.env, err)
+		}
+		if n := len(p.(*Chain).providers); n != tt.want {
+			t.Errorf("METADATA_PROVIDERS=%q: %d providers, want %d", tt.env, n, tt.want)
+		}
+	}
+}
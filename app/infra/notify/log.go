@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier はリマインダーをログに出すだけの実装（ローカル開発用）。
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("reminder: user %d: %s", notification.UserID, notification.Subject())
+	for _, b := range notification.Books {
+		log.Printf("reminder: user %d:   book %d %q: %d / %d pages", notification.UserID, b.BookID, b.Title, b.PagesRead, b.TargetPagesPerDay)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notifier はリマインダーの送り先。ログ・Webhook・メール（SMTP）を環境変数で切り替える。
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notification は1人のユーザーに送る、その日のリマインダー。
+type Notification struct {
+	UserID int                `json:"userId"`
+	Email  string             `json:"email"`
+	Name   string             `json:"name"`
+	Date   string             `json:"date"` // ユーザーのタイムゾーンで見た日付（YYYY-MM-DD）
+	Books  []NotificationBook `json:"books"`
+}
+
+// NotificationBook は目標ページ数/日に届いていない本。
+type NotificationBook struct {
+	BookID            int    `json:"bookId"`
+	Title             string `json:"title"`
+	TargetPagesPerDay int    `json:"targetPagesPerDay"`
+	PagesRead         int    `json:"pagesRead"` // その日に読んだページ数
+}
+
+// Subject はメールの件名・ログの見出し。
+func (n Notification) Subject() string {
+	return fmt.Sprintf("%s の読書: 目標に届いていない本が %d 冊あります", n.Date, len(n.Books))
+}
+
+// Text はメールの本文。
+func (n Notification) Text() string {
+	var b strings.Builder
+	if n.Name != "" {
+		fmt.Fprintf(&b, "%s さん\n\n", n.Name)
+	}
+	fmt.Fprintf(&b, "%s はまだ目標のページ数を読めていない本があります。\n\n", n.Date)
+	for _, book := range n.Books {
+		fmt.Fprintf(&b, "・%s: %d / %d ページ\n", book.Title, book.PagesRead, book.TargetPagesPerDay)
+	}
+	return b.String()
+}
+
+// NewNotifier は環境変数 REMINDER_NOTIFIER（log / webhook / smtp、既定は log）で選んだ実装を返す。
+//
+// webhook: REMINDER_WEBHOOK_URL に JSON を POST する。REMINDER_WEBHOOK_SECRET を設定すると本文の HMAC-SHA256 を X-Signature-256 に付ける。
+// smtp: SMTP_HOST / SMTP_PORT（既定 587）/ SMTP_USERNAME / SMTP_PASSWORD / SMTP_FROM で送る。SMTP_USERNAME が空なら認証しない。
+func NewNotifier() (Notifier, error) {
+	switch os.Getenv("REMINDER_NOTIFIER") {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "webhook":
+		url := os.Getenv("REMINDER_WEBHOOK_URL")
+		if url == "" {
+			return nil, errors.New("REMINDER_WEBHOOK_URL is required")
+		}
+		return NewWebhookNotifier(url, os.Getenv("REMINDER_WEBHOOK_SECRET"), 10*time.Second), nil
+	case "smtp":
+		port := 587
+		if s := os.Getenv("SMTP_PORT"); s != "" {
+			p, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SMTP_PORT: %q", s)
+			}
+			port = p
+		}
+		return NewSMTPNotifier(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		})
+	default:
+		return nil, errors.New("REMINDER_NOTIFIER must be log, webhook, or smtp")
+	}
+}
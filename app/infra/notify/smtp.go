@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig は SMTPNotifier の接続先。Username が空なら認証しない（ローカルの SMTP サーバー向け）。
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier はリマインダーをユーザーのメールアドレスにプレーンテキストのメールで送る。
+// サーバーが STARTTLS に対応していれば net/smtp が自動で使う。
+type SMTPNotifier struct {
+	cfg  SMTPConfig
+	addr string
+	auth smtp.Auth
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) (*SMTPNotifier, error) {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil, errors.New("smtp: SMTP_HOST and SMTP_FROM are required")
+	}
+	n := &SMTPNotifier{cfg: cfg, addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))}
+	if cfg.Username != "" {
+		n.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return n, nil
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return errors.New("smtp: user has no email address")
+	}
+	msg, err := n.message(notification)
+	if err != nil {
+		return err
+	}
+	// net/smtp は context を受け取らないので、止めるときは送り終わるのを待たずに戻る
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.addr, n.auth, n.cfg.From, []string{notification.Email}, msg)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *SMTPNotifier) message(notification Notification) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", notification.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", notification.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(&b)
+	if _, err := w.Write([]byte(notification.Text())); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,215 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP は1通だけ受け取るテスト用の SMTP サーバー。AUTH PLAIN だけに対応し、STARTTLS は出さない。
+type fakeSMTP struct {
+	addr       string
+	rejectRcpt bool
+
+	from, to string
+	auth     string // AUTH PLAIN で受け取った資格情報（base64 を戻したもの）
+	data     string
+	done     chan struct{}
+}
+
+func startFakeSMTP(t *testing.T, rejectRcpt bool) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	s := &fakeSMTP{addr: ln.Addr().String(), rejectRcpt: rejectRcpt, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		s.serve(conn)
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case cmd == "EHLO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case cmd == "AUTH":
+			cred, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.auth = string(cred)
+			reply("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			if s.rejectRcpt {
+				reply("550 5.1.1 No such user")
+				continue
+			}
+			s.to = strings.Trim(line[len("RCPT TO:"):], "<>")
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data = b.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func newTestSMTPNotifier(t *testing.T, s *fakeSMTP, username string) *SMTPNotifier {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(s.addr)
+	p, _ := strconv.Atoi(port)
+	n, err := NewSMTPNotifier(SMTPConfig{Host: host, Port: p, Username: username, Password: "pass", From: "reminder@example.com"})
+	if err != nil {
+		t.Fatalf("NewSMTPNotifier: %v", err)
+	}
+	return n
+}
+
+func testNotification() Notification {
+	return Notification{
+		UserID: 1,
+		Email:  "reader@example.com",
+		Name:   "読者",
+		Date:   "2024-06-10",
+		Books: []NotificationBook{
+			{BookID: 1, Title: "吾輩は猫である", TargetPagesPerDay: 20, PagesRead: 5},
+			{BookID: 2, Title: "Dune", TargetPagesPerDay: 30, PagesRead: 0},
+		},
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantAuth string
+	}{
+		{name: "without auth"},
+		{name: "with auth", username: "user", wantAuth: "\x00user\x00pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := startFakeSMTP(t, false)
+			if err := newTestSMTPNotifier(t, s, tt.username).Notify(context.Background(), testNotification()); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			<-s.done
+			if s.from != "reminder@example.com" || s.to != "reader@example.com" {
+				t.Errorf("envelope = %s → %s, want reminder@example.com → reader@example.com", s.from, s.to)
+			}
+			if s.auth != tt.wantAuth {
+				t.Errorf("auth = %q, want %q", s.auth, tt.wantAuth)
+			}
+
+			msg, err := mail.ReadMessage(strings.NewReader(s.data))
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil {
+				t.Fatalf("decode Subject: %v", err)
+			}
+			if want := testNotification().Subject(); subject != want {
+				t.Errorf("Subject = %q, want %q", subject, want)
+			}
+			if got := msg.Header.Get("To"); got != "reader@example.com" {
+				t.Errorf("To = %q", got)
+			}
+			body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+			if err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			for _, want := range []string{"読者 さん", "・吾輩は猫である: 5 / 20 ページ", "・Dune: 0 / 30 ページ"} {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierErrors(t *testing.T) {
+	t.Run("no email address", func(t *testing.T) {
+		s := startFakeSMTP(t, false)
+		n := testNotification()
+		n.Email = ""
+		if err := newTestSMTPNotifier(t, s, "").Notify(context.Background(), n); err == nil {
+			t.Error("Notify: err = nil, want an error")
+		}
+	})
+	t.Run("recipient rejected", func(t *testing.T) {
+		s := startFakeSMTP(t, true)
+		err := newTestSMTPNotifier(t, s, "").Notify(context.Background(), testNotification())
+		if err == nil || !strings.Contains(err.Error(), "550") {
+			t.Errorf("Notify: err = %v, want the 550 reply", err)
+		}
+	})
+	t.Run("context canceled", func(t *testing.T) {
+		// 接続を受けても挨拶を返さないサーバー
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		defer ln.Close()
+		go func() {
+			conn, err := ln.Accept()
+			if err == nil {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err = newTestSMTPNotifier(t, &fakeSMTP{addr: ln.Addr().String()}, "").Notify(ctx, testNotification())
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Notify: err = %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
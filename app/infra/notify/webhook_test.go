@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		wantErr bool
+	}{
+		{name: "without secret", status: http.StatusNoContent},
+		{name: "with secret", secret: "s3cret", status: http.StatusOK},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+		{name: "3xx is a failure", status: http.StatusNotModified, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var header http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				body, _ = io.ReadAll(r.Body)
+				header = r.Header.Clone()
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewWebhookNotifier(srv.URL+"/hook", tt.secret, time.Second).Notify(context.Background(), testNotification())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Notify: err = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			if got := header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			var payload struct {
+				Type string `json:"type"`
+				Notification
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("body is not JSON: %v\n%s", err, body)
+			}
+			if payload.Type != "daily_reminder" {
+				t.Errorf("type = %q, want daily_reminder", payload.Type)
+			}
+			if !reflect.DeepEqual(payload.Notification, testNotification()) {
+				t.Errorf("notification = %+v, want %+v", payload.Notification, testNotification())
+			}
+
+			signature := header.Get("X-Signature-256")
+			if tt.secret == "" {
+				if signature != "" {
+					t.Errorf("X-Signature-256 = %q, want none without a secret", signature)
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write(body)
+			if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+				t.Errorf("X-Signature-256 = %q, want %q", signature, want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	if err := NewWebhookNotifier(srv.URL, "", 50*time.Millisecond).Notify(context.Background(), testNotification()); err == nil {
+		t.Error("Notify: err = nil, want a timeout")
+	}
+}
@@ -0,0 +1,423 @@
+// Package openapi は request / response の Go の型から OpenAPI 3.1 のドキュメントを組み立てる。
+// フィールド名・型は json タグと reflect で読むので、構造体を変えればドキュメントも変わる。
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation は1つのルート（メソッド + パス）の説明。
+// Request / Response には JSON の型のゼロ値を渡す（例: request.BookCreateForm{}）。
+type Operation struct {
+	Method      string
+	Path        string // chi と同じ書き方（/api/books/{id}）
+	Summary     string
+	Description string
+	Tag         string
+	Public      bool // true ならログイン不要
+	Params      []Param
+
+	Request       any
+	RequestForm   []FormField // multipart/form-data のとき
+	RequestFormat string      // Request の Content-Type。空なら application/json
+
+	Response       any
+	ResponseStatus int    // 空なら 200。Response が nil なら本文なし
+	ResponseFormat string // Response の Content-Type。空なら application/json
+
+	Errors map[int]string // ステータスコード → どういうときに返すか
+}
+
+// Param はパス・クエリ・ヘッダーのパラメータ。
+type Param struct {
+	Name        string
+	In          string // path / query / header
+	Description string
+	Type        string // string / integer。空なら string
+	Enum        []string
+	Required    bool
+}
+
+// FormField は multipart/form-data の項目。Binary なら file。
+type FormField struct {
+	Name        string
+	Description string
+	Binary      bool
+	Required    bool
+}
+
+// Spec は OpenAPI ドキュメントのビルダー。
+type Spec struct {
+	title   string
+	version string
+	ops     []Operation
+	enums   map[reflect.Type][]string
+	schemas map[string]any
+	names   map[reflect.Type]string
+
+	errType   any
+	errFormat string
+}
+
+func NewSpec(title, version string) *Spec {
+	return &Spec{
+		title:   title,
+		version: version,
+		enums:   map[reflect.Type][]string{},
+		schemas: map[string]any{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Enum は string の型が取りうる値を登録する（例: entity.Status）。
+func (s *Spec) Enum(v any, values ...string) {
+	s.enums[reflect.TypeOf(v)] = values
+}
+
+// Add はルートを追加する。
+func (s *Spec) Add(ops ...Operation) {
+	s.ops = append(s.ops, ops...)
+}
+
+// Operations は追加したルートを返す。
+func (s *Spec) Operations() []Operation {
+	return s.ops
+}
+
+// MarshalJSON は OpenAPI 3.1 の JSON を返す。
+func (s *Spec) MarshalJSON() ([]byte, error) {
+	s.schemas = map[string]any{}
+	s.names = map[reflect.Type]string{}
+	paths := map[string]map[string]any{}
+	for _, op := range s.ops {
+		item, ok := paths[op.Path]
+		if !ok {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = s.operation(op)
+	}
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   s.title,
+			"version": s.version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": s.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "POST /api/auth/login（または signup）で返る token",
+				},
+			},
+		},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+	}
+	return json.Marshal(doc)
+}
+
+func (s *Spec) operation(op Operation) map[string]any {
+	o := map[string]any{
+		"summary":     op.Summary,
+		"operationId": operationID(op),
+	}
+	if op.Description != "" {
+		o["description"] = op.Description
+	}
+	if op.Tag != "" {
+		o["tags"] = []string{op.Tag}
+	}
+	if op.Public {
+		o["security"] = []any{}
+	}
+	if params := s.params(op); len(params) > 0 {
+		o["parameters"] = params
+	}
+	if body := s.requestBody(op); body != nil {
+		o["requestBody"] = body
+	}
+
+	responses := map[string]any{}
+	status := op.ResponseStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := map[string]any{"description": http.StatusText(status)}
+	if op.Response != nil {
+		format := op.ResponseFormat
+		if format == "" {
+			format = "application/json"
+		}
+		if strings.HasPrefix(format, "image/") {
+			// 画像はバイナリなので schema を書かない（3.1 ではメディアタイプだけで表す）
+			ok["content"] = map[string]any{format: map[string]any{}}
+		} else {
+			ok["content"] = map[string]any{format: map[string]any{"schema": s.schema(reflect.TypeOf(op.Response), true)}}
+		}
+	}
+	responses[strconv.Itoa(status)] = ok
+	for code, desc := range op.Errors {
+		responses[strconv.Itoa(code)] = s.errorResponse(code, desc)
+	}
+	if !op.Public {
+		if _, ok := op.Errors[http.StatusUnauthorized]; !ok {
+			responses["401"] = s.errorResponse(http.StatusUnauthorized, "unauthenticated: トークンがない・期限切れ・失効済み")
+		}
+	}
+	if _, ok := op.Errors[http.StatusInternalServerError]; !ok {
+		responses["500"] = s.errorResponse(http.StatusInternalServerError, "internal_error: サーバー内部のエラー")
+	}
+	o["responses"] = responses
+	return o
+}
+
+// ErrorType はエラーの本文の型（problem+json）を登録する。未登録ならエラーは text/plain の文字列。
+func (s *Spec) ErrorType(v any, contentType string) {
+	s.errType = v
+	s.errFormat = contentType
+}
+
+func (s *Spec) errorResponse(code int, desc string) map[string]any {
+	content := map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}}
+	if s.errType != nil {
+		content = map[string]any{s.errFormat: map[string]any{"schema": s.schema(reflect.TypeOf(s.errType), true)}}
+	}
+	return map[string]any{
+		"description": http.StatusText(code) + ": " + desc,
+		"content":     content,
+	}
+}
+
+func (s *Spec) params(op Operation) []any {
+	var params []any
+	// パスの {name} は Params になくても必須のパラメータとして出す
+	declared := map[string]bool{}
+	for _, p := range op.Params {
+		declared[p.In+":"+p.Name] = true
+	}
+	for _, name := range pathParams(op.Path) {
+		if !declared["path:"+name] {
+			params = append(params, s.param(Param{Name: name, In: "path", Required: true}))
+		}
+	}
+	for _, p := range op.Params {
+		if p.In == "path" {
+			p.Required = true
+		}
+		params = append(params, s.param(p))
+	}
+	return params
+}
+
+func (s *Spec) param(p Param) map[string]any {
+	typ := p.Type
+	if typ == "" {
+		typ = "string"
+	}
+	schema := map[string]any{"type": typ}
+	if len(p.Enum) > 0 {
+		schema["enum"] = p.Enum
+	}
+	m := map[string]any{
+		"name":     p.Name,
+		"in":       p.In,
+		"required": p.Required,
+		"schema":   schema,
+	}
+	if p.Description != "" {
+		m["description"] = p.Description
+	}
+	return m
+}
+
+func (s *Spec) requestBody(op Operation) map[string]any {
+	if len(op.RequestForm) > 0 {
+		props := map[string]any{}
+		var required []string
+		for _, f := range op.RequestForm {
+			p := map[string]any{"type": "string"}
+			if f.Binary {
+				p["contentMediaType"] = "application/octet-stream"
+			}
+			if f.Description != "" {
+				p["description"] = f.Description
+			}
+			props[f.Name] = p
+			if f.Required {
+				required = append(required, f.Name)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return map[string]any{
+			"required": true,
+			"content":  map[string]any{"multipart/form-data": map[string]any{"schema": schema}},
+		}
+	}
+	if op.Request == nil {
+		return nil
+	}
+	format := op.RequestFormat
+	if format == "" {
+		format = "application/json"
+	}
+	return map[string]any{
+		"required": true,
+		"content":  map[string]any{format: map[string]any{"schema": s.schema(reflect.TypeOf(op.Request), false)}},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// dateType は YYYY-MM-DD の文字列として読み書きする型（request.NormalizedDate など）。
+type dateType interface {
+	Time() time.Time
+}
+
+// schema は Go の型を JSON Schema にする。名前のある構造体は components/schemas に入れて $ref で参照する。
+// response のとき（omitempty でない項目は必ず返る）は required を付ける。
+func (s *Spec) schema(t reflect.Type, response bool) any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	var schema map[string]any
+	switch {
+	case t == timeType:
+		schema = map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Implements(reflect.TypeOf((*dateType)(nil)).Elem()):
+		schema = map[string]any{"type": "string", "format": "date"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		// 構造体の参照は null にしない（ポインタでも nil を返さない）
+		return map[string]any{"$ref": "#/components/schemas/" + s.component(t, response)}
+	default:
+		schema = s.inline(t, response)
+	}
+	if nullable {
+		schema["type"] = []any{schema["type"], "null"}
+	}
+	return schema
+}
+
+func (s *Spec) inline(t reflect.Type, response bool) map[string]any {
+	if values, ok := s.enums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": s.schema(t.Elem(), response)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem(), response)}
+	case reflect.Struct:
+		return s.object(t, response)
+	}
+	return map[string]any{}
+}
+
+// component は名前のある構造体を components/schemas に登録して名前を返す。
+// 別のパッケージに同じ名前の型があるときは「パッケージ名.型名」にする。
+func (s *Spec) component(t reflect.Type, response bool) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := s.schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	s.names[t] = name
+	s.schemas[name] = map[string]any{} // 自己参照に備えて先に場所を取る
+	s.schemas[name] = s.object(t, response)
+	return name
+}
+
+func (s *Spec) object(t reflect.Type, response bool) map[string]any {
+	props := map[string]any{}
+	var required []string
+	s.fields(t, response, props, &required)
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields は埋め込みの構造体を展開しながら json タグの付いた項目を集める（encoding/json と同じ見え方）。
+func (s *Spec) fields(t reflect.Type, response bool, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, response, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = s.schema(f.Type, response)
+		if response && !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func pathParams(p string) []string {
+	var names []string
+	for {
+		start := strings.Index(p, "{")
+		if start < 0 {
+			return names
+		}
+		end := strings.Index(p[start:], "}")
+		if end < 0 {
+			return names
+		}
+		names = append(names, p[start+1:start+end])
+		p = p[start+end+1:]
+	}
+}
+
+// operationID は GET /api/books/{id}/sessions → getApiBooksIdSessions のような ID を作る。
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return r == '/' || r == '{' || r == '}' || r == '-' || r == '.' || r == ':' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
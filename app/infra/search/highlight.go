@@ -0,0 +1,96 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+)
+
+// 長い項目（encounterNote など）は最初に当たった箇所の前後だけを返す
+const (
+	snippetBefore = 20
+	snippetLength = 100
+)
+
+type span struct{ start, end int } // 元の文字列の文字（rune）の範囲
+
+// highlight は当たった項目ごとに、当たった箇所を <mark> で囲んだ HTML を返す（それ以外の文字はエスケープする）。
+func highlight(d *indexedDoc, terms []queryTerm) map[string]string {
+	out := map[string]string{}
+	for i, f := range d.Fields {
+		spans := matchSpans(d.texts[i], terms)
+		if len(spans) == 0 {
+			continue
+		}
+		out[f.Name] = markup([]rune(f.Text), spans)
+	}
+	return out
+}
+
+func matchSpans(text normalized, terms []queryTerm) []span {
+	var spans []span
+	for _, t := range terms {
+		needle := []rune(t.text)
+		for i := 0; i+len(needle) <= len(text.runes); i++ {
+			if !hasPrefixAt(text.runes, needle, i) {
+				continue
+			}
+			spans = append(spans, span{start: text.src[i].start, end: text.src[i+len(needle)-1].end})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	// 重なる・隣り合う範囲はまとめる
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func hasPrefixAt(runes, needle []rune, at int) bool {
+	for j, r := range needle {
+		if runes[at+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func markup(text []rune, spans []span) string {
+	from, to := 0, len(text)
+	if len(text) > snippetLength {
+		from = max(spans[0].start-snippetBefore, 0)
+		to = min(from+snippetLength, len(text))
+	}
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(html.EscapeString(string(text[pos:start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(text[start:end])))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(text[pos:to])))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	long := strings.Repeat("あ", 50) + "猫" + strings.Repeat("い", 100)
+	tests := []struct {
+		name string
+		text string
+		q    string
+		want string
+	}{
+		{name: "marks the match", text: "ガラスの街", q: "ガラス", want: "<mark>ガラス</mark>の街"},
+		{name: "marks the original characters of a normalized match", text: "ｶﾞﾗｽの街", q: "がらす", want: "<mark>ｶﾞﾗｽ</mark>の街"},
+		{name: "marks every occurrence", text: "猫と猫", q: "猫", want: "<mark>猫</mark>と<mark>猫</mark>"},
+		{name: "merges overlapping matches", text: "海辺のカフカ", q: "海辺 辺の", want: "<mark>海辺の</mark>カフカ"},
+		{name: "marks word prefixes", text: "Haruki Murakami", q: "haru", want: "<mark>Haru</mark>ki Murakami"},
+		{name: "escapes html", text: `<b>"Tom & Jerry"</b>`, q: "tom", want: `&lt;b&gt;&#34;<mark>Tom</mark> &amp; Jerry&#34;&lt;/b&gt;`},
+		{
+			name: "long text is cut around the first match",
+			text: long,
+			q:    "猫",
+			want: "…" + strings.Repeat("あ", 20) + "<mark>猫</mark>" + strings.Repeat("い", 79) + "…",
+		},
+		{
+			name: "match near the start is not prefixed",
+			text: "猫" + strings.Repeat("い", 150),
+			q:    "猫",
+			want: "<mark>猫</mark>" + strings.Repeat("い", 99) + "…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := newTestIndex("", Document{ID: 1, Fields: []Field{{Name: "title", Text: tt.text, Weight: 1}}})
+			hits := x.Search(testUser, tt.q, 0)
+			if len(hits) != 1 {
+				t.Fatalf("Search(%q) = %d hits, want 1", tt.q, len(hits))
+			}
+			if got := hits[0].Highlights["title"]; got != tt.want {
+				t.Errorf("highlight = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightOnlyMatchedFields(t *testing.T) {
+	x := newTestIndex("", doc(1, "ガラスの街", "古本屋で見つけた"))
+	hits := x.Search(testUser, "ガラス", 0)
+	if len(hits) != 1 {
+		t.Fatalf("Search = %d hits, want 1", len(hits))
+	}
+	if _, ok := hits[0].Highlights["encounterNote"]; ok {
+		t.Errorf("Highlights = %v, want only the title", hits[0].Highlights)
+	}
+}
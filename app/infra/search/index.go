@@ -0,0 +1,395 @@
+// Package search は本の全文検索用の転置インデックス。ユーザーごとに分けてメモリに持ち、
+// ファイルにスナップショットを書いておいて起動時に読み込む。
+// インデックスはプロセスごとなので、ほかのインスタンスでの本の変更は反映されない。
+// 複数のインスタンスで動かすときは maxAge を指定し、古くなったユーザーのインデックスを本の保存先から作り直す。
+// 日本語は分かち書きせず、CJK の文字の連なりを uni-gram / bi-gram に分けて引く。
+package search
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BM25 のパラメータ
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field は文書の項目。Weight が大きい項目に当たるほど上位になる。
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Document はインデックスに入れる文書（本1冊）。
+type Document struct {
+	ID     int
+	Fields []Field
+}
+
+// Hit は検索結果の1件。Highlights は当たった項目だけを、当たった箇所を <mark> で囲んだ HTML で持つ。
+type Hit struct {
+	ID         int
+	Score      float64
+	Highlights map[string]string
+}
+
+type Index struct {
+	mu     sync.RWMutex
+	users  map[int]*userIndex
+	path   string        // 空ならファイルに書かない
+	maxAge time.Duration // 0 なら作り直さない
+	dirty  bool
+	now    func() time.Time
+}
+
+// NewIndex は空のインデックスを返す。path にスナップショットを読み書きする（Load / Save）。
+// maxAge を過ぎたユーザーのインデックスは Fresh が false を返すので、呼び出し側で作り直す。
+func NewIndex(path string, maxAge time.Duration) *Index {
+	return &Index{users: map[int]*userIndex{}, path: path, maxAge: maxAge, now: time.Now}
+}
+
+// Fresh はそのユーザーのインデックスがあり、作ってから maxAge を過ぎていないか。false なら呼び出し側で Replace して作り直す。
+func (x *Index) Fresh(userID int) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	u, ok := x.users[userID]
+	if !ok {
+		return false
+	}
+	return x.maxAge == 0 || x.now().Sub(u.builtAt) < x.maxAge
+}
+
+// Put は文書を追加する（同じ ID があれば置き換える）。ユーザーのインデックスがまだなければ何もしない（あとで Replace で作る）。
+func (x *Index) Put(userID int, doc Document) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	u, ok := x.users[userID]
+	if !ok {
+		return
+	}
+	u.put(doc)
+	x.dirty = true
+}
+
+func (x *Index) Delete(userID, docID int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	u, ok := x.users[userID]
+	if !ok {
+		return
+	}
+	u.delete(docID)
+	x.dirty = true
+}
+
+// Replace はユーザーのインデックスを docs で作り直す。
+func (x *Index) Replace(userID int, docs []Document) {
+	u := newUserIndex(x.now())
+	for _, d := range docs {
+		u.put(d)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.users[userID] = u
+	x.dirty = true
+}
+
+// ReplaceAll はすべてのユーザーのインデックスを作り直す（管理コマンドの reindex 用）。
+func (x *Index) ReplaceAll(docs map[int][]Document) {
+	x.replaceAll(docs, x.now())
+}
+
+func (x *Index) replaceAll(docs map[int][]Document, builtAt time.Time) {
+	users := make(map[int]*userIndex, len(docs))
+	for userID, ds := range docs {
+		u := newUserIndex(builtAt)
+		for _, d := range ds {
+			u.put(d)
+		}
+		users[userID] = u
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.users = users
+	x.dirty = true
+}
+
+// Search は q のすべての語を含む文書をスコアの高い順に最大 limit 件返す。
+func (x *Index) Search(userID int, q string, limit int) []Hit {
+	terms := parseQuery(q)
+	if len(terms) == 0 {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	u, ok := x.users[userID]
+	if !ok {
+		return nil
+	}
+	hits := u.search(terms)
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// Load はスナップショットを読み込む。ファイルがなければ空のまま（各ユーザーの初回検索で作る）。
+// 読み込んだインデックスはファイルを書いた日時に作ったものとして扱う。
+func (x *Index) Load() error {
+	if x.path == "" {
+		return nil
+	}
+	f, err := os.Open(x.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	var snapshot map[int][]Document
+	if err := gob.NewDecoder(f).Decode(&snapshot); err != nil {
+		return err
+	}
+	x.replaceAll(snapshot, info.ModTime())
+	x.mu.Lock()
+	x.dirty = false
+	x.mu.Unlock()
+	return nil
+}
+
+// Save はスナップショットを書く。途中で落ちても壊れたファイルが残らないよう一時ファイルから rename する。
+func (x *Index) Save() error {
+	if x.path == "" {
+		return nil
+	}
+	x.mu.Lock()
+	snapshot := make(map[int][]Document, len(x.users))
+	for userID, u := range x.users {
+		docs := make([]Document, 0, len(u.docs))
+		for _, d := range u.docs {
+			docs = append(docs, d.Document)
+		}
+		snapshot[userID] = docs
+	}
+	x.dirty = false
+	x.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(x.path), ".index-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(snapshot); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), x.path)
+}
+
+// RunAutoSave は interval ごとに、変更があればスナップショットを書く。ctx が終わるまで戻らない。
+func (x *Index) RunAutoSave(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			x.mu.RLock()
+			dirty := x.dirty
+			x.mu.RUnlock()
+			if !dirty {
+				continue
+			}
+			if err := x.Save(); err != nil {
+				log.Printf("search: save index: %v", err)
+			}
+		}
+	}
+}
+
+// ---
+
+type indexedDoc struct {
+	Document
+	texts []normalized // Fields と同じ順。フレーズの確認とハイライトに使う
+	lens  []int        // 項目ごとのトークン数
+}
+
+type userIndex struct {
+	docs     map[int]*indexedDoc
+	postings map[string]map[int][]int // トークン → 文書 ID → 項目ごとの出現回数
+	lenSum   map[string]int           // 項目名 → トークン数の合計（平均の長さに使う）
+	builtAt  time.Time                // 本の保存先から作った日時。Put / Delete では変わらない
+}
+
+func newUserIndex(builtAt time.Time) *userIndex {
+	return &userIndex{
+		builtAt:  builtAt,
+		docs:     map[int]*indexedDoc{},
+		postings: map[string]map[int][]int{},
+		lenSum:   map[string]int{},
+	}
+}
+
+func (u *userIndex) put(doc Document) {
+	u.delete(doc.ID)
+	d := &indexedDoc{Document: doc}
+	for i, f := range doc.Fields {
+		d.texts = append(d.texts, normalize(f.Text))
+		tokens := indexTokens(f.Text)
+		d.lens = append(d.lens, len(tokens))
+		u.lenSum[f.Name] += len(tokens)
+		for _, t := range tokens {
+			p, ok := u.postings[t]
+			if !ok {
+				p = map[int][]int{}
+				u.postings[t] = p
+			}
+			tf, ok := p[doc.ID]
+			if !ok {
+				tf = make([]int, len(doc.Fields))
+				p[doc.ID] = tf
+			}
+			tf[i]++
+		}
+	}
+	u.docs[doc.ID] = d
+}
+
+func (u *userIndex) delete(docID int) {
+	d, ok := u.docs[docID]
+	if !ok {
+		return
+	}
+	for i, f := range d.Fields {
+		u.lenSum[f.Name] -= d.lens[i]
+		for _, t := range indexTokens(f.Text) {
+			if p, ok := u.postings[t]; ok {
+				delete(p, docID)
+				if len(p) == 0 {
+					delete(u.postings, t)
+				}
+			}
+		}
+	}
+	delete(u.docs, docID)
+}
+
+// expand は検索語のトークンを、インデックスにあるトークンに広げる（英数字は前方一致）。
+func (u *userIndex) expand(t queryTerm) [][]string {
+	groups := make([][]string, 0, len(t.tokens))
+	for _, tok := range t.tokens {
+		if !t.prefix {
+			groups = append(groups, []string{tok})
+			continue
+		}
+		var matched []string
+		for indexed := range u.postings {
+			if strings.HasPrefix(indexed, tok) {
+				matched = append(matched, indexed)
+			}
+		}
+		groups = append(groups, matched)
+	}
+	return groups
+}
+
+func (u *userIndex) search(terms []queryTerm) []Hit {
+	scores := map[int]float64{}
+	var candidates map[int]bool
+	for _, term := range terms {
+		// この語のトークンのグループすべてに当たる文書だけを残す
+		for _, group := range u.expand(term) {
+			matched := map[int]bool{}
+			for _, tok := range group {
+				for docID, tf := range u.postings[tok] {
+					if candidates != nil && !candidates[docID] {
+						continue
+					}
+					matched[docID] = true
+					scores[docID] += u.bm25(tok, docID, tf)
+				}
+			}
+			candidates = matched
+		}
+	}
+
+	hits := make([]Hit, 0, len(candidates))
+	for docID := range candidates {
+		d := u.docs[docID]
+		// bi-gram がばらばらに当たっただけ（フレーズとしては含まない）ものを除く
+		if !containsAll(d, terms) {
+			continue
+		}
+		hits = append(hits, Hit{ID: docID, Score: scores[docID], Highlights: highlight(d, terms)})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// bm25 は項目の重みを掛けた BM25F 風のスコア。
+func (u *userIndex) bm25(token string, docID int, tf []int) float64 {
+	n := float64(len(u.docs))
+	df := float64(len(u.postings[token]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	d := u.docs[docID]
+	var score float64
+	for i, f := range d.Fields {
+		if tf[i] == 0 {
+			continue
+		}
+		avg := float64(u.lenSum[f.Name]) / n
+		if avg == 0 {
+			avg = 1
+		}
+		t := float64(tf[i])
+		score += f.Weight * t * (bm25K1 + 1) / (t + bm25K1*(1-bm25B+bm25B*float64(d.lens[i])/avg))
+	}
+	return idf * score
+}
+
+// containsAll は検索語がどれもいずれかの項目に文字列として含まれるか。
+func containsAll(d *indexedDoc, terms []queryTerm) bool {
+	for _, t := range terms {
+		found := false
+		for _, text := range d.texts {
+			if strings.Contains(string(text.runes), t.text) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
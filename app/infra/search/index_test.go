@@ -0,0 +1,216 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testUser = 1
+
+func newTestIndex(path string, docs ...Document) *Index {
+	x := NewIndex(path, 0)
+	x.Replace(testUser, docs)
+	return x
+}
+
+func doc(id int, title, note string) Document {
+	return Document{ID: id, Fields: []Field{
+		{Name: "title", Text: title, Weight: 3},
+		{Name: "encounterNote", Text: note, Weight: 1},
+	}}
+}
+
+func hitIDs(hits []Hit) []int {
+	ids := make([]int, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	return ids
+}
+
+func TestSearchMatching(t *testing.T) {
+	x := newTestIndex("",
+		doc(1, "ガラスの街", ""),
+		doc(2, "海辺のカフカ", "Haruki Murakami"),
+		doc(3, "海の辺り", ""),
+		doc(4, "Go言語プログラミング", "ＧＯ入門"),
+	)
+	tests := []struct {
+		name string
+		q    string
+		want []int
+	}{
+		{name: "katakana", q: "ガラス", want: []int{1}},
+		{name: "hiragana finds katakana", q: "がらす", want: []int{1}},
+		{name: "half-width kana with voiced mark", q: "ｶﾞﾗｽ", want: []int{1}},
+		{name: "single cjk character", q: "街", want: []int{1}},
+		{name: "phrase, not scattered bi-grams", q: "海辺", want: []int{2}},
+		{name: "word prefix", q: "haru", want: []int{2}},
+		{name: "all terms must match", q: "海辺 haruki", want: []int{2}},
+		{name: "a term that matches nothing", q: "海辺 banana", want: []int{}},
+		{name: "full-width alphanumerics", q: "go", want: []int{4}},
+		{name: "empty query", q: " 、", want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitIDs(x.Search(testUser, tt.q, 0)); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.q, got, tt.want)
+			}
+		})
+	}
+	if hits := x.Search(testUser+1, "ガラス", 0); len(hits) != 0 {
+		t.Errorf("Search for another user = %v, want none", hitIDs(hits))
+	}
+}
+
+// TestSearchRanking は BM25F の順位を確かめる。重みの大きい項目・短い項目・多く出る語ほど上になる。
+func TestSearchRanking(t *testing.T) {
+	tests := []struct {
+		name string
+		docs []Document
+		q    string
+		want []int
+	}{
+		{
+			name: "title outranks note",
+			docs: []Document{doc(1, "犬の本", "猫"), doc(2, "猫の本", "犬")},
+			q:    "猫",
+			want: []int{2, 1},
+		},
+		{
+			name: "shorter field outranks longer",
+			docs: []Document{doc(1, "猫と犬と鳥と魚の長い話", ""), doc(2, "猫の話", "")},
+			q:    "猫",
+			want: []int{2, 1},
+		},
+		{
+			name: "more occurrences rank higher",
+			docs: []Document{doc(1, "本", "cat"), doc(2, "本", "cat cat cat")},
+			q:    "cat",
+			want: []int{2, 1},
+		},
+		{
+			name: "equal scores are ordered by ID",
+			docs: []Document{doc(3, "猫", ""), doc(1, "猫", "")},
+			q:    "猫",
+			want: []int{1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := newTestIndex("", tt.docs...)
+			if got := hitIDs(x.Search(testUser, tt.q, 0)); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.q, got, tt.want)
+			}
+		})
+	}
+
+	x := newTestIndex("", doc(1, "猫", ""), doc(2, "猫", ""), doc(3, "猫", ""))
+	if got := hitIDs(x.Search(testUser, "猫", 2)); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("Search with limit 2 = %v, want [1 2]", got)
+	}
+}
+
+func TestIndexPutDelete(t *testing.T) {
+	x := newTestIndex("", doc(1, "ガラスの街", ""))
+	x.Put(testUser, doc(1, "鍵のない夢", ""))
+	if got := hitIDs(x.Search(testUser, "ガラス", 0)); len(got) != 0 {
+		t.Errorf("Search for the replaced title = %v, want none", got)
+	}
+	if got := hitIDs(x.Search(testUser, "夢", 0)); !slices.Equal(got, []int{1}) {
+		t.Errorf("Search for the new title = %v, want [1]", got)
+	}
+	x.Delete(testUser, 1)
+	if got := hitIDs(x.Search(testUser, "夢", 0)); len(got) != 0 {
+		t.Errorf("Search after Delete = %v, want none", got)
+	}
+	// インデックスのないユーザーには Put しない（初回の検索で Replace して作る）
+	x.Put(testUser+1, doc(2, "夢", ""))
+	if x.Fresh(testUser + 1) {
+		t.Error("Put created an index for a user without one")
+	}
+}
+
+// TestIndexSnapshot はスナップショットを書いて読み直すと、同じ検索結果になることを確かめる。
+func TestIndexSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "search-index.gob")
+	x := newTestIndex(path, doc(1, "ガラスの街", "Paul Auster"), doc(2, "海辺のカフカ", ""))
+	x.Replace(testUser+1, []Document{doc(3, "ガラスの動物園", "")})
+	if err := x.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewIndex(path, 0)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, user := range []int{testUser, testUser + 1} {
+		if !loaded.Fresh(user) {
+			t.Errorf("Fresh(%d) = false after Load", user)
+		}
+		for _, q := range []string{"ガラス", "auster", "カフカ"} {
+			want, got := x.Search(user, q, 0), loaded.Search(user, q, 0)
+			if !slices.Equal(hitIDs(got), hitIDs(want)) {
+				t.Errorf("user %d Search(%q) after Load = %v, want %v", user, q, hitIDs(got), hitIDs(want))
+				continue
+			}
+			for i := range got {
+				if got[i].Score != want[i].Score || got[i].Highlights["title"] != want[i].Highlights["title"] {
+					t.Errorf("user %d Search(%q)[%d] after Load = %+v, want %+v", user, q, i, got[i], want[i])
+				}
+			}
+		}
+	}
+
+	if err := NewIndex(filepath.Join(t.TempDir(), "missing.gob"), 0).Load(); err != nil {
+		t.Errorf("Load of a missing file: %v", err)
+	}
+}
+
+// TestIndexMaxAge は maxAge を過ぎたユーザーのインデックスが Fresh でなくなり、Replace で作り直せば戻ることを確かめる。
+// Put / Delete は自分のインスタンスでの変更だけなので、作った日時を進めない。
+func TestIndexMaxAge(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	x := NewIndex("", time.Minute)
+	x.now = func() time.Time { return now }
+
+	x.Replace(testUser, []Document{doc(1, "ガラスの街", "")})
+	now = now.Add(59 * time.Second)
+	if !x.Fresh(testUser) {
+		t.Error("Fresh before maxAge = false")
+	}
+	x.Put(testUser, doc(2, "海辺のカフカ", ""))
+	now = now.Add(time.Second)
+	if x.Fresh(testUser) {
+		t.Error("Fresh after maxAge = true")
+	}
+	if got := hitIDs(x.Search(testUser, "カフカ", 0)); !slices.Equal(got, []int{2}) {
+		t.Errorf("Search on a stale index = %v, want [2]", got)
+	}
+	x.Replace(testUser, []Document{doc(1, "ガラスの街", "")})
+	if !x.Fresh(testUser) {
+		t.Error("Fresh after Replace = false")
+	}
+
+	// スナップショットから読んだインデックスは、ファイルを書いた日時から数える
+	path := filepath.Join(t.TempDir(), "search-index.gob")
+	x.path = path
+	if err := x.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	written := now.Add(-2 * time.Minute)
+	if err := os.Chtimes(path, written, written); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	loaded := NewIndex(path, time.Minute)
+	loaded.now = func() time.Time { return now }
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Fresh(testUser) {
+		t.Error("Fresh after loading an old snapshot = true")
+	}
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalized は検索用に正規化した文字列。src[i] は i 文字目が元の文字列のどの文字の範囲から来たか
+// （半角カナと濁点のように2文字が1文字になると、その2文字の範囲になる）。
+type normalized struct {
+	runes []rune
+	src   []span
+}
+
+// normalize は全角英数・半角カナを NFKC でそろえ、小文字にし、カタカナをひらがなにする。
+// NFKC は文字列全体に当てる。文字列を正規化の境界（濁点などの結合文字は前の文字と同じ側）で区切って区切りごとに変換するので、
+// 全体を一度に変換したのと同じ結果になり、変換後の文字がどの元の文字から来たかも分かる（ハイライトを元の文字列に戻すのに使う）。
+func normalize(s string) normalized {
+	var n normalized
+	start := 0 // 区切りの先頭が元の文字列の何文字目か
+	for len(s) > 0 {
+		size := norm.NFKC.NextBoundaryInString(s, true)
+		chunk := s[:size]
+		end := start + utf8.RuneCountInString(chunk)
+		for _, c := range norm.NFKC.String(chunk) {
+			c = unicode.ToLower(c)
+			if c >= 'ァ' && c <= 'ヶ' {
+				c -= 'ァ' - 'ぁ'
+			}
+			n.runes = append(n.runes, c)
+			n.src = append(n.src, span{start: start, end: end})
+		}
+		s, start = s[size:], end
+	}
+	return n
+}
+
+// isCJK は単語の区切りがない文字（漢字・ひらがな・カタカナ）か。
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー' || r == '々' || r == '〆'
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// segment は正規化した文字列の中の、CJK の連なり・英数字の単語1つ分。
+type segment struct {
+	start, end int // normalized.runes の範囲
+	cjk        bool
+}
+
+func segments(runes []rune) []segment {
+	var segs []segment
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !isCJK(r) && !isWordRune(r) {
+			i++
+			continue
+		}
+		cjk := isCJK(r)
+		j := i + 1
+		for j < len(runes) && isCJK(runes[j]) == cjk && (cjk || isWordRune(runes[j])) {
+			j++
+		}
+		segs = append(segs, segment{start: i, end: j, cjk: cjk})
+		i = j
+	}
+	return segs
+}
+
+// indexTokens は文書に付けるトークン。英数字は単語ごと、CJK は1文字（uni-gram）と2文字（bi-gram）の両方。
+// 1文字だけの検索語でも引けるように uni-gram も入れておく。
+func indexTokens(text string) []string {
+	n := normalize(text)
+	var tokens []string
+	for _, seg := range segments(n.runes) {
+		if !seg.cjk {
+			tokens = append(tokens, string(n.runes[seg.start:seg.end]))
+			continue
+		}
+		for i := seg.start; i < seg.end; i++ {
+			tokens = append(tokens, string(n.runes[i]))
+			if i+1 < seg.end {
+				tokens = append(tokens, string(n.runes[i:i+2]))
+			}
+		}
+	}
+	return tokens
+}
+
+// queryTerm は検索語の1単位。CJK は連なり全体の bi-gram（1文字なら uni-gram）をすべて含む文書に当たる。
+// 英数字の単語は前方一致（"haru" で "haruki" に当たる）。
+type queryTerm struct {
+	text   string   // ハイライトに使う正規化済みの文字列
+	tokens []string // 引くトークン
+	prefix bool
+}
+
+func parseQuery(q string) []queryTerm {
+	n := normalize(q)
+	var terms []queryTerm
+	for _, seg := range segments(n.runes) {
+		text := string(n.runes[seg.start:seg.end])
+		if !seg.cjk {
+			terms = append(terms, queryTerm{text: text, tokens: []string{text}, prefix: true})
+			continue
+		}
+		t := queryTerm{text: text}
+		if seg.end-seg.start == 1 {
+			t.tokens = []string{text}
+		}
+		for i := seg.start; i+1 < seg.end; i++ {
+			t.tokens = append(t.tokens, string(n.runes[i:i+2]))
+		}
+		terms = append(terms, t)
+	}
+	return terms
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		src  []span // 変換後の各文字の元の範囲。nil なら確かめない
+	}{
+		{name: "katakana to hiragana", in: "ガラス", want: "がらす"},
+		{name: "half-width kana with voiced mark", in: "ｶﾞﾗｽ", want: "がらす", src: []span{{0, 2}, {2, 3}, {3, 4}}},
+		{name: "half-width kana with semi-voiced mark", in: "ﾊﾟﾝ", want: "ぱん", src: []span{{0, 2}, {2, 3}}},
+		{name: "full-width alphanumerics", in: "ＧＯ１２", want: "go12"},
+		{name: "upper case", in: "Haruki", want: "haruki"},
+		{name: "compatibility character expands", in: "㍻", want: "平成", src: []span{{0, 1}, {0, 1}}},
+		{name: "long vowel mark is kept", in: "コーヒー", want: "こーひー"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := normalize(tt.in)
+			if got := string(n.runes); got != tt.want {
+				t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if len(n.src) != len(n.runes) {
+				t.Fatalf("len(src) = %d, want %d", len(n.src), len(n.runes))
+			}
+			if tt.src != nil && !slices.Equal(n.src, tt.src) {
+				t.Errorf("src = %v, want %v", n.src, tt.src)
+			}
+		})
+	}
+}
+
+func TestIndexTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "cjk uni-grams and bi-grams", in: "海辺の", want: []string{"海", "海辺", "辺", "辺の", "の"}},
+		{name: "single cjk character", in: "猫", want: []string{"猫"}},
+		{name: "words", in: "Kafka on the Shore", want: []string{"kafka", "on", "the", "shore"}},
+		{name: "mixed scripts split into segments", in: "Go言語", want: []string{"go", "言", "言語", "語"}},
+		{name: "punctuation separates cjk runs", in: "春、夏", want: []string{"春", "夏"}},
+		{name: "half-width kana", in: "ｶﾞﾗｽ", want: []string{"が", "がら", "ら", "らす", "す"}},
+		{name: "empty", in: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexTokens(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("indexTokens(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []queryTerm
+	}{
+		{name: "cjk phrase is bi-grams", in: "海辺の", want: []queryTerm{{text: "海辺の", tokens: []string{"海辺", "辺の"}}}},
+		{name: "single cjk character is a uni-gram", in: "猫", want: []queryTerm{{text: "猫", tokens: []string{"猫"}}}},
+		{name: "words are prefixes", in: "Haru Mura", want: []queryTerm{
+			{text: "haru", tokens: []string{"haru"}, prefix: true},
+			{text: "mura", tokens: []string{"mura"}, prefix: true},
+		}},
+		{name: "half-width kana matches full-width", in: "ｶﾞﾗｽ", want: []queryTerm{{text: "がらす", tokens: []string{"がら", "らす"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseQuery(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseQuery(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			for i := range got {
+				if got[i].text != tt.want[i].text || got[i].prefix != tt.want[i].prefix || !slices.Equal(got[i].tokens, tt.want[i].tokens) {
+					t.Errorf("parseQuery(%q)[%d] = %+v, want %+v", tt.in, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
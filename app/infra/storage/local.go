@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localThumbnailStore はローカルディスクに保存する ThumbnailStore。1台で動かすときと開発用。
+type localThumbnailStore struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalThumbnailStore(dir, baseURL string) ThumbnailStore {
+	return &localThumbnailStore{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Put は一時ファイルに書いてから rename するので、書き込み途中のファイルが配信されることはない。
+func (s *localThumbnailStore) Put(ctx context.Context, name string, r io.Reader, contentType string) error {
+	if !validName(name) {
+		return ErrInvalidName
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp は 0600 で作るので、os.Create で保存していたときと同じ権限に揃える
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+func (s *localThumbnailStore) Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error) {
+	if !validName(name) {
+		return nil, nil, ErrNotFound
+	}
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	return f, fileInfo(name, info), nil
+}
+
+func (s *localThumbnailStore) Delete(ctx context.Context, name string) error {
+	if !validName(name) {
+		return ErrNotFound
+	}
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *localThumbnailStore) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	if !validName(name) {
+		return nil, ErrNotFound
+	}
+	info, err := os.Stat(filepath.Join(s.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return fileInfo(name, info), nil
+}
+
+func (s *localThumbnailStore) URL(name string) string {
+	return s.baseURL + "/" + name
+}
+
+func fileInfo(name string, info os.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Name:        name,
+		Size:        info.Size(),
+		ContentType: ContentTypeByExt(name),
+		ModTime:     info.ModTime(),
+	}
+}
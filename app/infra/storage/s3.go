@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config は S3 互換ストレージ（AWS S3 / MinIO / Cloudflare R2 など）への接続設定。
+// バケットはパス形式（<endpoint>/<bucket>/<key>）でアクセスする。
+type S3Config struct {
+	Endpoint        string // 例: https://s3.ap-northeast-1.amazonaws.com、http://localhost:9000
+	Region          string // 省略時は us-east-1（MinIO の既定）
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Prefix          string // オブジェクトキーの前につける（例: thumbnails/）
+	BaseURL         string // URL() で返す前半部分
+}
+
+// s3ThumbnailStore は S3 の REST API を署名付き（Signature Version 4）で直接呼ぶ ThumbnailStore。
+type s3ThumbnailStore struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+func NewS3ThumbnailStore(cfg S3Config) (ThumbnailStore, error) {
+	switch {
+	case cfg.Endpoint == "":
+		return nil, errors.New("S3_ENDPOINT is required")
+	case cfg.Bucket == "":
+		return nil, errors.New("S3_BUCKET is required")
+	case cfg.AccessKeyID == "" || cfg.SecretAccessKey == "":
+		return nil, errors.New("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &s3ThumbnailStore{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Put は本文を読み切ってから送る（表紙画像は controller で 10MB までに制限している）。
+func (s *s3ThumbnailStore) Put(ctx context.Context, name string, r io.Reader, contentType string) error {
+	if !validName(name) {
+		return ErrInvalidName
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	res, err := s.do(ctx, http.MethodPut, name, header, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkS3Response(res)
+}
+
+func (s *s3ThumbnailStore) Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error) {
+	if !validName(name) {
+		return nil, nil, ErrNotFound
+	}
+	res, err := s.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkS3Response(res); err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+	return res.Body, objectInfo(name, res), nil
+}
+
+func (s *s3ThumbnailStore) Delete(ctx context.Context, name string) error {
+	// S3 の DELETE は存在しなくても 204 を返すので、ほかの実装に合わせて先に確認する
+	if _, err := s.Stat(ctx, name); err != nil {
+		return err
+	}
+	res, err := s.do(ctx, http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkS3Response(res)
+}
+
+func (s *s3ThumbnailStore) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	if !validName(name) {
+		return nil, ErrNotFound
+	}
+	res, err := s.do(ctx, http.MethodHead, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if err := checkS3Response(res); err != nil {
+		return nil, err
+	}
+	return objectInfo(name, res), nil
+}
+
+func (s *s3ThumbnailStore) URL(name string) string {
+	return s.cfg.BaseURL + "/" + name
+}
+
+func (s *s3ThumbnailStore) do(ctx context.Context, method, name string, header http.Header, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = u.Path + "/" + s.cfg.Bucket + "/" + s.cfg.Prefix + name
+	// 送るパスと署名するパスのエンコードを揃える
+	u.RawPath = s3EscapePath(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = int64(len(body))
+	s.sign(req, body)
+	return s.client.Do(req)
+}
+
+// sign は AWS Signature Version 4 で Authorization ヘッダーをつける。
+// 署名するヘッダーは host / x-amz-content-sha256 / x-amz-date（と Content-Type があればそれ）だけ。
+func (s *s3ThumbnailStore) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		signed = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+		values["content-type"] = ct
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signed {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(values[h]) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func checkS3Response(res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("s3: %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func objectInfo(name string, res *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Name:        name,
+		ContentType: res.Header.Get("Content-Type"),
+	}
+	if info.ContentType == "" {
+		info.ContentType = ContentTypeByExt(name)
+	}
+	if n, err := strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = n
+	}
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info
+}
+
+// s3EscapePath は SigV4 の正規化 URI。"/" 以外の予約文字をすべて % エンコードする。
+func s3EscapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ThumbnailStore は本の表紙画像の保存先。API を複数コンテナで動かすときは S3 互換ストレージを使う。
+// name は "<id>.<ext>" のようなフラットなファイル名で、ディレクトリ区切りは含めない。
+type ThumbnailStore interface {
+	Put(ctx context.Context, name string, r io.Reader, contentType string) error
+	Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, name string) error
+	Stat(ctx context.Context, name string) (*ObjectInfo, error)
+	// URL はクライアントに返す画像の URL。
+	URL(name string) string
+}
+
+// ObjectInfo は保存済みの画像のメタデータ。
+type ObjectInfo struct {
+	Name        string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+var (
+	// ErrNotFound は画像が存在しないときに返す。controller で 404 に変換する。
+	ErrNotFound = errors.New("thumbnail not found")
+	// ErrInvalidName は name にパス区切りなどが含まれるときに返す。
+	ErrInvalidName = errors.New("invalid thumbnail name")
+)
+
+// NewThumbnailStore は環境変数 THUMBNAIL_STORE（local / s3、既定は local）で選んだ実装を返す。
+// 返す URL の前半は THUMBNAIL_BASE_URL で指定する（既定は http://localhost:<PORT>/api/books/thumbnails）。
+//
+// local: THUMBNAIL_DIR（既定 uploads/thumbnails）に保存する。
+// s3: S3_ENDPOINT / S3_REGION / S3_BUCKET / S3_ACCESS_KEY_ID / S3_SECRET_ACCESS_KEY / S3_PREFIX で接続する。
+// ローカルでは S3_ENDPOINT=http://localhost:9000 で MinIO に接続できる。
+func NewThumbnailStore(ctx context.Context) (ThumbnailStore, error) {
+	baseURL := os.Getenv("THUMBNAIL_BASE_URL")
+	if baseURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8085"
+		}
+		baseURL = "http://localhost:" + port + "/api/books/thumbnails"
+	}
+	switch os.Getenv("THUMBNAIL_STORE") {
+	case "", "local":
+		dir := os.Getenv("THUMBNAIL_DIR")
+		if dir == "" {
+			dir = "uploads/thumbnails"
+		}
+		return NewLocalThumbnailStore(dir, baseURL), nil
+	case "s3":
+		return NewS3ThumbnailStore(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			Prefix:          os.Getenv("S3_PREFIX"),
+			BaseURL:         baseURL,
+		})
+	default:
+		return nil, errors.New("THUMBNAIL_STORE must be local or s3")
+	}
+}
+
+// ContentTypeByExt は拡張子から画像の Content-Type を決める。分からなければ image/jpeg。
+func ContentTypeByExt(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
+
+func validName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && !strings.Contains(name, "..")
+}
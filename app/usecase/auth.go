@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type Auth struct {
+	authService *service.AuthSvc
+}
+
+func NewAuth(svc *service.AuthSvc) *Auth {
+	return &Auth{authService: svc}
+}
+
+func (a Auth) SignUp(ctx context.Context, r *request.AuthSignUp) (*response.AuthSignUp, error) {
+	user, err := a.authService.SignUp(ctx, r.Email, r.Name, r.Password)
+	if err != nil {
+		return nil, err
+	}
+	token, authToken, err := a.authService.IssueToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewAuthSignUp(token, authToken, user), nil
+}
+
+func (a Auth) Login(ctx context.Context, r *request.AuthLogin) (*response.AuthLogin, error) {
+	token, authToken, user, err := a.authService.Login(ctx, r.Email, r.Password)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewAuthLogin(token, authToken, user), nil
+}
+
+func (a Auth) Logout(ctx context.Context, r *request.AuthLogout) error {
+	return a.authService.Logout(ctx, r.Token)
+}
+
+// Me は認証 middleware が context に入れたユーザーを返す。
+func (a Auth) Me(ctx context.Context, r *request.AuthMe) (*response.AuthMe, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, service.ErrUnauthenticated
+	}
+	return response.NewAuthMe(user), nil
+}
+
+// UpdateMe はログイン中のユーザーの名前・タイムゾーン・リマインダーの設定を変える。
+func (a Auth) UpdateMe(ctx context.Context, r *request.AuthMeUpdate) (*response.AuthMeUpdate, error) {
+	current, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, service.ErrUnauthenticated
+	}
+	user := *current
+	if r.Name != nil {
+		user.Name = *r.Name
+	}
+	if r.TimeZone != nil {
+		user.TimeZone = *r.TimeZone
+	}
+	if r.DailyReminder != nil {
+		user.DailyReminder = *r.DailyReminder
+	}
+	if err := a.authService.UpdateUser(ctx, &user); err != nil {
+		return nil, err
+	}
+	return response.NewAuthMeUpdate(&user), nil
+}
+
+// Authenticate はセッショントークンからユーザーを引く。認証 middleware から呼ぶ。
+func (a Auth) Authenticate(ctx context.Context, token string) (*entity.User, error) {
+	return a.authService.Authenticate(ctx, token)
+}
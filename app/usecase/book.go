@@ -7,28 +7,45 @@ import (
 	"github.com/sora-00/booktracker-api/app/domain/entity"
 	"github.com/sora-00/booktracker-api/app/domain/repository"
 	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/infra/search"
 	"github.com/sora-00/booktracker-api/app/usecase/request"
 	"github.com/sora-00/booktracker-api/app/usecase/response"
 )
 
 type Book struct {
-	bookRepo    repository.BookRepo
-	bookService *service.BookSvc
+	bookRepo      repository.BookRepo
+	bookService   *service.BookSvc
+	reviewService *service.ReviewSvc
+	importService *service.ImportSvc
+	searchIndex   *search.Index
 }
 
-func NewBook(repo repository.BookRepo, svc *service.BookSvc) *Book {
+func NewBook(repo repository.BookRepo, svc *service.BookSvc, reviewSvc *service.ReviewSvc, importSvc *service.ImportSvc, idx *search.Index) *Book {
 	return &Book{
-		bookRepo:    repo,
-		bookService: svc,
+		bookRepo:      repo,
+		bookService:   svc,
+		reviewService: reviewSvc,
+		importService: importSvc,
+		searchIndex:   idx,
 	}
 }
 
 func (b Book) Get(ctx context.Context, r *request.BookGet) (*response.BookGet, error) {
-	books, err := b.bookRepo.FindAll(ctx)
+	books, next, err := b.bookRepo.Query(ctx, repository.BookQuery{
+		Status:    entity.Status(r.Status),
+		Author:    r.Author,
+		Publisher: r.Publisher,
+		Shelf:     r.Shelf,
+		Tag:       r.Tag,
+		Sort:      r.Sort,
+		Desc:      r.Order == "desc",
+		Limit:     r.Limit,
+		PageToken: r.PageToken,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return response.NewBookGet(books), nil
+	return response.NewBookGet(books, next), nil
 }
 
 func (b Book) GetByID(ctx context.Context, r *request.BookGetByID) (*response.BookGetByID, error) {
@@ -36,59 +53,118 @@ func (b Book) GetByID(ctx context.Context, r *request.BookGetByID) (*response.Bo
 	if err != nil {
 		return nil, err
 	}
-	return response.NewBookGetByID(book), nil
+	stats, err := b.reviewService.RatingStats(ctx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewBookGetByID(book, response.NewRatingStats(stats)), nil
 }
 
 func (b Book) Create(ctx context.Context, r *request.BookCreate) (*response.BookCreate, error) {
-	now := time.Now()
-	book := &entity.Book{
-		Title:              r.Title,
-		Author:             r.Author,
-		TotalPages:         r.TotalPages,
-		Publisher:          r.Publisher,
-		ThumbnailUrl:       r.ThumbnailUrl,
-		Status:             entity.Status(r.Status),
-		TargetCompleteDate: r.TargetCompleteDate.Time(),
-		EncounterNote:      r.EncounterNote,
-		ReadPages:          r.ReadPages,
-		TargetPagesPerDay:  r.TargetPagesPerDay,
-		CreatedAt:          now,
-		UpdatedAt:          now,
+	book := newBook(&r.BookCreateForm, time.Now())
+	if !r.AllowDuplicate {
+		if err := b.bookService.CheckDuplicate(ctx, book); err != nil {
+			return nil, err
+		}
 	}
 	created, err := b.bookService.CreateBook(ctx, book)
 	if err != nil {
 		return nil, err
 	}
+	b.indexBook(ctx, created)
 	return response.NewBookCreate(created), nil
 }
 
+// newBook は登録フォームから保存する本を作る。
+func newBook(f *request.BookCreateForm, now time.Time) *entity.Book {
+	return &entity.Book{
+		Title:              f.Title,
+		Author:             f.Author,
+		TotalPages:         f.TotalPages,
+		Publisher:          f.Publisher,
+		ISBN:               f.ISBN,
+		ThumbnailUrl:       f.ThumbnailUrl,
+		ThumbnailID:        f.ThumbnailID,
+		Status:             entity.Status(f.Status),
+		TargetCompleteDate: f.TargetCompleteDate.Time(),
+		EncounterNote:      f.EncounterNote,
+		ReadPages:          f.ReadPages,
+		TargetPagesPerDay:  f.TargetPagesPerDay,
+		Shelves:            f.Shelves,
+		Tags:               f.Tags,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
+
 func (b Book) Update(ctx context.Context, r *request.BookUpdate) (*response.BookUpdate, error) {
-	book, err := b.bookRepo.FindByID(ctx, r.BookID)
+	book, err := b.bookService.UpdateBook(ctx, r.BookID, precondition(r.IfMatch), updateBook(&r.BookUpdateForm))
 	if err != nil {
 		return nil, err
 	}
-	if r.ThumbnailUrl != nil {
-		book.ThumbnailUrl = *r.ThumbnailUrl
-	}
-	if r.TargetCompleteDate != nil {
-		book.TargetCompleteDate = r.TargetCompleteDate.Time()
-	}
-	if r.EncounterNote != nil {
-		book.EncounterNote = *r.EncounterNote
-	}
-	if r.TargetPagesPerDay != nil {
-		book.TargetPagesPerDay = *r.TargetPagesPerDay
+	b.indexBook(ctx, book)
+	return response.NewBookUpdate(book), nil
+}
+
+// updateBook は更新フォームの送られた項目だけを本に当てる。
+func updateBook(r *request.BookUpdateForm) func(book *entity.Book) error {
+	return func(book *entity.Book) error {
+		if r.ThumbnailUrl != nil {
+			if r.ThumbnailID == nil && book.ThumbnailID != "" && *r.ThumbnailUrl != book.ThumbnailUrl {
+				// 外部の URL に差し替えたときはアップロード済みの画像を外す
+				book.ThumbnailID = ""
+			}
+			book.ThumbnailUrl = *r.ThumbnailUrl
+		} else if r.ThumbnailID != nil && *r.ThumbnailID == "" {
+			book.ThumbnailUrl = ""
+		}
+		if r.ThumbnailID != nil {
+			book.ThumbnailID = *r.ThumbnailID
+		}
+		if r.ISBN != nil {
+			book.ISBN = *r.ISBN
+		}
+		if r.TargetCompleteDate != nil {
+			book.TargetCompleteDate = r.TargetCompleteDate.Time()
+		}
+		if r.EncounterNote != nil {
+			book.EncounterNote = *r.EncounterNote
+		}
+		if r.TargetPagesPerDay != nil {
+			book.TargetPagesPerDay = *r.TargetPagesPerDay
+		}
+		if r.Shelves != nil {
+			book.Shelves = *r.Shelves
+		}
+		if r.Tags != nil {
+			book.Tags = *r.Tags
+		}
+		return nil
 	}
-	book.UpdatedAt = time.Now()
-	if err := b.bookRepo.Update(ctx, book); err != nil {
+}
+
+// Patch は merge-patch / json-patch を今の本に当てて保存する。当てるのはトランザクションの中で読んだ最新の本。
+func (b Book) Patch(ctx context.Context, r *request.BookPatch) (*response.BookPatch, error) {
+	book, err := b.bookService.UpdateBook(ctx, r.BookID, precondition(r.IfMatch), r.Apply)
+	if err != nil {
 		return nil, err
 	}
-	return response.NewBookUpdate(book), nil
+	b.indexBook(ctx, book)
+	return response.NewBookPatch(book), nil
 }
 
 func (b Book) Delete(ctx context.Context, r *request.BookDelete) (*response.BookDelete, error) {
-	if err := b.bookRepo.Delete(ctx, r.BookID); err != nil {
+	if err := b.bookService.DeleteBook(ctx, r.BookID, precondition(r.IfMatch)); err != nil {
 		return nil, err
 	}
+	b.unindexBook(ctx, r.BookID)
 	return response.NewBookDelete(r.BookID), nil
 }
+
+// precondition は If-Match を service の更新・削除の条件にする。ヘッダーがなければ nil。
+func precondition(m *request.IfMatch) *service.Precondition {
+	if m == nil {
+		return nil
+	}
+	return &service.Precondition{Any: m.Any, Versions: m.Versions}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// BatchCreate は本をまとめて登録する。1冊ずつの扱いは Create と同じ。
+func (b Book) BatchCreate(ctx context.Context, r *request.BookBatchCreate) (*response.BookBatch, error) {
+	now := time.Now()
+	results, err := runBookBatch(r.Errs, r.Atomic, func(valid []int) ([]service.BookBatchResult, error) {
+		books := make([]*entity.Book, len(valid))
+		for j, i := range valid {
+			books[j] = newBook(&r.Books[i], now)
+		}
+		return b.bookService.CreateBooks(ctx, books, !r.AllowDuplicate, r.Atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		if res.Book != nil {
+			b.indexBook(ctx, res.Book)
+		}
+	}
+	return response.NewBookBatch(results, nil), nil
+}
+
+// BatchUpdate は本をまとめて更新する。1冊ずつの扱いは Update と同じで、version は If-Match の代わり。
+func (b Book) BatchUpdate(ctx context.Context, r *request.BookBatchUpdate) (*response.BookBatch, error) {
+	results, err := runBookBatch(r.Errs, r.Atomic, func(valid []int) ([]service.BookBatchResult, error) {
+		edits := make([]service.BookEdit, len(valid))
+		for j, i := range valid {
+			item := &r.Books[i]
+			edits[j] = service.BookEdit{ID: item.ID, Pre: versionPrecondition(item.Version), Edit: updateBook(&item.BookUpdateForm)}
+		}
+		return b.bookService.UpdateBooks(ctx, edits, r.Atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(r.Books))
+	for i, res := range results {
+		ids[i] = r.Books[i].ID
+		if res.Book != nil {
+			b.indexBook(ctx, res.Book)
+		}
+	}
+	return response.NewBookBatch(results, ids), nil
+}
+
+// BatchDelete は本をまとめてゴミ箱に入れる。1冊ずつの扱いは Delete と同じで、version は If-Match の代わり。
+func (b Book) BatchDelete(ctx context.Context, r *request.BookBatchDelete) (*response.BookBatch, error) {
+	results, err := runBookBatch(r.Errs, r.Atomic, func(valid []int) ([]service.BookBatchResult, error) {
+		edits := make([]service.BookEdit, len(valid))
+		for j, i := range valid {
+			edits[j] = service.BookEdit{ID: r.Books[i].ID, Pre: versionPrecondition(r.Books[i].Version)}
+		}
+		return b.bookService.TrashBooks(ctx, edits, r.Atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(r.Books))
+	for i, res := range results {
+		ids[i] = r.Books[i].ID
+		if res.Err == nil {
+			b.unindexBook(ctx, ids[i])
+		}
+		// ゴミ箱に入れた本は返さない（DELETE と同じ）
+		results[i].Book = nil
+	}
+	return response.NewBookBatch(results, ids), nil
+}
+
+// runBookBatch は入力の不備がない項目だけを run に渡し、結果をリクエストの順に戻す。
+// atomic で不備のある項目があれば run を呼ばず、ほかの項目を service.ErrBatchAborted にする。
+func runBookBatch(errs []error, atomic bool, run func(valid []int) ([]service.BookBatchResult, error)) ([]service.BookBatchResult, error) {
+	results := make([]service.BookBatchResult, len(errs))
+	var valid []int
+	for i, err := range errs {
+		if err != nil {
+			results[i].Err = err
+		} else {
+			valid = append(valid, i)
+		}
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+	if atomic && len(valid) < len(errs) {
+		for _, i := range valid {
+			results[i].Err = service.ErrBatchAborted
+		}
+		return results, nil
+	}
+	out, err := run(valid)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range valid {
+		results[i] = out[j]
+	}
+	return results, nil
+}
+
+// versionPrecondition は一括操作の version を If-Match と同じ条件にする。指定がなければ nil。
+func versionPrecondition(version *int) *service.Precondition {
+	if version == nil {
+		return nil
+	}
+	return &service.Precondition{Versions: []int{*version}}
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type BookForecast struct {
+	bookRepo        repository.BookRepo
+	sessionRepo     repository.ReadingSessionRepo
+	forecastService *service.ForecastSvc
+}
+
+func NewBookForecast(bookRepo repository.BookRepo, sessionRepo repository.ReadingSessionRepo, svc *service.ForecastSvc) *BookForecast {
+	return &BookForecast{
+		bookRepo:        bookRepo,
+		sessionRepo:     sessionRepo,
+		forecastService: svc,
+	}
+}
+
+func (u BookForecast) Get(ctx context.Context, r *request.BookForecast) (*response.BookForecast, error) {
+	book, err := u.bookRepo.FindByID(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.sessionRepo.FindByBookID(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	f := u.forecastService.Forecast(*book, sessions, time.Now(), r.Location)
+	return response.NewBookForecast(book.ID, f), nil
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// Merge は重複して登録した本を1冊にまとめる。消した本は検索インデックスからも外す。
+func (b Book) Merge(ctx context.Context, r *request.BookMerge) (*response.BookMerge, error) {
+	book, err := b.bookService.MergeBooks(ctx, r.TargetID, r.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	b.unindexBook(ctx, r.SourceID)
+	b.indexBook(ctx, book)
+	return response.NewBookMerge(book, r.SourceID), nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/infra/search"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// BookSearchDocument は本を検索インデックスの文書にする。タイトル・著者に当たったものほど上位にする。
+// 管理コマンドの reindex でも使う。
+func BookSearchDocument(book *entity.Book) search.Document {
+	return search.Document{
+		ID: book.ID,
+		Fields: []search.Field{
+			{Name: "title", Text: book.Title, Weight: 3},
+			{Name: "author", Text: book.Author, Weight: 2},
+			{Name: "publisher", Text: book.Publisher, Weight: 1},
+			{Name: "encounterNote", Text: book.EncounterNote, Weight: 1},
+		},
+	}
+}
+
+// Search は title / author / publisher / encounterNote を全文検索する。
+// そのユーザーのインデックスがまだないか古ければ（初回・スナップショットなし・ほかのインスタンスで変更されたかもしれない）、
+// 本をすべて読んで作り直す。
+func (b Book) Search(ctx context.Context, r *request.BookSearch) (*response.BookSearch, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, service.ErrUnauthenticated
+	}
+	if !b.searchIndex.Fresh(user.ID) {
+		books, err := b.bookRepo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		docs := make([]search.Document, 0, len(books))
+		for i := range books {
+			docs = append(docs, BookSearchDocument(&books[i]))
+		}
+		b.searchIndex.Replace(user.ID, docs)
+	}
+
+	hits := b.searchIndex.Search(user.ID, r.Q, r.Limit)
+	results := make([]response.BookSearchHit, 0, len(hits))
+	for _, hit := range hits {
+		// 本の中身はインデックスではなく repository から返す
+		book, err := b.bookRepo.FindByID(ctx, hit.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			b.searchIndex.Delete(user.ID, hit.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, response.BookSearchHit{Book: book, Score: hit.Score, Highlights: hit.Highlights})
+	}
+	return response.NewBookSearch(results), nil
+}
+
+// indexBook は作成・更新した本を検索インデックスに反映する。
+func (b Book) indexBook(ctx context.Context, book *entity.Book) {
+	if user, ok := auth.UserFromContext(ctx); ok {
+		b.searchIndex.Put(user.ID, BookSearchDocument(book))
+	}
+}
+
+// unindexBook は削除した本を検索インデックスから外す。
+func (b Book) unindexBook(ctx context.Context, id int) {
+	if user, ok := auth.UserFromContext(ctx); ok {
+		b.searchIndex.Delete(user.ID, id)
+	}
+}
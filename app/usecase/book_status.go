@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type BookStatus struct {
+	statusRepo  repository.StatusChangeRepo
+	bookService *service.BookSvc
+}
+
+func NewBookStatus(repo repository.StatusChangeRepo, svc *service.BookSvc) *BookStatus {
+	return &BookStatus{
+		statusRepo:  repo,
+		bookService: svc,
+	}
+}
+
+func (u BookStatus) Change(ctx context.Context, r *request.BookStatusChange) (*response.BookStatusChange, error) {
+	book, change, err := u.bookService.ChangeStatus(ctx, r.BookID, entity.Transition(r.Action))
+	if err != nil {
+		return nil, err
+	}
+	return response.NewBookStatusChange(book, change), nil
+}
+
+func (u BookStatus) History(ctx context.Context, r *request.BookStatusHistory) (*response.BookStatusHistory, error) {
+	history, err := u.statusRepo.FindByBookID(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewBookStatusHistory(history), nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type Highlight struct {
+	highlightRepo    repository.HighlightRepo
+	bookRepo         repository.BookRepo
+	highlightService *service.HighlightSvc
+}
+
+func NewHighlight(repo repository.HighlightRepo, bookRepo repository.BookRepo, svc *service.HighlightSvc) *Highlight {
+	return &Highlight{
+		highlightRepo:    repo,
+		bookRepo:         bookRepo,
+		highlightService: svc,
+	}
+}
+
+func (u Highlight) Get(ctx context.Context, r *request.HighlightGet) (*response.HighlightGet, error) {
+	highlights, err := u.highlightRepo.FindByBookID(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewHighlightGet(highlights), nil
+}
+
+func (u Highlight) GetByID(ctx context.Context, r *request.HighlightGetByID) (*response.HighlightGetByID, error) {
+	highlight, err := u.highlightRepo.FindByID(ctx, r.BookID, r.HighlightID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewHighlightGetByID(highlight), nil
+}
+
+func (u Highlight) Create(ctx context.Context, r *request.HighlightCreate) (*response.HighlightCreate, error) {
+	now := time.Now()
+	highlight := &entity.Highlight{
+		Text:      r.Text,
+		Page:      r.Page,
+		Chapter:   r.Chapter,
+		Comment:   r.Comment,
+		Color:     entity.HighlightColor(r.Color),
+		Category:  r.Category,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.highlightService.AddHighlight(ctx, r.BookID, highlight); err != nil {
+		return nil, err
+	}
+	return response.NewHighlightCreate(highlight), nil
+}
+
+func (u Highlight) Update(ctx context.Context, r *request.HighlightUpdate) (*response.HighlightUpdate, error) {
+	highlight, err := u.highlightRepo.FindByID(ctx, r.BookID, r.HighlightID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Text != nil {
+		highlight.Text = *r.Text
+	}
+	if r.Page != nil {
+		highlight.Page = *r.Page
+	}
+	if r.Chapter != nil {
+		highlight.Chapter = *r.Chapter
+	}
+	if r.Comment != nil {
+		highlight.Comment = *r.Comment
+	}
+	if r.Color != nil {
+		highlight.Color = entity.HighlightColor(*r.Color)
+	}
+	if r.Category != nil {
+		highlight.Category = *r.Category
+	}
+	if err := u.highlightService.UpdateHighlight(ctx, highlight); err != nil {
+		return nil, err
+	}
+	return response.NewHighlightUpdate(highlight), nil
+}
+
+func (u Highlight) Delete(ctx context.Context, r *request.HighlightDelete) (*response.HighlightDelete, error) {
+	if err := u.highlightRepo.Delete(ctx, r.BookID, r.HighlightID); err != nil {
+		return nil, err
+	}
+	return response.NewHighlightDelete(r.BookID, r.HighlightID), nil
+}
+
+// Feed は本をまたいだハイライトを新しい順に返す。bookId の本がなければ 404 にする。
+func (u Highlight) Feed(ctx context.Context, r *request.HighlightFeed) (*response.HighlightFeed, error) {
+	if r.BookID != 0 {
+		if _, err := u.bookRepo.FindByID(ctx, r.BookID); err != nil {
+			return nil, err
+		}
+	}
+	highlights, next, err := u.highlightRepo.Query(ctx, repository.HighlightQuery{
+		BookID:    r.BookID,
+		Color:     entity.HighlightColor(r.Color),
+		Category:  r.Category,
+		Limit:     r.Limit,
+		PageToken: r.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]response.HighlightWithBook, 0, len(highlights))
+	books := map[int]*entity.Book{}
+	for i := range highlights {
+		book, err := u.book(ctx, books, highlights[i].BookID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, response.NewHighlightWithBook(&highlights[i], book))
+	}
+	return response.NewHighlightFeed(items, next), nil
+}
+
+// Daily は tz での今日に見返すハイライトを1件返す。
+func (u Highlight) Daily(ctx context.Context, r *request.HighlightDaily) (*response.HighlightDaily, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, service.ErrUnauthenticated
+	}
+	now := time.Now().In(r.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	highlight, err := u.highlightService.DailyHighlight(ctx, user.ID, today)
+	if err != nil {
+		return nil, err
+	}
+	res := response.NewHighlightDaily(today.Format("2006-01-02"), nil)
+	if highlight != nil {
+		book, err := u.bookRepo.FindByID(ctx, highlight.BookID)
+		if err != nil {
+			return nil, err
+		}
+		item := response.NewHighlightWithBook(highlight, book)
+		res.Highlight = &item
+	}
+	return res, nil
+}
+
+// book は一覧の中で同じ本を何度も読まないようにキャッシュする。
+func (u Highlight) book(ctx context.Context, cache map[int]*entity.Book, id int) (*entity.Book, error) {
+	if b, ok := cache[id]; ok {
+		return b, nil
+	}
+	b, err := u.bookRepo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		// 一覧を引いた後に本が消された。本の情報なしで返す
+		b, err = &entity.Book{ID: id}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache[id] = b
+	return b, nil
+}
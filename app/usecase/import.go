@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// ImportCSV は CSV の行を本にして取り込みを始める。登録した本は検索インデックスにも入れる。
+func (b Book) ImportCSV(ctx context.Context, r *request.ImportCSV) (*response.ImportJob, error) {
+	now := time.Now()
+	items := make([]service.ImportItem, len(r.Rows))
+	for i := range r.Rows {
+		items[i] = importItem(&r.Rows[i], now)
+	}
+	job, err := b.importService.StartImport(ctx, r.Format, r.DryRun, items, b.indexBook)
+	if err != nil {
+		return nil, err
+	}
+	return newImportJobResponse(job), nil
+}
+
+func (b Book) GetImportJob(ctx context.Context, r *request.ImportJobGet) (*response.ImportJob, error) {
+	job, err := b.importService.FindImportJob(ctx, r.JobID)
+	if err != nil {
+		return nil, err
+	}
+	return newImportJobResponse(job), nil
+}
+
+// newImportJobResponse は保存した行の結果を、本を1冊ずつ登録したときと同じ error に戻して返す。
+func newImportJobResponse(job *entity.ImportJob) *response.ImportJob {
+	res := response.NewImportJob(job)
+	for i, row := range job.Rows {
+		if row.Error == nil {
+			continue
+		}
+		if len(row.Error.Fields) > 0 {
+			verr := &request.ValidationError{}
+			for _, f := range row.Error.Fields {
+				verr.Fields = append(verr.Fields, request.FieldError{Field: f.Field, Message: f.Message})
+			}
+			res.Rows[i].Err = verr
+			continue
+		}
+		res.Rows[i].Err = service.ImportRowErr(row.Error)
+	}
+	if job.Error != "" {
+		res.Err = errors.New(job.Error)
+	}
+	return res
+}
+
+// importItem は CSV の1行から登録する本を作る。completed の本は読み終えたものとして readPages を totalPages にし、
+// 追加した日があれば createdAt をその日にする。評価があればレビューにする。
+func importItem(row *request.ImportCSVRow, now time.Time) service.ImportItem {
+	item := service.ImportItem{Row: row.Line}
+	if row.Err != nil {
+		item.Err = importRowError(row.Err)
+		return item
+	}
+	book := &entity.Book{
+		Title:         row.Title,
+		Author:        row.Author,
+		TotalPages:    row.TotalPages,
+		Publisher:     row.Publisher,
+		ISBN:          row.ISBN,
+		ThumbnailUrl:  row.ThumbnailUrl,
+		Status:        entity.Status(row.Status),
+		EncounterNote: row.EncounterNote,
+		Tags:          row.Tags,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if book.Status == entity.StatusCompleted {
+		book.ReadPages = book.TotalPages
+	}
+	if !row.AddedOn.IsZero() {
+		book.CreatedAt = row.AddedOn
+	}
+	item.Book = book
+	item.FinishedOn = row.FinishedOn
+	if row.Rating > 0 {
+		item.Review = &entity.Review{
+			Rating:     row.Rating,
+			Text:       row.Review,
+			Spoiler:    row.Spoiler,
+			FinishedOn: row.FinishedOn,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+	return item
+}
+
+// importRowError は入力の不備を、行の結果として保存できる形にする。
+func importRowError(err error) *entity.ImportRowError {
+	e := &entity.ImportRowError{Message: err.Error()}
+	var verr *request.ValidationError
+	if errors.As(err, &verr) {
+		for _, f := range verr.Fields {
+			e.Fields = append(e.Fields, entity.ImportRowField{Field: f.Field, Message: f.Message})
+		}
+	}
+	return e
+}
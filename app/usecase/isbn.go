@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// ISBNLookup は ISBN から本の作成フォームを埋める。
+type ISBNLookup struct {
+	lookupService *service.LookupSvc
+}
+
+func NewISBNLookup(svc *service.LookupSvc) *ISBNLookup {
+	return &ISBNLookup{lookupService: svc}
+}
+
+func (u ISBNLookup) Get(ctx context.Context, r *request.ISBNLookup) (*response.ISBNLookup, error) {
+	l, err := u.lookupService.LookupISBN(ctx, r.ISBN, r.Cover)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewISBNLookup(l), nil
+}
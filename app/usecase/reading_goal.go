@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// ReadingGoal は読書目標の作成・更新・削除と進み具合。
+type ReadingGoal struct {
+	goalRepo    repository.ReadingGoalRepo
+	statusRepo  repository.StatusChangeRepo
+	sessionRepo repository.ReadingSessionRepo
+	goalService *service.GoalSvc
+}
+
+func NewReadingGoal(repo repository.ReadingGoalRepo, statusRepo repository.StatusChangeRepo, sessionRepo repository.ReadingSessionRepo, svc *service.GoalSvc) *ReadingGoal {
+	return &ReadingGoal{
+		goalRepo:    repo,
+		statusRepo:  statusRepo,
+		sessionRepo: sessionRepo,
+		goalService: svc,
+	}
+}
+
+func (u ReadingGoal) Get(ctx context.Context, r *request.ReadingGoalGet) (*response.ReadingGoalGet, error) {
+	goals, err := u.goalRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewReadingGoalGet(goals), nil
+}
+
+func (u ReadingGoal) GetByID(ctx context.Context, r *request.ReadingGoalGetByID) (*response.ReadingGoalGetByID, error) {
+	goal, err := u.goalRepo.FindByID(ctx, r.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewReadingGoalGetByID(goal), nil
+}
+
+func (u ReadingGoal) Create(ctx context.Context, r *request.ReadingGoalCreate) (*response.ReadingGoalCreate, error) {
+	now := time.Now()
+	start, end := r.Window()
+	goal := &entity.ReadingGoal{
+		Title:     r.Title,
+		Metric:    entity.GoalMetric(r.Metric),
+		Target:    r.Target,
+		Period:    entity.GoalPeriod(r.Period),
+		StartDate: start,
+		EndDate:   end,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.goalRepo.Create(ctx, goal); err != nil {
+		return nil, err
+	}
+	return response.NewReadingGoalCreate(goal), nil
+}
+
+func (u ReadingGoal) Update(ctx context.Context, r *request.ReadingGoalUpdate) (*response.ReadingGoalUpdate, error) {
+	goal, err := u.goalRepo.FindByID(ctx, r.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Title != nil {
+		goal.Title = *r.Title
+	}
+	if r.Target != nil {
+		goal.Target = *r.Target
+	}
+	goal.UpdatedAt = time.Now()
+	if err := u.goalRepo.Update(ctx, goal); err != nil {
+		return nil, err
+	}
+	return response.NewReadingGoalUpdate(goal), nil
+}
+
+func (u ReadingGoal) Delete(ctx context.Context, r *request.ReadingGoalDelete) (*response.ReadingGoalDelete, error) {
+	if err := u.goalRepo.Delete(ctx, r.GoalID); err != nil {
+		return nil, err
+	}
+	return response.NewReadingGoalDelete(r.GoalID), nil
+}
+
+func (u ReadingGoal) Progress(ctx context.Context, r *request.ReadingGoalProgress) (*response.ReadingGoalProgress, error) {
+	goal, err := u.goalRepo.FindByID(ctx, r.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	var changes []entity.StatusChange
+	var sessions []entity.ReadingSession
+	switch goal.Metric {
+	case entity.GoalBooks:
+		changes, err = u.statusRepo.FindAll(ctx)
+	case entity.GoalPages:
+		sessions, err = u.sessionRepo.FindAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := u.goalService.Progress(*goal, changes, sessions, time.Now(), r.Location)
+	return response.NewReadingGoalProgress(goal, p), nil
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type ReadingSession struct {
+	sessionRepo repository.ReadingSessionRepo
+	bookService *service.BookSvc
+}
+
+func NewReadingSession(repo repository.ReadingSessionRepo, svc *service.BookSvc) *ReadingSession {
+	return &ReadingSession{
+		sessionRepo: repo,
+		bookService: svc,
+	}
+}
+
+func (u ReadingSession) Get(ctx context.Context, r *request.ReadingSessionGet) (*response.ReadingSessionGet, error) {
+	sessions, err := u.sessionRepo.FindByBookID(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewReadingSessionGet(sessions), nil
+}
+
+func (u ReadingSession) Create(ctx context.Context, r *request.ReadingSessionCreate) (*response.ReadingSessionCreate, error) {
+	session := &entity.ReadingSession{
+		StartPage: r.StartPage,
+		EndPage:   r.EndPage,
+		StartedAt: r.StartedAt,
+		EndedAt:   r.EndedAt,
+		Note:      r.Note,
+		CreatedAt: time.Now(),
+	}
+	book, err := u.bookService.AddReadingSession(ctx, r.BookID, session)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewReadingSessionCreate(session, book), nil
+}
+
+func (u ReadingSession) Delete(ctx context.Context, r *request.ReadingSessionDelete) (*response.ReadingSessionDelete, error) {
+	book, err := u.bookService.DeleteReadingSession(ctx, r.BookID, r.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewReadingSessionDelete(book), nil
+}
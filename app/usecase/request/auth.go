@@ -0,0 +1,153 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type AuthSignUp struct {
+	AuthSignUpForm
+}
+
+func NewAuthSignUp(req *http.Request) (*AuthSignUp, error) {
+	r := &AuthSignUp{}
+	if err := json.NewDecoder(req.Body).Decode(r); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateAuthSignUpForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type AuthLogin struct {
+	AuthLoginForm
+}
+
+func NewAuthLogin(req *http.Request) (*AuthLogin, error) {
+	r := &AuthLogin{}
+	if err := json.NewDecoder(req.Body).Decode(r); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateAuthLoginForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type AuthLogout struct {
+	Token string
+}
+
+func NewAuthLogout(req *http.Request) (*AuthLogout, error) {
+	token := BearerToken(req)
+	if token == "" {
+		return nil, InvalidField("Authorization", "bearer token is required")
+	}
+	return &AuthLogout{Token: token}, nil
+}
+
+type AuthMe struct{}
+
+func NewAuthMe(req *http.Request) (*AuthMe, error) {
+	return &AuthMe{}, nil
+}
+
+type AuthMeUpdate struct {
+	AuthMeUpdateForm
+}
+
+func NewAuthMeUpdate(req *http.Request) (*AuthMeUpdate, error) {
+	r := &AuthMeUpdate{}
+	if err := json.NewDecoder(req.Body).Decode(&r.AuthMeUpdateForm); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateAuthMeUpdateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// BearerToken は Authorization: Bearer <token> ヘッダーからトークンを取り出す。なければ空文字。
+func BearerToken(req *http.Request) string {
+	h := req.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// ---
+
+// bcrypt は 72 バイトより後ろを無視するので、それより長いパスワードは受け付けない
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+	maxUserNameLength = 50
+)
+
+type AuthSignUpForm struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"` // 表示名（任意）
+	Password string `json:"password"`
+}
+
+func (f AuthSignUpForm) ValidateAuthSignUpForm() error {
+	v := &ValidationError{}
+	switch {
+	case f.Email == "":
+		v.add("email", "email is required")
+	case !strings.Contains(f.Email, "@"):
+		v.add("email", "email is invalid")
+	}
+	switch {
+	case f.Password == "":
+		v.add("password", "password is required")
+	case len(f.Password) < minPasswordLength:
+		v.add("password", "password must be at least 8 characters")
+	case len(f.Password) > maxPasswordLength:
+		v.add("password", "password must be at most 72 bytes")
+	}
+	return v.err()
+}
+
+type AuthLoginForm struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (f AuthLoginForm) ValidateAuthLoginForm() error {
+	v := &ValidationError{}
+	if f.Email == "" {
+		v.add("email", "email is required")
+	}
+	if f.Password == "" {
+		v.add("password", "password is required")
+	}
+	return v.err()
+}
+
+// AuthMeUpdateForm は送った項目だけ更新する。timeZone は IANA のタイムゾーン名で、空文字なら UTC に戻す。
+type AuthMeUpdateForm struct {
+	Name          *string `json:"name"`
+	TimeZone      *string `json:"timeZone"`
+	DailyReminder *bool   `json:"dailyReminder"`
+}
+
+func (f AuthMeUpdateForm) ValidateAuthMeUpdateForm() error {
+	v := &ValidationError{}
+	if f.Name != nil && utf8.RuneCountInString(*f.Name) > maxUserNameLength {
+		v.add("name", fmt.Sprintf("name must be at most %d characters", maxUserNameLength))
+	}
+	if f.TimeZone != nil && *f.TimeZone != "" {
+		if _, err := time.LoadLocation(*f.TimeZone); err != nil {
+			v.add("timeZone", "timeZone must be an IANA time zone name (e.g. Asia/Tokyo)")
+		}
+	}
+	return v.err()
+}
@@ -2,7 +2,6 @@ package request
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,10 +10,109 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-type BookGet struct{}
+const (
+	defaultBookGetLimit = 50
+	maxBookGetLimit     = 100
+)
+
+// BookGet は一覧取得のクエリパラメータ。
+// sort は createdAt（既定）/ updatedAt / targetCompleteDate / title、order は asc（既定）/ desc。
+// shelf・tag は棚・タグの名前で、その棚に入っている・そのタグが付いている本だけを返す。
+// pageToken は前ページのレスポンスの nextPageToken をそのまま渡す。
+type BookGet struct {
+	Status    string
+	Author    string
+	Publisher string
+	Shelf     string
+	Tag       string
+	Sort      string
+	Order     string
+	Limit     int
+	PageToken string
+}
 
 func NewBookGet(req *http.Request) (*BookGet, error) {
-	return &BookGet{}, nil
+	q := req.URL.Query()
+	r := &BookGet{
+		Status:    q.Get("status"),
+		Author:    q.Get("author"),
+		Publisher: q.Get("publisher"),
+		Shelf:     q.Get("shelf"),
+		Tag:       q.Get("tag"),
+		Sort:      q.Get("sort"),
+		Order:     q.Get("order"),
+		Limit:     defaultBookGetLimit,
+		PageToken: q.Get("pageToken"),
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, InvalidField("limit", "limit must be an integer")
+		}
+		r.Limit = limit
+	}
+	if r.Sort == "" {
+		r.Sort = "createdAt"
+	}
+	if r.Order == "" {
+		r.Order = "asc"
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r BookGet) Validate() error {
+	v := &ValidationError{}
+	if r.Status != "" && !validStatus(r.Status) {
+		v.add("status", "status must be unread, reading, paused, completed, or abandoned")
+	}
+	if r.Sort != "createdAt" && r.Sort != "updatedAt" && r.Sort != "targetCompleteDate" && r.Sort != "title" {
+		v.add("sort", "sort must be createdAt, updatedAt, targetCompleteDate, or title")
+	}
+	if r.Order != "asc" && r.Order != "desc" {
+		v.add("order", "order must be asc or desc")
+	}
+	if r.Limit < 1 || r.Limit > maxBookGetLimit {
+		v.add("limit", "limit must be between 1 and 100")
+	}
+	return v.err()
+}
+
+const (
+	defaultBookSearchLimit = 20
+	maxBookSearchLimit     = 100
+)
+
+// BookSearch は全文検索のクエリパラメータ。q は空白区切りの語で、すべての語を含む本を返す。
+type BookSearch struct {
+	Q     string
+	Limit int
+}
+
+func NewBookSearch(req *http.Request) (*BookSearch, error) {
+	q := req.URL.Query()
+	r := &BookSearch{Q: strings.TrimSpace(q.Get("q")), Limit: defaultBookSearchLimit}
+	v := &ValidationError{}
+	if r.Q == "" {
+		v.add("q", "q is required")
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		switch {
+		case err != nil:
+			v.add("limit", "limit must be an integer")
+		case limit < 1 || limit > maxBookSearchLimit:
+			v.add("limit", "limit must be between 1 and 100")
+		default:
+			r.Limit = limit
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 type BookGetByID struct {
@@ -22,72 +120,113 @@ type BookGetByID struct {
 }
 
 func NewBookGetByID(req *http.Request) (*BookGetByID, error) {
-	idStr := chi.URLParam(req, "id")
-	if idStr == "" {
-		return nil, errors.New("book id is required")
-	}
-	id, err := strconv.Atoi(idStr)
+	id, err := bookIDParam(req)
 	if err != nil {
-		return nil, errors.New("invalid book id")
+		return nil, err
 	}
 	return &BookGetByID{BookID: id}, nil
 }
 
+// BookCreate は本の登録。?allowDuplicate=true なら同じらしい本があっても登録する。
 type BookCreate struct {
+	AllowDuplicate bool
 	BookCreateForm
 }
 
 func NewBookCreate(req *http.Request) (*BookCreate, error) {
 	r := &BookCreate{}
+	if s := req.URL.Query().Get("allowDuplicate"); s != "" {
+		allow, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, InvalidField("allowDuplicate", "allowDuplicate must be true or false")
+		}
+		r.AllowDuplicate = allow
+	}
 	if err := json.NewDecoder(req.Body).Decode(r); err != nil {
 		return nil, err
 	}
+	r.Shelves = normalizeLabels(r.Shelves)
+	r.Tags = normalizeLabels(r.Tags)
 	if err := r.ValidateBookCreateForm(); err != nil {
 		return nil, err
 	}
+	r.ISBN = normalizedISBN(r.ISBN)
 	return r, nil
 }
 
 type BookDelete struct {
-	BookID int `json:"bookId"`
+	BookID  int `json:"bookId"`
+	IfMatch *IfMatch
 }
 
 func NewBookDelete(req *http.Request) (*BookDelete, error) {
-	idStr := chi.URLParam(req, "id")
-	if idStr == "" {
-		return nil, errors.New("book id is required")
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
 	}
-	id, err := strconv.Atoi(idStr)
+	ifMatch, err := ifMatchHeader(req)
 	if err != nil {
-		return nil, errors.New("invalid book id")
+		return nil, err
 	}
-	return &BookDelete{BookID: id}, nil
+	return &BookDelete{BookID: id, IfMatch: ifMatch}, nil
 }
 
+// BookUpdate の IfMatch は If-Match ヘッダー（なければ nil）。
 type BookUpdate struct {
-	BookID int
+	BookID  int
+	IfMatch *IfMatch
 	BookUpdateForm
 }
 
 func NewBookUpdate(req *http.Request) (*BookUpdate, error) {
-	idStr := chi.URLParam(req, "id")
-	if idStr == "" {
-		return nil, errors.New("book id is required")
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
 	}
-	id, err := strconv.Atoi(idStr)
+	ifMatch, err := ifMatchHeader(req)
 	if err != nil {
-		return nil, errors.New("invalid book id")
+		return nil, err
 	}
-	r := &BookUpdate{BookID: id}
+	r := &BookUpdate{BookID: id, IfMatch: ifMatch}
 	if err := json.NewDecoder(req.Body).Decode(&r.BookUpdateForm); err != nil {
 		return nil, err
 	}
+	if r.Shelves != nil {
+		*r.Shelves = normalizeLabels(*r.Shelves)
+	}
+	if r.Tags != nil {
+		*r.Tags = normalizeLabels(*r.Tags)
+	}
 	if err := r.ValidateBookUpdateForm(); err != nil {
 		return nil, err
 	}
+	if r.ISBN != nil {
+		*r.ISBN = normalizedISBN(*r.ISBN)
+	}
 	return r, nil
 }
 
+// bookIDParam は URL の {id} を本の ID として読む。
+func bookIDParam(req *http.Request) (int, error) {
+	idStr := chi.URLParam(req, "id")
+	if idStr == "" {
+		return 0, InvalidField("id", "book id is required")
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, InvalidField("id", "invalid book id")
+	}
+	return id, nil
+}
+
+func validStatus(s string) bool {
+	switch s {
+	case "unread", "reading", "paused", "completed", "abandoned":
+		return true
+	}
+	return false
+}
+
 // ---
 
 // NormalizedDate は targetCompleteDate 用。YYYY-MM-DD のみ受け付け、その日の 00:00:00Z に正規化してから DB に保存する。
@@ -96,11 +235,11 @@ type NormalizedDate time.Time
 func (t *NormalizedDate) UnmarshalJSON(b []byte) error {
 	s := strings.Trim(string(b), `"`)
 	if s == "" || s == "null" {
-		return errors.New("targetCompleteDate is required or invalid format")
+		return InvalidField("targetCompleteDate", "targetCompleteDate is required or invalid format")
 	}
 	parsed, err := time.Parse("2006-01-02", s)
 	if err != nil {
-		return errors.New("targetCompleteDate must be YYYY-MM-DD")
+		return InvalidField("targetCompleteDate", "targetCompleteDate must be YYYY-MM-DD")
 	}
 	*t = NormalizedDate(time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC))
 	return nil
@@ -108,63 +247,105 @@ func (t *NormalizedDate) UnmarshalJSON(b []byte) error {
 
 func (t NormalizedDate) Time() time.Time { return time.Time(t) }
 
+// MarshalJSON は YYYY-MM-DD で書く（ゼロ値は null）。PATCH で今の本をパッチを当てる文書にするときに使う。
+func (t NormalizedDate) MarshalJSON() ([]byte, error) {
+	if t.Time().IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time().Format("2006-01-02"))
+}
+
 // BookCreateForm の targetCompleteDate は YYYY-MM-DD。正規化後 00:00:00Z で保存する。
 type BookCreateForm struct {
 	Title              string         `json:"title"`
 	Author             string         `json:"author"`
 	TotalPages         int            `json:"totalPages"`
 	Publisher          string         `json:"publisher"`
+	ISBN               string         `json:"isbn"`        // 任意。ISBN-10 / ISBN-13（ハイフン可）で、ISBN-13 にそろえて保存する
 	ThumbnailUrl       string         `json:"thumbnailUrl"`
+	ThumbnailID        string         `json:"thumbnailId"` // POST /api/books/thumbnails で返った id。指定すると thumbnailUrl はその画像の URL になる
 	Status             string         `json:"status"`
 	TargetCompleteDate NormalizedDate `json:"targetCompleteDate"`
 	EncounterNote      string         `json:"encounterNote"`      // この本に出会った経緯
 	ReadPages          int            `json:"readPages"`          // 読み終わったページ数
 	TargetPagesPerDay  int            `json:"targetPagesPerDay"`  // 目標ページ数/日
+	Shelves            []string       `json:"shelves"`            // 入れる棚の名前。棚は先に POST /api/shelves で作っておく
+	Tags               []string       `json:"tags"`               // 付けるタグ。まだないタグは自動で作る
 }
 
+// ValidateBookCreateForm は通らなかった項目をすべて *ValidationError にまとめて返す。
 func (f BookCreateForm) ValidateBookCreateForm() error {
+	v := &ValidationError{}
+	if f.Title == "" {
+		v.add("title", "title is required")
+	}
+	if f.Author == "" {
+		v.add("author", "author is required")
+	}
+	if f.TotalPages == 0 {
+		v.add("totalPages", "totalPages is required")
+	}
+	if f.Publisher == "" {
+		v.add("publisher", "publisher is required")
+	}
+	validateISBN(v, f.ISBN)
+	if f.ThumbnailUrl == "" && f.ThumbnailID == "" {
+		v.add("thumbnailId", "thumbnailId or thumbnailUrl is required")
+	}
 	switch {
-	case f.Title == "":
-		return errors.New("title is required")
-	case f.Author == "":
-		return errors.New("author is required")
-	case f.TotalPages == 0:
-		return errors.New("totalPages is required")
-	case f.Publisher == "":
-		return errors.New("publisher is required")
-	case f.ThumbnailUrl == "":
-		return errors.New("thumbnailUrl is required")
 	case f.Status == "":
-		return errors.New("status is required")
-	case f.Status != "unread" && f.Status != "reading" && f.Status != "completed":
-		return errors.New("status must be unread, reading, or completed")
-	case f.TargetCompleteDate.Time().IsZero():
-		return errors.New("targetCompleteDate is required or invalid format (use YYYY-MM-DD)")
+		v.add("status", "status is required")
+	case !validStatus(f.Status):
+		v.add("status", "status must be unread, reading, paused, completed, or abandoned")
+	case f.Status == "completed" && f.ReadPages != f.TotalPages:
+		v.add("status", "status completed requires readPages == totalPages")
+	}
+	if f.TargetCompleteDate.Time().IsZero() {
+		v.add("targetCompleteDate", "targetCompleteDate is required or invalid format (use YYYY-MM-DD)")
+	}
+	switch {
 	case f.ReadPages < 0:
-		return errors.New("readPages must be 0 or greater")
+		v.add("readPages", "readPages must be 0 or greater")
 	case f.ReadPages > f.TotalPages:
-		return errors.New("readPages must not exceed totalPages")
-	case f.TargetPagesPerDay < 0:
-		return errors.New("targetPagesPerDay must be 0 or greater")
+		v.add("readPages", "readPages must not exceed totalPages")
 	}
-	return nil
+	if f.TargetPagesPerDay < 0 {
+		v.add("targetPagesPerDay", "targetPagesPerDay must be 0 or greater")
+	}
+	validateLabels(v, "shelves", f.Shelves)
+	validateLabels(v, "tags", f.Tags)
+	return v.err()
 }
 
 // BookUpdateForm は更新可能な項目のみ。送った項目だけ更新する（nil の項目は既存のまま）。
 // targetCompleteDate は YYYY-MM-DD。正規化後 00:00:00Z で保存する。
 type BookUpdateForm struct {
+	ISBN               *string         `json:"isbn"`        // 空文字で外す
 	ThumbnailUrl       *string         `json:"thumbnailUrl"`
+	ThumbnailID        *string         `json:"thumbnailId"` // 空文字で画像を外す。付け替えた・外した画像は消す
 	TargetCompleteDate *NormalizedDate `json:"targetCompleteDate"`
 	EncounterNote      *string         `json:"encounterNote"`
 	TargetPagesPerDay  *int            `json:"targetPagesPerDay"`
+	Shelves            *[]string       `json:"shelves"` // 送ると入っている棚をこの一覧に置き換える（[] ですべての棚から出す）
+	Tags               *[]string       `json:"tags"`    // 送るとタグをこの一覧に置き換える
 }
 
 func (f BookUpdateForm) ValidateBookUpdateForm() error {
+	v := &ValidationError{}
 	if f.TargetPagesPerDay != nil && *f.TargetPagesPerDay < 0 {
-		return errors.New("targetPagesPerDay must be 0 or greater")
+		v.add("targetPagesPerDay", "targetPagesPerDay must be 0 or greater")
 	}
 	if f.TargetCompleteDate != nil && f.TargetCompleteDate.Time().IsZero() {
-		return errors.New("targetCompleteDate invalid format (use YYYY-MM-DD)")
+		v.add("targetCompleteDate", "targetCompleteDate invalid format (use YYYY-MM-DD)")
 	}
-	return nil
+	if f.ISBN != nil {
+		validateISBN(v, *f.ISBN)
+	}
+	if f.Shelves != nil {
+		validateLabels(v, "shelves", *f.Shelves)
+	}
+	if f.Tags != nil {
+		validateLabels(v, "tags", *f.Tags)
+	}
+	return v.err()
 }
@@ -0,0 +1,211 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxBookBatchSize は1回の一括操作で扱える本の数。status の履歴と合わせても Datastore の1トランザクションの上限に収まるようにする。
+const maxBookBatchSize = 100
+
+// BookBatchCreate は本の一括登録。Errs[i] があれば Books[i] は入力の不備で登録しない。
+type BookBatchCreate struct {
+	Atomic         bool
+	AllowDuplicate bool
+	Books          []BookCreateForm
+	Errs           []error
+}
+
+func NewBookBatchCreate(req *http.Request) (*BookBatchCreate, error) {
+	body, err := decodeBookBatch(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &BookBatchCreate{
+		Atomic:         body.Atomic,
+		AllowDuplicate: body.AllowDuplicate,
+		Books:          make([]BookCreateForm, len(body.Books)),
+		Errs:           make([]error, len(body.Books)),
+	}
+	for i, raw := range body.Books {
+		f := &r.Books[i]
+		if err := decodeBookBatchItem(raw, f); err != nil {
+			r.Errs[i] = err
+			continue
+		}
+		f.Shelves = normalizeLabels(f.Shelves)
+		f.Tags = normalizeLabels(f.Tags)
+		if err := f.ValidateBookCreateForm(); err != nil {
+			r.Errs[i] = err
+			continue
+		}
+		f.ISBN = normalizedISBN(f.ISBN)
+	}
+	return r, nil
+}
+
+// BookBatchUpdate は本の一括更新。Errs[i] があれば Books[i] は入力の不備で更新しない。
+type BookBatchUpdate struct {
+	Atomic bool
+	Books  []BookBatchUpdateItem
+	Errs   []error
+}
+
+func NewBookBatchUpdate(req *http.Request) (*BookBatchUpdate, error) {
+	body, err := decodeBookBatch(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &BookBatchUpdate{
+		Atomic: body.Atomic,
+		Books:  make([]BookBatchUpdateItem, len(body.Books)),
+		Errs:   make([]error, len(body.Books)),
+	}
+	seen := map[int]bool{}
+	for i, raw := range body.Books {
+		item := &r.Books[i]
+		if err := decodeBookBatchItem(raw, item); err != nil {
+			r.Errs[i] = err
+			continue
+		}
+		if item.Shelves != nil {
+			*item.Shelves = normalizeLabels(*item.Shelves)
+		}
+		if item.Tags != nil {
+			*item.Tags = normalizeLabels(*item.Tags)
+		}
+		if err := item.ValidateBookBatchUpdateItem(seen); err != nil {
+			r.Errs[i] = err
+			continue
+		}
+		if item.ISBN != nil {
+			*item.ISBN = normalizedISBN(*item.ISBN)
+		}
+	}
+	return r, nil
+}
+
+// BookBatchDelete は本をまとめてゴミ箱に入れる。Errs[i] があれば Books[i] は入力の不備でゴミ箱に入れない。
+type BookBatchDelete struct {
+	Atomic bool
+	Books  []BookBatchDeleteItem
+	Errs   []error
+}
+
+func NewBookBatchDelete(req *http.Request) (*BookBatchDelete, error) {
+	body, err := decodeBookBatch(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &BookBatchDelete{
+		Atomic: body.Atomic,
+		Books:  make([]BookBatchDeleteItem, len(body.Books)),
+		Errs:   make([]error, len(body.Books)),
+	}
+	seen := map[int]bool{}
+	for i, raw := range body.Books {
+		item := &r.Books[i]
+		if err := decodeBookBatchItem(raw, item); err != nil {
+			r.Errs[i] = err
+			continue
+		}
+		v := &ValidationError{}
+		validateBookBatchTarget(v, item.ID, item.Version, seen)
+		r.Errs[i] = v.err()
+	}
+	return r, nil
+}
+
+// bookBatchBody は一括操作のボディ。1件ずつ読んで項目ごとにエラーを返せるよう、books は JSON のまま受け取る。
+type bookBatchBody struct {
+	Books          []json.RawMessage `json:"books"`
+	Atomic         bool              `json:"atomic"`
+	AllowDuplicate bool              `json:"allowDuplicate"`
+}
+
+func decodeBookBatch(req *http.Request) (*bookBatchBody, error) {
+	body := &bookBatchBody{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(body.Books) == 0:
+		return nil, InvalidField("books", "books is required")
+	case len(body.Books) > maxBookBatchSize:
+		return nil, InvalidField("books", fmt.Sprintf("books must have at most %d items", maxBookBatchSize))
+	}
+	return body, nil
+}
+
+// decodeBookBatchItem は books の1件を読む。型の合わない項目は、その項目名の *ValidationError にする。
+func decodeBookBatchItem(raw json.RawMessage, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+		if typeErr.Field == "" {
+			return InvalidField("books", "each item of books must be an object")
+		}
+		return InvalidField(typeErr.Field, typeErr.Field+" has an invalid type")
+	}
+	return nil
+}
+
+// validateBookBatchTarget は一括更新・削除の対象の本を確かめる。同じ本を2回指定したときは2回目以降をエラーにする。
+func validateBookBatchTarget(v *ValidationError, id int, version *int, seen map[int]bool) {
+	switch {
+	case id <= 0:
+		v.add("id", "id must be a positive integer")
+	case seen[id]:
+		v.add("id", "id appears more than once in the batch")
+	default:
+		seen[id] = true
+	}
+	if version != nil && *version < 1 {
+		v.add("version", "version must be a positive integer")
+	}
+}
+
+// ---
+
+// BookBatchCreateForm の books は1件ずつ BookCreateForm として確かめ、通らなかったものだけを失敗にする。
+type BookBatchCreateForm struct {
+	Books          []BookCreateForm `json:"books"`          // 1〜100 件
+	Atomic         bool             `json:"atomic"`         // true なら1冊でも失敗すれば1冊も登録しない
+	AllowDuplicate bool             `json:"allowDuplicate"` // true なら登録済みの本と同じらしくても登録する
+}
+
+type BookBatchUpdateForm struct {
+	Books  []BookBatchUpdateItem `json:"books"`  // 1〜100 件
+	Atomic bool                  `json:"atomic"` // true なら1冊でも失敗すれば1冊も更新しない
+}
+
+// BookBatchUpdateItem は1冊分の更新。id のほかは BookUpdateForm と同じく送った項目だけ更新する。
+type BookBatchUpdateItem struct {
+	ID      int  `json:"id"`
+	Version *int `json:"version"` // 任意。If-Match と同じく、今の本がこの version のときだけ更新する
+	BookUpdateForm
+}
+
+func (f BookBatchUpdateItem) ValidateBookBatchUpdateItem(seen map[int]bool) error {
+	v := &ValidationError{}
+	validateBookBatchTarget(v, f.ID, f.Version, seen)
+	var verr *ValidationError
+	if errors.As(f.ValidateBookUpdateForm(), &verr) {
+		v.Fields = append(v.Fields, verr.Fields...)
+	}
+	return v.err()
+}
+
+type BookBatchDeleteForm struct {
+	Books  []BookBatchDeleteItem `json:"books"`  // 1〜100 件
+	Atomic bool                  `json:"atomic"` // true なら1冊でも失敗すれば1冊もゴミ箱に入れない
+}
+
+type BookBatchDeleteItem struct {
+	ID      int  `json:"id"`
+	Version *int `json:"version"` // 任意。If-Match と同じく、今の本がこの version のときだけゴミ箱に入れる
+}
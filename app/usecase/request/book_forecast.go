@@ -0,0 +1,37 @@
+package request
+
+import (
+	"net/http"
+	"time"
+)
+
+// BookForecast の tz は IANA のタイムゾーン名（例: Asia/Tokyo）。省略時は UTC で「今日」を決める。
+type BookForecast struct {
+	BookID   int
+	Location *time.Location
+}
+
+func NewBookForecast(req *http.Request) (*BookForecast, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := locationParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &BookForecast{BookID: id, Location: loc}, nil
+}
+
+// locationParam は ?tz を読む。省略時は UTC。
+func locationParam(req *http.Request) (*time.Location, error) {
+	tz := req.URL.Query().Get("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, InvalidField("tz", "tz must be an IANA time zone name (e.g. Asia/Tokyo)")
+	}
+	return loc, nil
+}
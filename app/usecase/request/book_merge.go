@@ -0,0 +1,43 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// BookMerge は sourceId の本を targetId の本にまとめる。
+type BookMerge struct {
+	BookMergeForm
+}
+
+func NewBookMerge(req *http.Request) (*BookMerge, error) {
+	r := &BookMerge{}
+	if err := json.NewDecoder(req.Body).Decode(&r.BookMergeForm); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateBookMergeForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ---
+
+// BookMergeForm の targetId は残す本、sourceId は target に移してから消す本。
+type BookMergeForm struct {
+	TargetID int `json:"targetId"`
+	SourceID int `json:"sourceId"`
+}
+
+func (f BookMergeForm) ValidateBookMergeForm() error {
+	v := &ValidationError{}
+	if f.TargetID <= 0 {
+		v.add("targetId", "targetId must be a positive integer")
+	}
+	if f.SourceID <= 0 {
+		v.add("sourceId", "sourceId must be a positive integer")
+	} else if f.SourceID == f.TargetID {
+		v.add("sourceId", "sourceId must differ from targetId")
+	}
+	return v.err()
+}
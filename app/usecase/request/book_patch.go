@@ -0,0 +1,486 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+const (
+	MediaTypeMergePatch = "application/merge-patch+json" // RFC 7396
+	MediaTypeJSONPatch  = "application/json-patch+json"  // RFC 6902
+)
+
+// ErrUnsupportedPatch は PATCH の Content-Type が merge-patch / json-patch のどちらでもないときに返す。controller で 415 に変換する。
+var ErrUnsupportedPatch = errors.New("Content-Type must be " + MediaTypeMergePatch + " or " + MediaTypeJSONPatch)
+
+// ErrPatchTestFailed は JSON Patch の test 操作で値が一致しなかったときに返す。controller で 409 に変換する。
+var ErrPatchTestFailed = errors.New("json patch test operation failed")
+
+// bookPatchFields は PATCH で書き換えられる本の項目。登録時に送れる項目（BookCreateForm）と同じ。
+var bookPatchFields = jsonFieldNames(reflect.TypeOf(BookCreateForm{}))
+
+// BookPatch は本の部分更新。merge-patch なら Merge、json-patch なら Ops を今の本に当てる。
+// 当てた結果は登録時と同じ ValidateBookCreateForm で確かめる。ただしパッチで変えていない項目の不備は問わない
+// （CSV から取り込んだ本には targetCompleteDate・表紙画像がないことがある）。
+type BookPatch struct {
+	BookID  int
+	IfMatch *IfMatch
+	Merge   map[string]any
+	Ops     []JSONPatchOp
+}
+
+// JSONPatchOp は RFC 6902 の操作1つ。Value は add / replace / test のときだけ使う（null と省略を見分けるため RawMessage）。
+type JSONPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func NewBookPatch(req *http.Request) (*BookPatch, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	ifMatch, err := ifMatchHeader(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &BookPatch{BookID: id, IfMatch: ifMatch}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case MediaTypeMergePatch:
+		if err := json.NewDecoder(req.Body).Decode(&r.Merge); err != nil {
+			return nil, err
+		}
+		if r.Merge == nil {
+			return nil, InvalidField("body", "merge patch must be a JSON object")
+		}
+	case MediaTypeJSONPatch:
+		if err := json.NewDecoder(req.Body).Decode(&r.Ops); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedPatch
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate はパッチが書き換えられる項目だけを指しているかを確かめる。値が正しいかは Apply で当てた結果を確かめる。
+func (r BookPatch) Validate() error {
+	v := &ValidationError{}
+	for name := range r.Merge {
+		if !slices.Contains(bookPatchFields, name) {
+			v.add(name, name+" cannot be changed")
+		}
+	}
+	for i, op := range r.Ops {
+		field := fmt.Sprintf("patch[%d]", i)
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				v.add(field+".value", "value is required for "+op.Op)
+			}
+		case "move", "copy":
+			if err := validPatchPath(op.From); err != nil {
+				v.add(field+".from", err.Error())
+			}
+		case "remove":
+		default:
+			v.add(field+".op", "op must be add, remove, replace, move, copy, or test")
+			continue
+		}
+		if err := validPatchPath(op.Path); err != nil {
+			v.add(field+".path", err.Error())
+		}
+	}
+	return v.err()
+}
+
+// validPatchPath は JSON Pointer が書き換えられる項目（かその中）を指しているかを確かめる。
+func validPatchPath(path string) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errors.New("path must point to a field of the book")
+	}
+	if !slices.Contains(bookPatchFields, tokens[0]) {
+		return fmt.Errorf("%s cannot be changed", tokens[0])
+	}
+	return nil
+}
+
+// Apply はパッチを book に当てる。当てた結果が登録時の条件を満たさなければ *ValidationError を返し、book は途中まで書き換わることがある。
+// thumbnailId を変えたときの画像を付ける処理・thumbnailUrl の書き換えは service で行う。
+func (r BookPatch) Apply(book *entity.Book) error {
+	before := bookPatchDocument(book)
+	doc := bookPatchDocument(book)
+	var patched any = doc
+	if r.Ops != nil {
+		var err error
+		if patched, err = applyJSONPatch(doc, r.Ops); err != nil {
+			return err
+		}
+	} else {
+		patched = mergePatch(doc, r.Merge)
+	}
+	if m, ok := patched.(map[string]any); ok {
+		// もとから null の項目（取り込んだ本の targetCompleteDate など）は、null のままならないものとして読む
+		for name, value := range m {
+			if value == nil && before[name] == nil {
+				delete(m, name)
+			}
+		}
+	}
+	form, err := decodeBookPatch(patched)
+	if err != nil {
+		return err
+	}
+	switch {
+	case form.ThumbnailID == "" && book.ThumbnailID != "" && form.ThumbnailUrl == book.ThumbnailUrl:
+		// 画像を外したときは、その画像を指している thumbnailUrl も外す
+		form.ThumbnailUrl = ""
+	case form.ThumbnailID != "" && form.ThumbnailID == book.ThumbnailID && form.ThumbnailUrl != book.ThumbnailUrl:
+		// 外部の URL に差し替えたときはアップロード済みの画像を外す
+		form.ThumbnailID = ""
+	}
+	form.Shelves = normalizeLabels(form.Shelves)
+	form.Tags = normalizeLabels(form.Tags)
+	if err := changedFieldErrors(form.ValidateBookCreateForm(), before, bookFormDocument(*form)); err != nil {
+		return err
+	}
+	book.Title = form.Title
+	book.Author = form.Author
+	book.TotalPages = form.TotalPages
+	book.Publisher = form.Publisher
+	book.ISBN = normalizedISBN(form.ISBN)
+	book.ThumbnailUrl = form.ThumbnailUrl
+	book.ThumbnailID = form.ThumbnailID
+	book.Status = entity.Status(form.Status)
+	book.TargetCompleteDate = form.TargetCompleteDate.Time()
+	book.EncounterNote = form.EncounterNote
+	book.ReadPages = form.ReadPages
+	book.TargetPagesPerDay = form.TargetPagesPerDay
+	book.Shelves = form.Shelves
+	book.Tags = form.Tags
+	return nil
+}
+
+// bookPatchRelated は ValidateBookCreateForm のエラーの項目と、そのエラーに関わる項目。ここにない項目はその項目だけが関わる。
+var bookPatchRelated = map[string][]string{
+	"thumbnailId": {"thumbnailId", "thumbnailUrl"},
+	"status":      {"status", "readPages", "totalPages"},
+	"readPages":   {"readPages", "totalPages"},
+}
+
+// changedFieldErrors は err の項目ごとのエラーのうち、関わる項目がパッチで変わったものだけを返す。
+// before・after はパッチを当てる前・当てた後の文書（bookPatchDocument・bookFormDocument）。
+func changedFieldErrors(err error, before, after map[string]any) error {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	v := &ValidationError{}
+	for _, f := range verr.Fields {
+		related, ok := bookPatchRelated[f.Field]
+		if !ok {
+			related = []string{f.Field}
+		}
+		for _, name := range related {
+			if !reflect.DeepEqual(before[name], after[name]) {
+				v.Fields = append(v.Fields, f)
+				break
+			}
+		}
+	}
+	return v.err()
+}
+
+// bookPatchDocument は book の書き換えられる項目を、パッチを当てる JSON の文書にする。shelves / tags はないときも [] にする。
+func bookPatchDocument(book *entity.Book) map[string]any {
+	form := BookCreateForm{
+		Title:              book.Title,
+		Author:             book.Author,
+		TotalPages:         book.TotalPages,
+		Publisher:          book.Publisher,
+		ISBN:               book.ISBN,
+		ThumbnailUrl:       book.ThumbnailUrl,
+		ThumbnailID:        book.ThumbnailID,
+		Status:             string(book.Status),
+		TargetCompleteDate: NormalizedDate(book.TargetCompleteDate),
+		EncounterNote:      book.EncounterNote,
+		ReadPages:          book.ReadPages,
+		TargetPagesPerDay:  book.TargetPagesPerDay,
+		Shelves:            book.Shelves,
+		Tags:               book.Tags,
+	}
+	return bookFormDocument(form)
+}
+
+// bookFormDocument は form を JSON の文書にする。shelves / tags はないときも [] にする。
+func bookFormDocument(form BookCreateForm) map[string]any {
+	form.Shelves = append([]string{}, form.Shelves...)
+	form.Tags = append([]string{}, form.Tags...)
+	var doc map[string]any
+	b, _ := json.Marshal(form)
+	json.Unmarshal(b, &doc)
+	return doc
+}
+
+// decodeBookPatch はパッチを当てた文書を BookCreateForm に読む。null・消した項目はゼロ値になる。
+func decodeBookPatch(doc any) (*BookCreateForm, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	form := &BookCreateForm{}
+	if err := json.Unmarshal(b, form); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, InvalidField(typeErr.Field, typeErr.Field+" has an invalid type")
+		}
+		return nil, err
+	}
+	return form, nil
+}
+
+// mergePatch は RFC 7396 の JSON Merge Patch を target に当てる。null の項目は消す。
+func mergePatch(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+		} else {
+			t[name] = mergePatch(t[name], value)
+		}
+	}
+	return t
+}
+
+// applyJSONPatch は RFC 6902 の JSON Patch を順に当てる。途中で失敗したらそこでやめる（doc は書き換わっている）。
+func applyJSONPatch(doc any, ops []JSONPatchOp) (any, error) {
+	for i, op := range ops {
+		field := fmt.Sprintf("patch[%d]", i)
+		path, _ := parsePointer(op.Path)
+		var value any
+		if op.Value != nil {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, InvalidField(field+".value", "value must be JSON")
+			}
+		}
+		var err error
+		switch op.Op {
+		case "add":
+			doc, err = addValue(doc, path, value, false)
+		case "replace":
+			doc, err = addValue(doc, path, value, true)
+		case "remove":
+			doc, _, err = removeValue(doc, path)
+		case "move", "copy":
+			from, _ := parsePointer(op.From)
+			var moved any
+			if op.Op == "move" {
+				doc, moved, err = removeValue(doc, from)
+			} else if moved, err = getValue(doc, from); err == nil {
+				moved = cloneJSON(moved)
+			}
+			if err != nil {
+				return nil, InvalidField(field+".from", err.Error())
+			}
+			doc, err = addValue(doc, path, moved, false)
+		case "test":
+			var current any
+			if current, err = getValue(doc, path); err == nil && !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: %s", ErrPatchTestFailed, op.Path)
+			}
+		}
+		if err != nil {
+			return nil, InvalidField(field+".path", err.Error())
+		}
+	}
+	return doc, nil
+}
+
+// parsePointer は RFC 6901 の JSON Pointer を参照トークンに分ける。"" は文書全体。
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("path must be a JSON Pointer starting with /")
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getValue は doc の path の値。
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", token)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, fmt.Errorf("%s does not exist", token)
+		}
+	}
+	return doc, nil
+}
+
+// addValue は doc の path に value を入れた doc を返す。replace なら path にすでに値があること（配列には挿入せず置き換える）。
+func addValue(doc any, path []string, value any, replace bool) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+	switch c := doc.(type) {
+	case map[string]any:
+		child, ok := c[token]
+		if !ok && (replace || len(rest) > 0) {
+			return nil, fmt.Errorf("%s does not exist", token)
+		}
+		v, err := addValue(child, rest, value, replace)
+		if err != nil {
+			return nil, err
+		}
+		c[token] = v
+		return c, nil
+	case []any:
+		if len(rest) == 0 && !replace {
+			// 配列への add は挿入。"-" は末尾
+			i := len(c)
+			if token != "-" {
+				var err error
+				if i, err = arrayIndex(token, len(c)); err != nil {
+					return nil, err
+				}
+			}
+			return slices.Insert(c, i, value), nil
+		}
+		i, err := arrayIndex(token, len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := addValue(c[i], rest, value, replace)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = v
+		return c, nil
+	}
+	return nil, fmt.Errorf("%s does not exist", token)
+}
+
+// removeValue は doc の path の値を取り除いた doc と、取り除いた値を返す。
+func removeValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole book")
+	}
+	token, rest := path[0], path[1:]
+	switch c := doc.(type) {
+	case map[string]any:
+		child, ok := c[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s does not exist", token)
+		}
+		if len(rest) == 0 {
+			delete(c, token)
+			return c, child, nil
+		}
+		v, removed, err := removeValue(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		c[token] = v
+		return c, removed, nil
+	case []any:
+		i, err := arrayIndex(token, len(c)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := c[i]
+			return slices.Delete(c, i, i+1), removed, nil
+		}
+		v, removed, err := removeValue(c[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		c[i] = v
+		return c, removed, nil
+	}
+	return nil, nil, fmt.Errorf("%s does not exist", token)
+}
+
+// arrayIndex は配列の添字のトークンを読む。0〜max の範囲でなければエラー。
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%s is not a valid array index", token)
+	}
+	return i, nil
+}
+
+// cloneJSON は JSON から読んだ値（map / slice）を深くコピーする。
+func cloneJSON(v any) any {
+	switch c := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(c))
+		for k, e := range c {
+			out[k] = cloneJSON(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(c))
+		for i, e := range c {
+			out[i] = cloneJSON(e)
+		}
+		return out
+	}
+	return v
+}
+
+// jsonFieldNames は構造体の JSON の項目名。
+func jsonFieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
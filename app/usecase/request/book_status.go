@@ -0,0 +1,56 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type BookStatusChange struct {
+	BookID int
+	BookStatusChangeForm
+}
+
+func NewBookStatusChange(req *http.Request) (*BookStatusChange, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &BookStatusChange{BookID: id}
+	if err := json.NewDecoder(req.Body).Decode(&r.BookStatusChangeForm); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateBookStatusChangeForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type BookStatusHistory struct {
+	BookID int
+}
+
+func NewBookStatusHistory(req *http.Request) (*BookStatusHistory, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &BookStatusHistory{BookID: id}, nil
+}
+
+// ---
+
+// BookStatusChangeForm の action は start / pause / finish / abandon / reread のいずれか。
+// 今の status からその操作ができるかは domain 層で確認する。
+type BookStatusChangeForm struct {
+	Action string `json:"action"`
+}
+
+func (f BookStatusChangeForm) ValidateBookStatusChangeForm() error {
+	switch f.Action {
+	case "":
+		return InvalidField("action", "action is required")
+	case "start", "pause", "finish", "abandon", "reread":
+		return nil
+	}
+	return InvalidField("action", "action must be start, pause, finish, abandon, or reread")
+}
@@ -0,0 +1,272 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxHighlightTextLength    = 5000
+	maxHighlightCommentLength = 5000
+	maxHighlightFieldLength   = 100 // chapter / category
+	defaultHighlightFeedLimit = 50
+	maxHighlightFeedLimit     = 100
+)
+
+type HighlightGet struct {
+	BookID int
+}
+
+func NewHighlightGet(req *http.Request) (*HighlightGet, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &HighlightGet{BookID: id}, nil
+}
+
+type HighlightGetByID struct {
+	BookID      int
+	HighlightID int
+}
+
+func NewHighlightGetByID(req *http.Request) (*HighlightGetByID, error) {
+	id, hid, err := highlightIDParams(req)
+	if err != nil {
+		return nil, err
+	}
+	return &HighlightGetByID{BookID: id, HighlightID: hid}, nil
+}
+
+type HighlightCreate struct {
+	BookID int
+	HighlightCreateForm
+}
+
+func NewHighlightCreate(req *http.Request) (*HighlightCreate, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &HighlightCreate{BookID: id}
+	if err := json.NewDecoder(req.Body).Decode(&r.HighlightCreateForm); err != nil {
+		return nil, err
+	}
+	if r.Color == "" {
+		r.Color = "yellow"
+	}
+	r.Category = strings.TrimSpace(r.Category)
+	if err := r.ValidateHighlightCreateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type HighlightUpdate struct {
+	BookID      int
+	HighlightID int
+	HighlightUpdateForm
+}
+
+func NewHighlightUpdate(req *http.Request) (*HighlightUpdate, error) {
+	id, hid, err := highlightIDParams(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &HighlightUpdate{BookID: id, HighlightID: hid}
+	if err := json.NewDecoder(req.Body).Decode(&r.HighlightUpdateForm); err != nil {
+		return nil, err
+	}
+	if r.Category != nil {
+		*r.Category = strings.TrimSpace(*r.Category)
+	}
+	if err := r.ValidateHighlightUpdateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type HighlightDelete struct {
+	BookID      int
+	HighlightID int
+}
+
+func NewHighlightDelete(req *http.Request) (*HighlightDelete, error) {
+	id, hid, err := highlightIDParams(req)
+	if err != nil {
+		return nil, err
+	}
+	return &HighlightDelete{BookID: id, HighlightID: hid}, nil
+}
+
+// HighlightFeed は本をまたいだハイライト一覧のクエリパラメータ。新しい順に返す。
+type HighlightFeed struct {
+	BookID    int
+	Color     string
+	Category  string
+	Limit     int
+	PageToken string
+}
+
+func NewHighlightFeed(req *http.Request) (*HighlightFeed, error) {
+	q := req.URL.Query()
+	r := &HighlightFeed{
+		Color:     q.Get("color"),
+		Category:  q.Get("category"),
+		Limit:     defaultHighlightFeedLimit,
+		PageToken: q.Get("pageToken"),
+	}
+	v := &ValidationError{}
+	if s := q.Get("bookId"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			v.add("bookId", "bookId must be an integer")
+		}
+		r.BookID = id
+	}
+	if r.Color != "" && !validHighlightColor(r.Color) {
+		v.add("color", "color must be yellow, green, blue, pink, or purple")
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		switch {
+		case err != nil:
+			v.add("limit", "limit must be an integer")
+		case limit < 1 || limit > maxHighlightFeedLimit:
+			v.add("limit", "limit must be between 1 and 100")
+		default:
+			r.Limit = limit
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// HighlightDaily の tz は「今日」を決める IANA のタイムゾーン名。省略時は UTC。
+type HighlightDaily struct {
+	Location *time.Location
+}
+
+func NewHighlightDaily(req *http.Request) (*HighlightDaily, error) {
+	loc := time.UTC
+	if tz := req.URL.Query().Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, InvalidField("tz", "tz must be an IANA time zone name (e.g. Asia/Tokyo)")
+		}
+	}
+	return &HighlightDaily{Location: loc}, nil
+}
+
+// highlightIDParams は URL の {id} と {highlightId} を読む。
+func highlightIDParams(req *http.Request) (int, int, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	hidStr := chi.URLParam(req, "highlightId")
+	if hidStr == "" {
+		return 0, 0, InvalidField("highlightId", "highlight id is required")
+	}
+	hid, err := strconv.Atoi(hidStr)
+	if err != nil {
+		return 0, 0, InvalidField("highlightId", "invalid highlight id")
+	}
+	return id, hid, nil
+}
+
+func validHighlightColor(s string) bool {
+	switch s {
+	case "yellow", "green", "blue", "pink", "purple":
+		return true
+	}
+	return false
+}
+
+// ---
+
+// HighlightCreateForm の color は省略すると yellow。ページが本の totalPages を超えていないかは domain 層で確認する。
+type HighlightCreateForm struct {
+	Text     string `json:"text"`
+	Page     int    `json:"page"`
+	Chapter  string `json:"chapter"`  // 任意
+	Comment  string `json:"comment"`  // 任意
+	Color    string `json:"color"`    // yellow / green / blue / pink / purple
+	Category string `json:"category"` // 任意
+}
+
+func (f HighlightCreateForm) ValidateHighlightCreateForm() error {
+	v := &ValidationError{}
+	switch {
+	case strings.TrimSpace(f.Text) == "":
+		v.add("text", "text is required")
+	case utf8.RuneCountInString(f.Text) > maxHighlightTextLength:
+		v.add("text", fmt.Sprintf("text must be at most %d characters", maxHighlightTextLength))
+	}
+	if f.Page < 1 {
+		v.add("page", "page must be 1 or greater")
+	}
+	validateHighlightOptional(v, f.Chapter, f.Comment, f.Category)
+	if !validHighlightColor(f.Color) {
+		v.add("color", "color must be yellow, green, blue, pink, or purple")
+	}
+	return v.err()
+}
+
+// HighlightUpdateForm は送った項目だけ更新する。
+type HighlightUpdateForm struct {
+	Text     *string `json:"text"`
+	Page     *int    `json:"page"`
+	Chapter  *string `json:"chapter"`
+	Comment  *string `json:"comment"`
+	Color    *string `json:"color"`
+	Category *string `json:"category"`
+}
+
+func (f HighlightUpdateForm) ValidateHighlightUpdateForm() error {
+	v := &ValidationError{}
+	if f.Text != nil {
+		switch {
+		case strings.TrimSpace(*f.Text) == "":
+			v.add("text", "text must not be empty")
+		case utf8.RuneCountInString(*f.Text) > maxHighlightTextLength:
+			v.add("text", fmt.Sprintf("text must be at most %d characters", maxHighlightTextLength))
+		}
+	}
+	if f.Page != nil && *f.Page < 1 {
+		v.add("page", "page must be 1 or greater")
+	}
+	validateHighlightOptional(v, deref(f.Chapter), deref(f.Comment), deref(f.Category))
+	if f.Color != nil && !validHighlightColor(*f.Color) {
+		v.add("color", "color must be yellow, green, blue, pink, or purple")
+	}
+	return v.err()
+}
+
+func validateHighlightOptional(v *ValidationError, chapter, comment, category string) {
+	if utf8.RuneCountInString(chapter) > maxHighlightFieldLength {
+		v.add("chapter", fmt.Sprintf("chapter must be at most %d characters", maxHighlightFieldLength))
+	}
+	if utf8.RuneCountInString(comment) > maxHighlightCommentLength {
+		v.add("comment", fmt.Sprintf("comment must be at most %d characters", maxHighlightCommentLength))
+	}
+	if utf8.RuneCountInString(category) > maxHighlightFieldLength {
+		v.add("category", fmt.Sprintf("category must be at most %d characters", maxHighlightFieldLength))
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
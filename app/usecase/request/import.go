@@ -0,0 +1,361 @@
+package request
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxImportFileSize = 10 << 20
+	maxImportRows     = 5000
+)
+
+// importFields は CSV の列を読み込める本の項目。mapping のキーに使う。
+var importFields = []string{
+	"title", "author", "publisher", "totalPages", "isbn", "status", "thumbnailUrl",
+	"encounterNote", "tags", "addedOn", "finishedOn", "rating", "review", "spoiler",
+}
+
+// goodreadsMapping は Goodreads の「Export Library」の CSV の列。isbn は ISBN13 が空なら ISBN（10 桁）を使う。
+var goodreadsMapping = map[string]string{
+	"title":         "Title",
+	"author":        "Author",
+	"publisher":     "Publisher",
+	"totalPages":    "Number of Pages",
+	"isbn":          "ISBN13",
+	"status":        "Exclusive Shelf",
+	"encounterNote": "Private Notes",
+	"tags":          "Bookshelves",
+	"addedOn":       "Date Added",
+	"finishedOn":    "Date Read",
+	"rating":        "My Rating",
+	"review":        "My Review",
+	"spoiler":       "Spoiler",
+}
+
+// importStatuses は status の列の値（小文字）と本の status。Goodreads の既定の棚と、よくある自分で作った棚の名前も受け付ける。
+var importStatuses = map[string]string{
+	"unread":            "unread",
+	"reading":           "reading",
+	"paused":            "paused",
+	"completed":         "completed",
+	"abandoned":         "abandoned",
+	"to-read":           "unread",
+	"currently-reading": "reading",
+	"read":              "completed",
+	"on-hold":           "paused",
+	"did-not-finish":    "abandoned",
+	"dnf":               "abandoned",
+}
+
+// importDateLayouts は日付の列で受け付ける書き方。Goodreads は 2006/01/02。
+var importDateLayouts = []string{"2006-01-02", "2006/01/02", "2006/1/2", "2006-1-2", time.RFC3339}
+
+// ImportCSV は CSV の取り込み。Rows[i].Err があればその行は入力の不備で登録しない。
+type ImportCSV struct {
+	Format string
+	DryRun bool
+	Rows   []ImportCSVRow
+}
+
+// ImportCSVRow は CSV の1行を本の項目に読み替えたもの。Line は CSV の行番号（ヘッダーが1行目）。
+// 日付は 00:00:00Z、Rating は 0 なら評価なし。
+type ImportCSVRow struct {
+	Line          int
+	Title         string
+	Author        string
+	Publisher     string
+	TotalPages    int
+	ISBN          string
+	Status        string
+	ThumbnailUrl  string
+	EncounterNote string
+	Tags          []string
+	AddedOn       time.Time
+	FinishedOn    time.Time
+	Rating        float64
+	Review        string
+	Spoiler       bool
+	Err           error
+}
+
+func NewImportCSV(req *http.Request) (*ImportCSV, error) {
+	if err := req.ParseMultipartForm(maxImportFileSize); err != nil {
+		return nil, InvalidField("file", "request must be multipart/form-data with a CSV file of at most 10MB")
+	}
+	r := &ImportCSV{Format: req.FormValue("format")}
+	v := &ValidationError{}
+	if r.Format == "" {
+		r.Format = "goodreads"
+	}
+	if s := req.FormValue("dryRun"); s != "" {
+		dryRun, err := strconv.ParseBool(s)
+		if err != nil {
+			v.add("dryRun", "dryRun must be true or false")
+		}
+		r.DryRun = dryRun
+	}
+	mapping := map[string]string{}
+	if s := req.FormValue("mapping"); s != "" {
+		if err := json.Unmarshal([]byte(s), &mapping); err != nil {
+			v.add("mapping", "mapping must be a JSON object of field name to CSV column name")
+		}
+	}
+	for _, field := range slices.Sorted(maps.Keys(mapping)) {
+		if !slices.Contains(importFields, field) {
+			v.add("mapping", fmt.Sprintf("mapping has an unknown field %q (use %s)", field, strings.Join(importFields, ", ")))
+		}
+	}
+	switch r.Format {
+	case "goodreads":
+	case "generic":
+		for _, field := range []string{"title", "author", "totalPages"} {
+			if mapping[field] == "" {
+				v.add("mapping", fmt.Sprintf("mapping.%s is required for the generic format", field))
+			}
+		}
+	default:
+		v.add("format", "format must be goodreads or generic")
+	}
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		v.add("file", "file is required")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := readImportCSV(file, r.Format, mapping)
+	if err != nil {
+		return nil, err
+	}
+	r.Rows = rows
+	return r, nil
+}
+
+// importColumns は項目ごとの CSV の列の位置。CSV にない列の項目は入れない。
+type importColumns map[string]int
+
+func (c importColumns) get(record []string, field string) string {
+	i, ok := c[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// readImportCSV は CSV を読み、ヘッダーから mapping の列を探して1行ずつ読み替える。
+// goodreads なら mapping は Goodreads の列を上書きするだけで、CSV にない Goodreads の列は読まない（古いエクスポートにない列がある）。
+func readImportCSV(file io.Reader, format string, mapping map[string]string) ([]ImportCSVRow, error) {
+	cr := csv.NewReader(file)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, InvalidField("file", "file is empty")
+		}
+		return nil, InvalidField("file", "file is not a valid CSV: "+err.Error())
+	}
+	positions := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // Excel で保存した CSV の BOM
+		}
+		positions[strings.TrimSpace(name)] = i
+	}
+
+	columns := importColumns{}
+	v := &ValidationError{}
+	if format == "goodreads" {
+		for field, name := range goodreadsMapping {
+			if i, ok := positions[name]; ok {
+				columns[field] = i
+			}
+		}
+		if i, ok := positions["ISBN"]; ok {
+			columns["isbn10"] = i
+		}
+		for _, field := range []string{"title", "author"} {
+			if _, ok := columns[field]; !ok && mapping[field] == "" {
+				v.add("file", fmt.Sprintf("column %q is not in the CSV header (is this a Goodreads export?)", goodreadsMapping[field]))
+			}
+		}
+	}
+	for _, field := range slices.Sorted(maps.Keys(mapping)) {
+		name := mapping[field]
+		i, ok := positions[name]
+		if !ok {
+			v.add("mapping", fmt.Sprintf("column %q for %s is not in the CSV header", name, field))
+			continue
+		}
+		columns[field] = i
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var rows []ImportCSVRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, InvalidField("file", "file is not a valid CSV: "+err.Error())
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, InvalidField("file", fmt.Sprintf("file must have at most %d rows", maxImportRows))
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, readImportRow(line, record, columns, format == "goodreads"))
+	}
+	if len(rows) == 0 {
+		return nil, InvalidField("file", "file has no rows")
+	}
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readImportRow は1行を本の項目に読み替え、通らなかった項目をすべて Err にまとめる。
+// goodreads なら知らない棚の status は unread にする（自分で作った棚は tags にも入っている）。
+func readImportRow(line int, record []string, c importColumns, goodreads bool) ImportCSVRow {
+	row := ImportCSVRow{
+		Line:          line,
+		Title:         c.get(record, "title"),
+		Author:        c.get(record, "author"),
+		Publisher:     c.get(record, "publisher"),
+		ISBN:          unquoteISBN(c.get(record, "isbn")),
+		ThumbnailUrl:  c.get(record, "thumbnailUrl"),
+		EncounterNote: c.get(record, "encounterNote"),
+		Review:        c.get(record, "review"),
+	}
+	v := &ValidationError{}
+	if row.Title == "" {
+		v.add("title", "title is required")
+	}
+	if row.Author == "" {
+		v.add("author", "author is required")
+	}
+	pages := c.get(record, "totalPages")
+	if n, err := strconv.Atoi(pages); err == nil && n > 0 {
+		row.TotalPages = n
+	} else if pages == "" {
+		v.add("totalPages", "totalPages is required")
+	} else {
+		v.add("totalPages", fmt.Sprintf("totalPages must be a positive integer (got %q)", pages))
+	}
+	if row.ISBN == "" && goodreads {
+		row.ISBN = unquoteISBN(c.get(record, "isbn10"))
+	}
+	validateISBN(v, row.ISBN)
+	row.ISBN = normalizedISBN(row.ISBN)
+
+	status := strings.ToLower(c.get(record, "status"))
+	switch s, ok := importStatuses[status]; {
+	case status == "" || !ok && goodreads:
+		row.Status = "unread"
+	case !ok:
+		v.add("status", fmt.Sprintf("status must be unread, reading, paused, completed, or abandoned (got %q)", status))
+	default:
+		row.Status = s
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(c.get(record, "tags"), ",") {
+		// 既定の棚は status にしたので tags には入れない
+		if _, ok := importStatuses[strings.ToLower(strings.TrimSpace(tag))]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	row.Tags = normalizeLabels(tags)
+	validateLabels(v, "tags", row.Tags)
+
+	row.AddedOn = readImportDate(v, "addedOn", c.get(record, "addedOn"))
+	row.FinishedOn = readImportDate(v, "finishedOn", c.get(record, "finishedOn"))
+
+	if s := c.get(record, "rating"); s != "" {
+		rating, err := strconv.ParseFloat(s, 64)
+		switch {
+		case err != nil:
+			v.add("rating", fmt.Sprintf("rating must be a number (got %q)", s))
+		case rating == 0:
+			// Goodreads は評価していない本を 0 にする
+		case rating < 0.5 || rating > 5 || rating*2 != math.Trunc(rating*2):
+			v.add("rating", "rating must be between 0.5 and 5 in steps of 0.5")
+		default:
+			row.Rating = rating
+		}
+	}
+	if goodreads {
+		row.Review = strings.NewReplacer("<br/>", "\n", "<br />", "\n", "<br>", "\n").Replace(row.Review)
+	}
+	if utf8.RuneCountInString(row.Review) > maxReviewTextLength {
+		v.add("review", fmt.Sprintf("review must be at most %d characters", maxReviewTextLength))
+	}
+	if s := c.get(record, "spoiler"); s != "" {
+		spoiler, err := strconv.ParseBool(s)
+		if err != nil {
+			v.add("spoiler", fmt.Sprintf("spoiler must be true or false (got %q)", s))
+		}
+		row.Spoiler = spoiler
+	}
+	row.Err = v.err()
+	return row
+}
+
+// unquoteISBN は Goodreads が ISBN を ="0123456789" と書くのを外す。
+func unquoteISBN(s string) string {
+	return strings.Trim(strings.TrimPrefix(s, "="), `"`)
+}
+
+func readImportDate(v *ValidationError, field, s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+	}
+	v.add(field, fmt.Sprintf("%s must be YYYY-MM-DD or YYYY/MM/DD (got %q)", field, s))
+	return time.Time{}
+}
+
+// ImportJobGet は取り込みの進み具合の取得。
+type ImportJobGet struct {
+	JobID string
+}
+
+func NewImportJobGet(req *http.Request) (*ImportJobGet, error) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		return nil, InvalidField("id", "job id is required")
+	}
+	return &ImportJobGet{JobID: id}, nil
+}
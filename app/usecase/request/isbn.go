@@ -0,0 +1,54 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sora-00/booktracker-api/app/domain/isbn"
+)
+
+// ISBNLookup は ISBN から本の情報を引く。cover=true なら表紙画像を取り込み、thumbnailId を返す。
+type ISBNLookup struct {
+	ISBN  string // ISBN-13
+	Cover bool
+}
+
+func NewISBNLookup(req *http.Request) (*ISBNLookup, error) {
+	v := &ValidationError{}
+	code, err := isbn.Normalize(chi.URLParam(req, "isbn"))
+	if err != nil {
+		v.add("isbn", err.Error())
+	}
+	r := &ISBNLookup{ISBN: code}
+	switch req.URL.Query().Get("cover") {
+	case "", "false":
+	case "true":
+		r.Cover = true
+	default:
+		v.add("cover", "cover must be true or false")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// validateISBN は空でなければ ISBN-10 / ISBN-13 のチェックディジットまで確かめる。
+func validateISBN(v *ValidationError, s string) {
+	if s == "" {
+		return
+	}
+	if _, err := isbn.Normalize(s); err != nil {
+		v.add("isbn", err.Error())
+	}
+}
+
+// normalizedISBN は validateISBN を通った ISBN を ISBN-13 にする。空ならそのまま。
+func normalizedISBN(s string) string {
+	if s == "" {
+		return ""
+	}
+	code, _ := isbn.Normalize(s)
+	return code
+}
@@ -0,0 +1,42 @@
+package request
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// IfMatch は If-Match ヘッダー。Any は "*"、Versions は ETag（"<version>"）から読んだ本の version。
+// 弱い ETag（W/"..."）は強い比較では一致しないので読み飛ばす。
+type IfMatch struct {
+	Any      bool
+	Versions []int
+}
+
+// ifMatchHeader は If-Match を読む。ヘッダーがなければ nil。
+func ifMatchHeader(req *http.Request) (*IfMatch, error) {
+	values := req.Header.Values("If-Match")
+	if len(values) == 0 {
+		return nil, nil
+	}
+	m := &IfMatch{}
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			switch {
+			case tag == "":
+			case tag == "*":
+				m.Any = true
+			case strings.HasPrefix(tag, "W/"):
+			case len(tag) >= 2 && strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`):
+				// この API の ETag でないものはどの version にも一致しない
+				if v, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+					m.Versions = append(m.Versions, v)
+				}
+			default:
+				return nil, InvalidField("If-Match", `If-Match must be "*" or a list of quoted ETags`)
+			}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,209 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+const (
+	maxGoalTitleLength = 100
+	maxGoalTarget      = 1000000
+	maxGoalDays        = 3660 // custom の期間の最大日数（およそ10年）
+)
+
+type ReadingGoalGet struct{}
+
+func NewReadingGoalGet(req *http.Request) (*ReadingGoalGet, error) {
+	return &ReadingGoalGet{}, nil
+}
+
+type ReadingGoalGetByID struct {
+	GoalID int
+}
+
+func NewReadingGoalGetByID(req *http.Request) (*ReadingGoalGetByID, error) {
+	id, err := idParam(req, "goal")
+	if err != nil {
+		return nil, err
+	}
+	return &ReadingGoalGetByID{GoalID: id}, nil
+}
+
+type ReadingGoalCreate struct {
+	ReadingGoalCreateForm
+}
+
+func NewReadingGoalCreate(req *http.Request) (*ReadingGoalCreate, error) {
+	r := &ReadingGoalCreate{}
+	if err := json.NewDecoder(req.Body).Decode(&r.ReadingGoalCreateForm); err != nil {
+		return nil, err
+	}
+	r.Title = strings.TrimSpace(r.Title)
+	if err := r.ValidateReadingGoalCreateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ReadingGoalUpdate struct {
+	GoalID int
+	ReadingGoalUpdateForm
+}
+
+func NewReadingGoalUpdate(req *http.Request) (*ReadingGoalUpdate, error) {
+	id, err := idParam(req, "goal")
+	if err != nil {
+		return nil, err
+	}
+	r := &ReadingGoalUpdate{GoalID: id}
+	if err := json.NewDecoder(req.Body).Decode(&r.ReadingGoalUpdateForm); err != nil {
+		return nil, err
+	}
+	if r.Title != nil {
+		*r.Title = strings.TrimSpace(*r.Title)
+	}
+	if err := r.ValidateReadingGoalUpdateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ReadingGoalDelete struct {
+	GoalID int
+}
+
+func NewReadingGoalDelete(req *http.Request) (*ReadingGoalDelete, error) {
+	id, err := idParam(req, "goal")
+	if err != nil {
+		return nil, err
+	}
+	return &ReadingGoalDelete{GoalID: id}, nil
+}
+
+// ReadingGoalProgress の tz は「今日」と、読了・読書記録の日付を決めるタイムゾーン。省略時は UTC。
+type ReadingGoalProgress struct {
+	GoalID   int
+	Location *time.Location
+}
+
+func NewReadingGoalProgress(req *http.Request) (*ReadingGoalProgress, error) {
+	id, err := idParam(req, "goal")
+	if err != nil {
+		return nil, err
+	}
+	loc, err := locationParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadingGoalProgress{GoalID: id, Location: loc}, nil
+}
+
+// ---
+
+// ReadingGoalCreateForm の期間は period で決める。
+// year は year 年の1年間、month は year 年 month 月、custom は startDate〜endDate（YYYY-MM-DD、どちらも含む）。
+type ReadingGoalCreateForm struct {
+	Title     string `json:"title"` // 任意
+	Metric    string `json:"metric"`
+	Target    int    `json:"target"`
+	Period    string `json:"period"`
+	Year      int    `json:"year"`
+	Month     int    `json:"month"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+func (f ReadingGoalCreateForm) ValidateReadingGoalCreateForm() error {
+	v := &ValidationError{}
+	validateGoalTitle(v, f.Title)
+	switch entity.GoalMetric(f.Metric) {
+	case entity.GoalBooks, entity.GoalPages:
+	default:
+		v.add("metric", "metric must be books or pages")
+	}
+	validateGoalTarget(v, f.Target)
+	switch entity.GoalPeriod(f.Period) {
+	case entity.GoalYear:
+		validateGoalYear(v, f.Year)
+	case entity.GoalMonth:
+		validateGoalYear(v, f.Year)
+		if f.Month < 1 || f.Month > 12 {
+			v.add("month", "month must be between 1 and 12")
+		}
+	case entity.GoalCustom:
+		start, errStart := time.Parse("2006-01-02", f.StartDate)
+		if errStart != nil {
+			v.add("startDate", "startDate must be YYYY-MM-DD")
+		}
+		end, errEnd := time.Parse("2006-01-02", f.EndDate)
+		if errEnd != nil {
+			v.add("endDate", "endDate must be YYYY-MM-DD")
+		}
+		if errStart == nil && errEnd == nil {
+			if end.Before(start) {
+				v.add("endDate", "endDate must not be before startDate")
+			} else if end.Sub(start).Hours()/24 >= maxGoalDays {
+				v.add("endDate", fmt.Sprintf("the period must be at most %d days", maxGoalDays))
+			}
+		}
+	default:
+		v.add("period", "period must be year, month, or custom")
+	}
+	return v.err()
+}
+
+// Window は目標の期間の初日と最終日（その日の 00:00:00Z）。ValidateReadingGoalCreateForm を通ったものに使う。
+func (f ReadingGoalCreateForm) Window() (start, end time.Time) {
+	switch entity.GoalPeriod(f.Period) {
+	case entity.GoalYear:
+		start = time.Date(f.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	case entity.GoalMonth:
+		start = time.Date(f.Year, time.Month(f.Month), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+	start, _ = time.Parse("2006-01-02", f.StartDate)
+	end, _ = time.Parse("2006-01-02", f.EndDate)
+	return start, end
+}
+
+// ReadingGoalUpdateForm は送った項目だけ更新する。期間と metric は変えられない（変えるときは作り直す）。
+type ReadingGoalUpdateForm struct {
+	Title  *string `json:"title"`
+	Target *int    `json:"target"`
+}
+
+func (f ReadingGoalUpdateForm) ValidateReadingGoalUpdateForm() error {
+	v := &ValidationError{}
+	if f.Title != nil {
+		validateGoalTitle(v, *f.Title)
+	}
+	if f.Target != nil {
+		validateGoalTarget(v, *f.Target)
+	}
+	return v.err()
+}
+
+func validateGoalTitle(v *ValidationError, title string) {
+	if utf8.RuneCountInString(title) > maxGoalTitleLength {
+		v.add("title", fmt.Sprintf("title must be at most %d characters", maxGoalTitleLength))
+	}
+}
+
+func validateGoalTarget(v *ValidationError, target int) {
+	if target < 1 || target > maxGoalTarget {
+		v.add("target", fmt.Sprintf("target must be between 1 and %d", maxGoalTarget))
+	}
+}
+
+func validateGoalYear(v *ValidationError, year int) {
+	if year < 1900 || year > 9999 {
+		v.add("year", "year must be between 1900 and 9999")
+	}
+}
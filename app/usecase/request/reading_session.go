@@ -0,0 +1,95 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReadingSessionGet struct {
+	BookID int
+}
+
+func NewReadingSessionGet(req *http.Request) (*ReadingSessionGet, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadingSessionGet{BookID: id}, nil
+}
+
+type ReadingSessionCreate struct {
+	BookID int
+	ReadingSessionCreateForm
+}
+
+func NewReadingSessionCreate(req *http.Request) (*ReadingSessionCreate, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReadingSessionCreate{BookID: id}
+	if err := json.NewDecoder(req.Body).Decode(&r.ReadingSessionCreateForm); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateReadingSessionCreateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ReadingSessionDelete struct {
+	BookID    int
+	SessionID int
+}
+
+func NewReadingSessionDelete(req *http.Request) (*ReadingSessionDelete, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	sidStr := chi.URLParam(req, "sessionId")
+	if sidStr == "" {
+		return nil, InvalidField("sessionId", "session id is required")
+	}
+	sid, err := strconv.Atoi(sidStr)
+	if err != nil {
+		return nil, InvalidField("sessionId", "invalid session id")
+	}
+	return &ReadingSessionDelete{BookID: id, SessionID: sid}, nil
+}
+
+// ---
+
+// ReadingSessionCreateForm の startedAt / endedAt は RFC 3339（例: 2026-10-18T21:00:00+09:00）。
+// endPage が本の totalPages を超えていないかは domain 層で確認する。
+type ReadingSessionCreateForm struct {
+	StartPage int       `json:"startPage"`
+	EndPage   int       `json:"endPage"`
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+	Note      string    `json:"note"` // 任意
+}
+
+func (f ReadingSessionCreateForm) ValidateReadingSessionCreateForm() error {
+	v := &ValidationError{}
+	if f.StartPage < 1 {
+		v.add("startPage", "startPage must be 1 or greater")
+	}
+	if f.EndPage < f.StartPage {
+		v.add("endPage", "endPage must be startPage or greater")
+	}
+	if f.StartedAt.IsZero() {
+		v.add("startedAt", "startedAt is required")
+	}
+	switch {
+	case f.EndedAt.IsZero():
+		v.add("endedAt", "endedAt is required")
+	case f.EndedAt.Before(f.StartedAt):
+		v.add("endedAt", "endedAt must not be before startedAt")
+	}
+	return v.err()
+}
@@ -0,0 +1,103 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const maxReviewTextLength = 10000
+
+type ReviewGet struct {
+	BookID int
+}
+
+func NewReviewGet(req *http.Request) (*ReviewGet, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewGet{BookID: id}, nil
+}
+
+// ReviewPut はレビューの作成・書き換え。?force=true なら completed でない本にも書ける。
+type ReviewPut struct {
+	BookID int
+	Force  bool
+	ReviewPutForm
+}
+
+func NewReviewPut(req *http.Request) (*ReviewPut, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReviewPut{BookID: id}
+	if s := req.URL.Query().Get("force"); s != "" {
+		force, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, InvalidField("force", "force must be true or false")
+		}
+		r.Force = force
+	}
+	if err := json.NewDecoder(req.Body).Decode(&r.ReviewPutForm); err != nil {
+		return nil, err
+	}
+	if err := r.ValidateReviewPutForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ReviewDelete struct {
+	BookID int
+}
+
+func NewReviewDelete(req *http.Request) (*ReviewDelete, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewDelete{BookID: id}, nil
+}
+
+// ---
+
+// ReviewPutForm の rating は 0.5 刻みの 0.5〜5、text は Markdown。
+// finishedOn は YYYY-MM-DD で、省略すると最後に completed になった日にする。
+type ReviewPutForm struct {
+	Rating      float64 `json:"rating"`
+	Text        string  `json:"text"`
+	Spoiler     bool    `json:"spoiler"`
+	FinishedOn  string  `json:"finishedOn"`
+	WouldReread bool    `json:"wouldReread"`
+}
+
+func (f ReviewPutForm) ValidateReviewPutForm() error {
+	v := &ValidationError{}
+	if f.Rating < 0.5 || f.Rating > 5 || f.Rating*2 != math.Trunc(f.Rating*2) {
+		v.add("rating", "rating must be between 0.5 and 5 in steps of 0.5")
+	}
+	if utf8.RuneCountInString(f.Text) > maxReviewTextLength {
+		v.add("text", fmt.Sprintf("text must be at most %d characters", maxReviewTextLength))
+	}
+	if f.FinishedOn != "" {
+		if _, err := time.Parse("2006-01-02", f.FinishedOn); err != nil {
+			v.add("finishedOn", "finishedOn must be YYYY-MM-DD")
+		}
+	}
+	return v.err()
+}
+
+// FinishedOnDate は finishedOn をその日の 00:00:00Z にしたもの。省略されていればゼロ値。
+func (f ReviewPutForm) FinishedOnDate() time.Time {
+	t, err := time.Parse("2006-01-02", f.FinishedOn)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
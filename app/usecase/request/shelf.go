@@ -0,0 +1,174 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxLabelNameLength      = 50  // 棚・タグの名前の最大文字数
+	maxLabelsPerBook        = 20  // 1冊に付けられる棚・タグの数
+	maxShelfDescriptionSize = 500 // 棚の説明の最大文字数
+)
+
+type ShelfGet struct{}
+
+func NewShelfGet(req *http.Request) (*ShelfGet, error) {
+	return &ShelfGet{}, nil
+}
+
+type ShelfGetByID struct {
+	ShelfID int
+}
+
+func NewShelfGetByID(req *http.Request) (*ShelfGetByID, error) {
+	id, err := idParam(req, "shelf")
+	if err != nil {
+		return nil, err
+	}
+	return &ShelfGetByID{ShelfID: id}, nil
+}
+
+type ShelfCreate struct {
+	ShelfCreateForm
+}
+
+func NewShelfCreate(req *http.Request) (*ShelfCreate, error) {
+	r := &ShelfCreate{}
+	if err := json.NewDecoder(req.Body).Decode(&r.ShelfCreateForm); err != nil {
+		return nil, err
+	}
+	r.Name = strings.TrimSpace(r.Name)
+	if err := r.ValidateShelfCreateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ShelfUpdate struct {
+	ShelfID int
+	ShelfUpdateForm
+}
+
+func NewShelfUpdate(req *http.Request) (*ShelfUpdate, error) {
+	id, err := idParam(req, "shelf")
+	if err != nil {
+		return nil, err
+	}
+	r := &ShelfUpdate{ShelfID: id}
+	if err := json.NewDecoder(req.Body).Decode(&r.ShelfUpdateForm); err != nil {
+		return nil, err
+	}
+	if r.Name != nil {
+		*r.Name = strings.TrimSpace(*r.Name)
+	}
+	if err := r.ValidateShelfUpdateForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ShelfDelete struct {
+	ShelfID int
+}
+
+func NewShelfDelete(req *http.Request) (*ShelfDelete, error) {
+	id, err := idParam(req, "shelf")
+	if err != nil {
+		return nil, err
+	}
+	return &ShelfDelete{ShelfID: id}, nil
+}
+
+// idParam は URL の {id} を what（shelf / tag など）の ID として読む。
+func idParam(req *http.Request, what string) (int, error) {
+	idStr := chi.URLParam(req, "id")
+	if idStr == "" {
+		return 0, InvalidField("id", what+" id is required")
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, InvalidField("id", "invalid "+what+" id")
+	}
+	return id, nil
+}
+
+// ---
+
+type ShelfCreateForm struct {
+	Name        string `json:"name"`
+	Description string `json:"description"` // 任意
+}
+
+func (f ShelfCreateForm) ValidateShelfCreateForm() error {
+	v := &ValidationError{}
+	validateLabelName(v, "name", f.Name)
+	if utf8.RuneCountInString(f.Description) > maxShelfDescriptionSize {
+		v.add("description", fmt.Sprintf("description must be at most %d characters", maxShelfDescriptionSize))
+	}
+	return v.err()
+}
+
+// ShelfUpdateForm は送った項目だけ更新する。名前を変えると、入っている本の shelves も新しい名前になる。
+type ShelfUpdateForm struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+func (f ShelfUpdateForm) ValidateShelfUpdateForm() error {
+	v := &ValidationError{}
+	if f.Name != nil {
+		validateLabelName(v, "name", *f.Name)
+	}
+	if f.Description != nil && utf8.RuneCountInString(*f.Description) > maxShelfDescriptionSize {
+		v.add("description", fmt.Sprintf("description must be at most %d characters", maxShelfDescriptionSize))
+	}
+	return v.err()
+}
+
+func validateLabelName(v *ValidationError, field, name string) {
+	switch {
+	case name == "":
+		v.add(field, field+" is required")
+	case utf8.RuneCountInString(name) > maxLabelNameLength:
+		v.add(field, fmt.Sprintf("%s must be at most %d characters", field, maxLabelNameLength))
+	}
+}
+
+// normalizeLabels は本に付ける棚・タグの名前の前後の空白を取り、空のものと重複を除く。
+func normalizeLabels(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// validateLabels は本に付ける棚・タグ（normalizeLabels 済み）の数と名前の長さを確かめる。
+func validateLabels(v *ValidationError, field string, names []string) {
+	if len(names) > maxLabelsPerBook {
+		v.add(field, fmt.Sprintf("%s must have at most %d items", field, maxLabelsPerBook))
+		return
+	}
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > maxLabelNameLength {
+			v.add(field, fmt.Sprintf("each of %s must be at most %d characters", field, maxLabelNameLength))
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package request
+
+import (
+	"net/http"
+	"time"
+)
+
+// StatsGet の from / to は YYYY-MM-DD（どちらも含む）。to を省略すると tz で見た今日、from を省略すると to までの1年間。
+type StatsGet struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+func NewStatsGet(req *http.Request) (*StatsGet, error) {
+	loc, err := locationParam(req)
+	if err != nil {
+		return nil, err
+	}
+	r := &StatsGet{Location: loc}
+	v := &ValidationError{}
+	q := req.URL.Query()
+	if s := q.Get("from"); s != "" {
+		if r.From, err = time.Parse("2006-01-02", s); err != nil {
+			v.add("from", "from must be YYYY-MM-DD")
+		}
+	}
+	if s := q.Get("to"); s != "" {
+		if r.To, err = time.Parse("2006-01-02", s); err != nil {
+			v.add("to", "to must be YYYY-MM-DD")
+		}
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		v.add("to", "to must not be before from")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
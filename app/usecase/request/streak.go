@@ -0,0 +1,22 @@
+package request
+
+import (
+	"net/http"
+	"time"
+)
+
+// StreakGet の tz を省略すると、ユーザーの設定（timeZone）のタイムゾーンで日付を分ける。
+type StreakGet struct {
+	Location *time.Location // tz を省略したときは nil
+}
+
+func NewStreakGet(req *http.Request) (*StreakGet, error) {
+	if req.URL.Query().Get("tz") == "" {
+		return &StreakGet{}, nil
+	}
+	loc, err := locationParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &StreakGet{Location: loc}, nil
+}
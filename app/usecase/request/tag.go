@@ -0,0 +1,87 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type TagGet struct{}
+
+func NewTagGet(req *http.Request) (*TagGet, error) {
+	return &TagGet{}, nil
+}
+
+type TagGetByID struct {
+	TagID int
+}
+
+func NewTagGetByID(req *http.Request) (*TagGetByID, error) {
+	id, err := idParam(req, "tag")
+	if err != nil {
+		return nil, err
+	}
+	return &TagGetByID{TagID: id}, nil
+}
+
+type TagCreate struct {
+	TagForm
+}
+
+func NewTagCreate(req *http.Request) (*TagCreate, error) {
+	r := &TagCreate{}
+	if err := json.NewDecoder(req.Body).Decode(&r.TagForm); err != nil {
+		return nil, err
+	}
+	r.Name = strings.TrimSpace(r.Name)
+	if err := r.ValidateTagForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TagUpdate は名前の変更。付いている本の tags も新しい名前になる。
+type TagUpdate struct {
+	TagID int
+	TagForm
+}
+
+func NewTagUpdate(req *http.Request) (*TagUpdate, error) {
+	id, err := idParam(req, "tag")
+	if err != nil {
+		return nil, err
+	}
+	r := &TagUpdate{TagID: id}
+	if err := json.NewDecoder(req.Body).Decode(&r.TagForm); err != nil {
+		return nil, err
+	}
+	r.Name = strings.TrimSpace(r.Name)
+	if err := r.ValidateTagForm(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type TagDelete struct {
+	TagID int
+}
+
+func NewTagDelete(req *http.Request) (*TagDelete, error) {
+	id, err := idParam(req, "tag")
+	if err != nil {
+		return nil, err
+	}
+	return &TagDelete{TagID: id}, nil
+}
+
+// ---
+
+type TagForm struct {
+	Name string `json:"name"`
+}
+
+func (f TagForm) ValidateTagForm() error {
+	v := &ValidationError{}
+	validateLabelName(v, "name", f.Name)
+	return v.err()
+}
@@ -0,0 +1,35 @@
+package request
+
+import "net/http"
+
+type TrashGet struct{}
+
+func NewTrashGet(req *http.Request) (*TrashGet, error) {
+	return &TrashGet{}, nil
+}
+
+// TrashRestore はゴミ箱の本を戻す。
+type TrashRestore struct {
+	BookID int
+}
+
+func NewTrashRestore(req *http.Request) (*TrashRestore, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &TrashRestore{BookID: id}, nil
+}
+
+// TrashDelete はゴミ箱の本を完全に消す。
+type TrashDelete struct {
+	BookID int
+}
+
+func NewTrashDelete(req *http.Request) (*TrashDelete, error) {
+	id, err := bookIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	return &TrashDelete{BookID: id}, nil
+}
@@ -0,0 +1,40 @@
+package request
+
+import "strings"
+
+// FieldError は入力項目ごとのエラー。Field は JSON の項目名（クエリ・パスパラメータはその名前）。
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError は入力のエラーをまとめたもの。最初の1件で止めずに、通らなかった項目をすべて持つ。
+// controller で 400（validation_failed）に変換し、項目ごとのエラーを返す。
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// err はエラーがなければ nil を返す（*ValidationError の nil を error にしないため）。
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// InvalidField は1項目だけのエラー。
+func InvalidField(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
@@ -0,0 +1,43 @@
+package response
+
+import (
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// AuthLogin は発行したセッショントークンを返す。以降は Authorization: Bearer <token> で送る。
+type AuthLogin struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	User      *entity.User `json:"user"`
+}
+
+func NewAuthLogin(token string, authToken *entity.AuthToken, user *entity.User) *AuthLogin {
+	return &AuthLogin{Token: token, ExpiresAt: authToken.ExpiresAt, User: user}
+}
+
+// AuthSignUp は登録と同時にログインした状態のトークンを返す。
+type AuthSignUp struct {
+	AuthLogin
+}
+
+func NewAuthSignUp(token string, authToken *entity.AuthToken, user *entity.User) *AuthSignUp {
+	return &AuthSignUp{*NewAuthLogin(token, authToken, user)}
+}
+
+type AuthMe struct {
+	*entity.User
+}
+
+func NewAuthMe(user *entity.User) *AuthMe {
+	return &AuthMe{user}
+}
+
+type AuthMeUpdate struct {
+	*entity.User
+}
+
+func NewAuthMeUpdate(user *entity.User) *AuthMeUpdate {
+	return &AuthMeUpdate{user}
+}
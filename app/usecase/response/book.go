@@ -1,27 +1,52 @@
 package response
 
 import (
+	"strconv"
+
 	"github.com/sora-00/booktracker-api/app/domain/entity"
 )
 
+// BookETag は本の version の強い ETag。If-Match にはこの値をそのまま送る。
+func BookETag(book *entity.Book) string {
+	return strconv.Quote(strconv.Itoa(book.Version))
+}
+
 type BookGet struct {
-	Books []*entity.Book `json:"books"`
+	Books         []*entity.Book `json:"books"`
+	NextPageToken string         `json:"nextPageToken,omitempty"` // 続きがないときは省略
 }
 
-func NewBookGet(books []entity.Book) *BookGet {
+func NewBookGet(books []entity.Book, nextPageToken string) *BookGet {
 	bs := make([]*entity.Book, 0, len(books))
 	for i := range books {
 		bs = append(bs, &books[i])
 	}
-	return &BookGet{Books: bs}
+	return &BookGet{Books: bs, NextPageToken: nextPageToken}
+}
+
+type BookSearch struct {
+	Results []BookSearchHit `json:"results"`
+}
+
+// BookSearchHit は検索結果の1件。highlights は当たった項目だけを、当たった箇所を <mark> で囲んだ HTML で返す。
+type BookSearchHit struct {
+	Book       *entity.Book      `json:"book"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
 }
 
+func NewBookSearch(results []BookSearchHit) *BookSearch {
+	return &BookSearch{Results: results}
+}
+
+// BookGetByID は本と、その本の評価・ユーザーのレビュー全体の集計。
 type BookGetByID struct {
 	*entity.Book
+	RatingStats *RatingStats `json:"ratingStats"`
 }
 
-func NewBookGetByID(book *entity.Book) *BookGetByID {
-	return &BookGetByID{book}
+func NewBookGetByID(book *entity.Book, stats *RatingStats) *BookGetByID {
+	return &BookGetByID{Book: book, RatingStats: stats}
 }
 
 type BookCreate struct {
@@ -40,6 +65,14 @@ func NewBookUpdate(book *entity.Book) *BookUpdate {
 	return &BookUpdate{book}
 }
 
+type BookPatch struct {
+	*entity.Book
+}
+
+func NewBookPatch(book *entity.Book) *BookPatch {
+	return &BookPatch{book}
+}
+
 type BookDelete struct {
 	BookID int `json:"bookId"`
 }
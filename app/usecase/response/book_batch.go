@@ -0,0 +1,45 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+// BookBatch は一括操作の結果。results はリクエストの books と同じ順。
+type BookBatch struct {
+	Results   []BookBatchResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// BookBatchResult は1冊分の結果。status は1冊ずつの API で返るはずの HTTP ステータスで、失敗したときは error に problem を入れる。
+// id は登録ではできた本の ID、更新・削除では指定した本の ID。
+type BookBatchResult struct {
+	Index  int          `json:"index"`
+	ID     int          `json:"id,omitempty"`
+	Status int          `json:"status"`
+	Book   *entity.Book `json:"book,omitempty"`
+	Error  *Problem     `json:"error,omitempty"`
+	Err    error        `json:"-"` // controller で status と error にする
+}
+
+// NewBookBatch の ids は指定した本の ID（登録では nil）。
+func NewBookBatch(results []service.BookBatchResult, ids []int) *BookBatch {
+	res := &BookBatch{Results: make([]BookBatchResult, 0, len(results))}
+	for i, r := range results {
+		item := BookBatchResult{Index: i, Book: r.Book, Err: r.Err}
+		switch {
+		case ids != nil:
+			item.ID = ids[i]
+		case r.Book != nil:
+			item.ID = r.Book.ID
+		}
+		if r.Err != nil {
+			res.Failed++
+		} else {
+			res.Succeeded++
+		}
+		res.Results = append(res.Results, item)
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package response
+
+import (
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+// 見込みの日付は暦日なので YYYY-MM-DD で返す
+const dateLayout = "2006-01-02"
+
+type BookForecast struct {
+	BookID                       int                `json:"bookId"`
+	Status                       string             `json:"status"` // completed / on_track / behind / overdue / not_started
+	Today                        string             `json:"today"`
+	TargetCompleteDate           string             `json:"targetCompleteDate"`
+	RemainingPages               int                `json:"remainingPages"`
+	DaysLeft                     int                `json:"daysLeft"`
+	RequiredPagesPerDay          int                `json:"requiredPagesPerDay"`
+	CurrentPagesPerDay           float64            `json:"currentPagesPerDay"`
+	TargetPagesPerDay            int                `json:"targetPagesPerDay"`
+	ProjectedFinishAtCurrentPace *string            `json:"projectedFinishAtCurrentPace"`
+	ProjectedFinishAtTargetPace  *string            `json:"projectedFinishAtTargetPace"`
+	Plan                         []BookForecastPlan `json:"plan"`
+}
+
+type BookForecastPlan struct {
+	Date    string `json:"date"`
+	Pages   int    `json:"pages"`
+	EndPage int    `json:"endPage"`
+}
+
+func NewBookForecast(bookID int, f *service.Forecast) *BookForecast {
+	plan := make([]BookForecastPlan, 0, len(f.Plan))
+	for _, d := range f.Plan {
+		plan = append(plan, BookForecastPlan{Date: d.Date.Format(dateLayout), Pages: d.Pages, EndPage: d.EndPage})
+	}
+	return &BookForecast{
+		BookID:                       bookID,
+		Status:                       string(f.Status),
+		Today:                        f.Today.Format(dateLayout),
+		TargetCompleteDate:           f.TargetCompleteDate.Format(dateLayout),
+		RemainingPages:               f.RemainingPages,
+		DaysLeft:                     f.DaysLeft,
+		RequiredPagesPerDay:          f.RequiredPagesPerDay,
+		CurrentPagesPerDay:           f.CurrentPagesPerDay,
+		TargetPagesPerDay:            f.TargetPagesPerDay,
+		ProjectedFinishAtCurrentPace: formatDate(f.ProjectedFinishAtCurrentPace),
+		ProjectedFinishAtTargetPace:  formatDate(f.ProjectedFinishAtTargetPace),
+		Plan:                         plan,
+	}
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(dateLayout)
+	return &s
+}
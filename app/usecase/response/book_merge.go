@@ -0,0 +1,35 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+// BookDuplicate は登録しようとした本と同じらしい本。reason は isbn / title_author、score は 0〜1。
+type BookDuplicate struct {
+	Book   *entity.Book `json:"book"`
+	Reason string       `json:"reason"`
+	Score  float64      `json:"score"`
+}
+
+func NewBookDuplicates(candidates []service.Duplicate) []BookDuplicate {
+	out := make([]BookDuplicate, 0, len(candidates))
+	for i := range candidates {
+		out = append(out, BookDuplicate{
+			Book:   &candidates[i].Book,
+			Reason: string(candidates[i].Reason),
+			Score:  candidates[i].Score,
+		})
+	}
+	return out
+}
+
+// BookMerge はまとめた後の本。
+type BookMerge struct {
+	*entity.Book
+	MergedBookID int `json:"mergedBookId"` // target にまとめて消した本の ID
+}
+
+func NewBookMerge(book *entity.Book, mergedBookID int) *BookMerge {
+	return &BookMerge{Book: book, MergedBookID: mergedBookID}
+}
@@ -0,0 +1,26 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// BookStatusChange は遷移後の本と、追加した遷移履歴を返す。
+type BookStatusChange struct {
+	Book   *entity.Book         `json:"book"`
+	Change *entity.StatusChange `json:"change"`
+}
+
+func NewBookStatusChange(book *entity.Book, change *entity.StatusChange) *BookStatusChange {
+	return &BookStatusChange{Book: book, Change: change}
+}
+
+type BookStatusHistory struct {
+	History []entity.StatusChange `json:"history"`
+}
+
+func NewBookStatusHistory(history []entity.StatusChange) *BookStatusHistory {
+	if history == nil {
+		history = []entity.StatusChange{}
+	}
+	return &BookStatusHistory{History: history}
+}
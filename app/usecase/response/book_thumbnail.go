@@ -0,0 +1,12 @@
+package response
+
+// BookThumbnailUpload はアップロードした表紙画像。id を本の thumbnailId に指定する。
+type BookThumbnailUpload struct {
+	ID       string            `json:"id"`
+	URL      string            `json:"url"`      // original の URL
+	Variants map[string]string `json:"variants"` // list / detail / original → URL
+}
+
+func NewBookThumbnailUpload(id, url string, variants map[string]string) *BookThumbnailUpload {
+	return &BookThumbnailUpload{ID: id, URL: url, Variants: variants}
+}
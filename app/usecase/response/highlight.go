@@ -0,0 +1,87 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+type HighlightGet struct {
+	Highlights []entity.Highlight `json:"highlights"`
+}
+
+func NewHighlightGet(highlights []entity.Highlight) *HighlightGet {
+	if highlights == nil {
+		highlights = []entity.Highlight{}
+	}
+	return &HighlightGet{Highlights: highlights}
+}
+
+type HighlightGetByID struct {
+	*entity.Highlight
+}
+
+func NewHighlightGetByID(highlight *entity.Highlight) *HighlightGetByID {
+	return &HighlightGetByID{highlight}
+}
+
+type HighlightCreate struct {
+	*entity.Highlight
+}
+
+func NewHighlightCreate(highlight *entity.Highlight) *HighlightCreate {
+	return &HighlightCreate{highlight}
+}
+
+type HighlightUpdate struct {
+	*entity.Highlight
+}
+
+func NewHighlightUpdate(highlight *entity.Highlight) *HighlightUpdate {
+	return &HighlightUpdate{highlight}
+}
+
+type HighlightDelete struct {
+	BookID      int `json:"bookId"`
+	HighlightID int `json:"highlightId"`
+}
+
+func NewHighlightDelete(bookID, highlightID int) *HighlightDelete {
+	return &HighlightDelete{BookID: bookID, HighlightID: highlightID}
+}
+
+type HighlightFeed struct {
+	Highlights    []HighlightWithBook `json:"highlights"`
+	NextPageToken string              `json:"nextPageToken,omitempty"` // 続きがないときは省略
+}
+
+func NewHighlightFeed(highlights []HighlightWithBook, nextPageToken string) *HighlightFeed {
+	return &HighlightFeed{Highlights: highlights, NextPageToken: nextPageToken}
+}
+
+// HighlightWithBook は本をまたいだ一覧用に、どの本のハイライトかを付けたもの。
+type HighlightWithBook struct {
+	*entity.Highlight
+	Book HighlightBook `json:"book"`
+}
+
+type HighlightBook struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+func NewHighlightWithBook(highlight *entity.Highlight, book *entity.Book) HighlightWithBook {
+	return HighlightWithBook{
+		Highlight: highlight,
+		Book:      HighlightBook{ID: book.ID, Title: book.Title, Author: book.Author},
+	}
+}
+
+// HighlightDaily は今日見返すハイライト。ハイライトが1件もなければ highlight は null。
+type HighlightDaily struct {
+	Date      string             `json:"date"` // YYYY-MM-DD（tz での今日）
+	Highlight *HighlightWithBook `json:"highlight"`
+}
+
+func NewHighlightDaily(date string, highlight *HighlightWithBook) *HighlightDaily {
+	return &HighlightDaily{Date: date, Highlight: highlight}
+}
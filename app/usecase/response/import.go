@@ -0,0 +1,64 @@
+package response
+
+import (
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// ImportJob は CSV の取り込みの進み具合。rows は処理した行の結果で、CSV と同じ順。
+// succeeded は登録した（dryRun なら登録できる）行、duplicates は同じらしい本があって登録しなかった行、failed はそれ以外で登録しなかった行の数。
+type ImportJob struct {
+	ID         string      `json:"id"`
+	Format     string      `json:"format"`
+	DryRun     bool        `json:"dryRun"`
+	State      string      `json:"state"` // running / succeeded（失敗した行があっても）/ failed（途中で止まった）
+	Total      int         `json:"total"`
+	Processed  int         `json:"processed"`
+	Succeeded  int         `json:"succeeded"`
+	Duplicates int         `json:"duplicates"`
+	Failed     int         `json:"failed"`
+	Rows       []ImportRow `json:"rows"`
+	Error      *Problem    `json:"error,omitempty"` // state が failed のときの原因
+	Err        error       `json:"-"`               // controller で error にする。usecase で State が failed のときに入れる
+	CreatedAt  time.Time   `json:"createdAt"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+}
+
+// ImportRow は1行分の結果。status は1冊ずつ POST /api/books したときの HTTP ステータスで（dryRun で登録できる行は 200）、
+// 失敗した行は error に problem を入れる。
+type ImportRow struct {
+	Row    int          `json:"row"` // CSV の行番号（ヘッダーが1行目）
+	Status int          `json:"status"`
+	Book   *entity.Book `json:"book,omitempty"`
+	Error  *Problem     `json:"error,omitempty"`
+	Err    error        `json:"-"` // controller で status と error にする。usecase で保存した行のエラーから戻して入れる
+}
+
+func NewImportJob(job *entity.ImportJob) *ImportJob {
+	res := &ImportJob{
+		ID:        job.ID,
+		Format:    job.Format,
+		DryRun:    job.DryRun,
+		State:     string(job.State),
+		Total:     job.Total,
+		Processed: job.Processed,
+		Rows:      make([]ImportRow, 0, len(job.Rows)),
+		CreatedAt: job.CreatedAt,
+	}
+	if !job.FinishedAt.IsZero() {
+		res.FinishedAt = &job.FinishedAt
+	}
+	for _, r := range job.Rows {
+		switch {
+		case r.Error == nil:
+			res.Succeeded++
+		case len(r.Error.Candidates) > 0:
+			res.Duplicates++
+		default:
+			res.Failed++
+		}
+		res.Rows = append(res.Rows, ImportRow{Row: r.Row, Book: r.Book})
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package response
+
+import (
+	"strings"
+
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+// ISBNLookup は引いた書誌情報と、そのまま POST /api/books に送れる形に埋めた form。
+// form の targetCompleteDate など書誌情報にない項目はクライアントで足す。
+type ISBNLookup struct {
+	ISBN          string         `json:"isbn"`
+	Source        string         `json:"source"` // 書誌情報を引いたサービス（openlibrary / googlebooks）
+	Authors       []string       `json:"authors"`
+	PublishedDate string         `json:"publishedDate"`
+	CoverURL      string         `json:"coverUrl"`      // サービスの表紙画像の URL。なければ空
+	CoverImported bool           `json:"coverImported"` // ?cover=true で表紙を取り込めたか
+	CoverError    string         `json:"coverError,omitempty"`
+	Form          ISBNLookupForm `json:"form"`
+}
+
+// ISBNLookupForm は BookCreateForm のうち書誌情報から埋められる項目。
+type ISBNLookupForm struct {
+	Title        string `json:"title"`
+	Author       string `json:"author"` // 複数の著者は ", " でつなぐ
+	TotalPages   int    `json:"totalPages"`
+	Publisher    string `json:"publisher"`
+	ISBN         string `json:"isbn"`
+	ThumbnailUrl string `json:"thumbnailUrl"`
+	ThumbnailID  string `json:"thumbnailId"` // 表紙を取り込んだときだけ
+	Status       string `json:"status"`
+}
+
+func NewISBNLookup(l *service.ISBNLookup) *ISBNLookup {
+	m := l.Metadata
+	authors := m.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	res := &ISBNLookup{
+		ISBN:          m.ISBN,
+		Source:        m.Source,
+		Authors:       authors,
+		PublishedDate: m.PublishedDate,
+		CoverURL:      m.CoverURL,
+		Form: ISBNLookupForm{
+			Title:        m.Title,
+			Author:       strings.Join(m.Authors, ", "),
+			TotalPages:   m.PageCount,
+			Publisher:    m.Publisher,
+			ISBN:         m.ISBN,
+			ThumbnailUrl: m.CoverURL,
+			Status:       "unread",
+		},
+	}
+	if l.Thumbnail != nil {
+		res.CoverImported = true
+		res.Form.ThumbnailID = l.Thumbnail.ID
+		res.Form.ThumbnailUrl = l.Thumbnail.URL
+	}
+	if l.CoverError != nil {
+		res.CoverError = l.CoverError.Error()
+	}
+	return res
+}
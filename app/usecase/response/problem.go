@@ -0,0 +1,20 @@
+package response
+
+// Problem は RFC 7807 の application/problem+json のエラー。
+// type は about:blank（title は HTTP のステータスの文言）で、エラーの種類は code で見分ける。
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Code     string         `json:"code"` // validation_failed / book_not_found / thumbnail_too_large など
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"` // リクエストのパス
+	Errors   []ProblemField `json:"errors,omitempty"`   // validation_failed のときの項目ごとのエラー
+	// duplicate_book のときの同じらしい本（似ている順）
+	Candidates []BookDuplicate `json:"candidates,omitempty"`
+}
+
+type ProblemField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
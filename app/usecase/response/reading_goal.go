@@ -0,0 +1,95 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+type ReadingGoalGet struct {
+	Goals []entity.ReadingGoal `json:"goals"`
+}
+
+func NewReadingGoalGet(goals []entity.ReadingGoal) *ReadingGoalGet {
+	if goals == nil {
+		goals = []entity.ReadingGoal{}
+	}
+	return &ReadingGoalGet{Goals: goals}
+}
+
+type ReadingGoalGetByID struct {
+	*entity.ReadingGoal
+}
+
+func NewReadingGoalGetByID(goal *entity.ReadingGoal) *ReadingGoalGetByID {
+	return &ReadingGoalGetByID{goal}
+}
+
+type ReadingGoalCreate struct {
+	*entity.ReadingGoal
+}
+
+func NewReadingGoalCreate(goal *entity.ReadingGoal) *ReadingGoalCreate {
+	return &ReadingGoalCreate{goal}
+}
+
+type ReadingGoalUpdate struct {
+	*entity.ReadingGoal
+}
+
+func NewReadingGoalUpdate(goal *entity.ReadingGoal) *ReadingGoalUpdate {
+	return &ReadingGoalUpdate{goal}
+}
+
+type ReadingGoalDelete struct {
+	GoalID int `json:"goalId"`
+}
+
+func NewReadingGoalDelete(goalID int) *ReadingGoalDelete {
+	return &ReadingGoalDelete{GoalID: goalID}
+}
+
+type ReadingGoalProgress struct {
+	GoalID         int                        `json:"goalId"`
+	Metric         string                     `json:"metric"`
+	Status         string                     `json:"status"` // not_started / ahead / behind / achieved / missed
+	Today          string                     `json:"today"`
+	StartDate      string                     `json:"startDate"`
+	EndDate        string                     `json:"endDate"`
+	Target         int                        `json:"target"`
+	Actual         int                        `json:"actual"`
+	ExpectedToday  float64                    `json:"expectedToday"`
+	Difference     float64                    `json:"difference"` // actual - expectedToday。正なら予定より進んでいる
+	DaysTotal      int                        `json:"daysTotal"`
+	DaysLeft       int                        `json:"daysLeft"`
+	RequiredPerDay int                        `json:"requiredPerDay"`
+	Curve          []ReadingGoalProgressPoint `json:"curve"`
+}
+
+type ReadingGoalProgressPoint struct {
+	Date     string  `json:"date"`
+	Expected float64 `json:"expected"`
+	Actual   *int    `json:"actual"` // 今日より後の日は null
+}
+
+func NewReadingGoalProgress(goal *entity.ReadingGoal, p *service.GoalProgress) *ReadingGoalProgress {
+	curve := make([]ReadingGoalProgressPoint, 0, len(p.Curve))
+	for _, c := range p.Curve {
+		curve = append(curve, ReadingGoalProgressPoint{Date: c.Date.Format(dateLayout), Expected: c.Expected, Actual: c.Actual})
+	}
+	return &ReadingGoalProgress{
+		GoalID:         goal.ID,
+		Metric:         string(goal.Metric),
+		Status:         string(p.Status),
+		Today:          p.Today.Format(dateLayout),
+		StartDate:      goal.StartDate.Format(dateLayout),
+		EndDate:        goal.EndDate.Format(dateLayout),
+		Target:         p.Target,
+		Actual:         p.Actual,
+		ExpectedToday:  p.ExpectedToday,
+		Difference:     float64(p.Actual) - p.ExpectedToday,
+		DaysTotal:      p.DaysTotal,
+		DaysLeft:       p.DaysLeft,
+		RequiredPerDay: p.RequiredPerDay,
+		Curve:          curve,
+	}
+}
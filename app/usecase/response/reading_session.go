@@ -0,0 +1,34 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+type ReadingSessionGet struct {
+	Sessions []entity.ReadingSession `json:"sessions"`
+}
+
+func NewReadingSessionGet(sessions []entity.ReadingSession) *ReadingSessionGet {
+	if sessions == nil {
+		sessions = []entity.ReadingSession{}
+	}
+	return &ReadingSessionGet{Sessions: sessions}
+}
+
+// ReadingSessionCreate は追加した記録と、再計算後の本（readPages / status）を返す。
+type ReadingSessionCreate struct {
+	Session *entity.ReadingSession `json:"session"`
+	Book    *entity.Book           `json:"book"`
+}
+
+func NewReadingSessionCreate(session *entity.ReadingSession, book *entity.Book) *ReadingSessionCreate {
+	return &ReadingSessionCreate{Session: session, Book: book}
+}
+
+type ReadingSessionDelete struct {
+	Book *entity.Book `json:"book"`
+}
+
+func NewReadingSessionDelete(book *entity.Book) *ReadingSessionDelete {
+	return &ReadingSessionDelete{Book: book}
+}
@@ -0,0 +1,53 @@
+package response
+
+import (
+	"strconv"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+type ReviewGet struct {
+	*entity.Review
+}
+
+func NewReviewGet(review *entity.Review) *ReviewGet {
+	return &ReviewGet{review}
+}
+
+type ReviewPut struct {
+	*entity.Review
+}
+
+func NewReviewPut(review *entity.Review) *ReviewPut {
+	return &ReviewPut{review}
+}
+
+type ReviewDelete struct {
+	BookID int `json:"bookId"`
+}
+
+func NewReviewDelete(bookID int) *ReviewDelete {
+	return &ReviewDelete{BookID: bookID}
+}
+
+// RatingStats は本の評価と、ユーザーのレビュー全体の集計。distribution の key は "0.5"〜"5"。
+type RatingStats struct {
+	Rating        *float64       `json:"rating"` // この本の評価。レビューがなければ null
+	ReviewCount   int            `json:"reviewCount"`
+	AverageRating float64        `json:"averageRating"`
+	Distribution  map[string]int `json:"distribution"`
+}
+
+func NewRatingStats(stats *service.RatingStats) *RatingStats {
+	dist := make(map[string]int, len(stats.Distribution))
+	for rating, n := range stats.Distribution {
+		dist[strconv.FormatFloat(rating, 'f', -1, 64)] = n
+	}
+	return &RatingStats{
+		Rating:        stats.Rating,
+		ReviewCount:   stats.Count,
+		AverageRating: stats.Average,
+		Distribution:  dist,
+	}
+}
@@ -0,0 +1,48 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+type ShelfGet struct {
+	Shelves []entity.Shelf `json:"shelves"`
+}
+
+func NewShelfGet(shelves []entity.Shelf) *ShelfGet {
+	if shelves == nil {
+		shelves = []entity.Shelf{}
+	}
+	return &ShelfGet{Shelves: shelves}
+}
+
+type ShelfGetByID struct {
+	*entity.Shelf
+}
+
+func NewShelfGetByID(shelf *entity.Shelf) *ShelfGetByID {
+	return &ShelfGetByID{shelf}
+}
+
+type ShelfCreate struct {
+	*entity.Shelf
+}
+
+func NewShelfCreate(shelf *entity.Shelf) *ShelfCreate {
+	return &ShelfCreate{shelf}
+}
+
+type ShelfUpdate struct {
+	*entity.Shelf
+}
+
+func NewShelfUpdate(shelf *entity.Shelf) *ShelfUpdate {
+	return &ShelfUpdate{shelf}
+}
+
+type ShelfDelete struct {
+	ShelfID int `json:"shelfId"`
+}
+
+func NewShelfDelete(shelfID int) *ShelfDelete {
+	return &ShelfDelete{ShelfID: shelfID}
+}
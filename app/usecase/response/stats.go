@@ -0,0 +1,93 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+type StatsGet struct {
+	From                string            `json:"from"`
+	To                  string            `json:"to"`
+	TotalBooks          int               `json:"totalBooks"`
+	ByStatus            map[string]int    `json:"byStatus"` // 期間に関係なく今の status ごとの冊数
+	PagesRead           int               `json:"pagesRead"`
+	PagesPerDay         []StatsPages      `json:"pagesPerDay"`
+	PagesPerWeek        []StatsPages      `json:"pagesPerWeek"` // 月曜始まり。date は週の月曜
+	PagesPerMonth       []StatsPages      `json:"pagesPerMonth"`
+	BooksFinished       int               `json:"booksFinished"`
+	BooksAbandoned      int               `json:"booksAbandoned"`
+	CompletionRate      *float64          `json:"completionRate"`
+	AverageDaysToFinish *float64          `json:"averageDaysToFinish"`
+	AverageBookLength   *float64          `json:"averageBookLength"`
+	TopAuthors          []StatsNameCount  `json:"topAuthors"`
+	TopPublishers       []StatsNameCount  `json:"topPublishers"`
+	Heatmap             []StatsHeatmapDay `json:"heatmap"`
+}
+
+type StatsPages struct {
+	Date  string `json:"date"`
+	Pages int    `json:"pages"`
+}
+
+type StatsNameCount struct {
+	Name  string `json:"name"`
+	Books int    `json:"books"`
+}
+
+type StatsHeatmapDay struct {
+	Date     string `json:"date"`
+	Weekday  int    `json:"weekday"` // 0 = 日曜
+	Pages    int    `json:"pages"`
+	Sessions int    `json:"sessions"`
+	Level    int    `json:"level"` // 0〜4
+}
+
+func NewStatsGet(st *service.Stats) *StatsGet {
+	byStatus := make(map[string]int, len(st.ByStatus))
+	for status, n := range st.ByStatus {
+		byStatus[string(status)] = n
+	}
+	heatmap := make([]StatsHeatmapDay, 0, len(st.Heatmap))
+	for _, d := range st.Heatmap {
+		heatmap = append(heatmap, StatsHeatmapDay{
+			Date:     d.Date.Format(dateLayout),
+			Weekday:  int(d.Date.Weekday()),
+			Pages:    d.Pages,
+			Sessions: d.Sessions,
+			Level:    d.Level,
+		})
+	}
+	return &StatsGet{
+		From:                st.From.Format(dateLayout),
+		To:                  st.To.Format(dateLayout),
+		TotalBooks:          st.TotalBooks,
+		ByStatus:            byStatus,
+		PagesRead:           st.PagesRead,
+		PagesPerDay:         newStatsPages(st.PagesPerDay),
+		PagesPerWeek:        newStatsPages(st.PagesPerWeek),
+		PagesPerMonth:       newStatsPages(st.PagesPerMonth),
+		BooksFinished:       st.BooksFinished,
+		BooksAbandoned:      st.BooksAbandoned,
+		CompletionRate:      st.CompletionRate,
+		AverageDaysToFinish: st.AverageDaysToFinish,
+		AverageBookLength:   st.AverageBookLength,
+		TopAuthors:          newStatsNameCounts(st.TopAuthors),
+		TopPublishers:       newStatsNameCounts(st.TopPublishers),
+		Heatmap:             heatmap,
+	}
+}
+
+func newStatsPages(buckets []service.PagesBucket) []StatsPages {
+	out := make([]StatsPages, 0, len(buckets))
+	for _, b := range buckets {
+		out = append(out, StatsPages{Date: b.Start.Format(dateLayout), Pages: b.Pages})
+	}
+	return out
+}
+
+func newStatsNameCounts(names []service.NameCount) []StatsNameCount {
+	out := make([]StatsNameCount, 0, len(names))
+	for _, n := range names {
+		out = append(out, StatsNameCount{Name: n.Name, Books: n.Books})
+	}
+	return out
+}
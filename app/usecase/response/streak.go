@@ -0,0 +1,33 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/service"
+)
+
+type StreakGet struct {
+	TimeZone     string  `json:"timeZone"`
+	Today        string  `json:"today"`
+	Current      int     `json:"current"`
+	CurrentStart *string `json:"currentStart"`
+	ReadToday    bool    `json:"readToday"`
+	Longest      int     `json:"longest"`
+	LongestStart *string `json:"longestStart"`
+	LongestEnd   *string `json:"longestEnd"`
+	LastReadOn   *string `json:"lastReadOn"`
+	DaysRead     int     `json:"daysRead"`
+}
+
+func NewStreakGet(timeZone string, st *service.Streak) *StreakGet {
+	return &StreakGet{
+		TimeZone:     timeZone,
+		Today:        st.Today.Format(dateLayout),
+		Current:      st.Current,
+		CurrentStart: formatDate(st.CurrentStart),
+		ReadToday:    st.ReadToday,
+		Longest:      st.Longest,
+		LongestStart: formatDate(st.LongestStart),
+		LongestEnd:   formatDate(st.LongestEnd),
+		LastReadOn:   formatDate(st.LastReadOn),
+		DaysRead:     st.DaysRead,
+	}
+}
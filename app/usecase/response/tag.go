@@ -0,0 +1,48 @@
+package response
+
+import (
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+type TagGet struct {
+	Tags []entity.Tag `json:"tags"`
+}
+
+func NewTagGet(tags []entity.Tag) *TagGet {
+	if tags == nil {
+		tags = []entity.Tag{}
+	}
+	return &TagGet{Tags: tags}
+}
+
+type TagGetByID struct {
+	*entity.Tag
+}
+
+func NewTagGetByID(tag *entity.Tag) *TagGetByID {
+	return &TagGetByID{tag}
+}
+
+type TagCreate struct {
+	*entity.Tag
+}
+
+func NewTagCreate(tag *entity.Tag) *TagCreate {
+	return &TagCreate{tag}
+}
+
+type TagUpdate struct {
+	*entity.Tag
+}
+
+func NewTagUpdate(tag *entity.Tag) *TagUpdate {
+	return &TagUpdate{tag}
+}
+
+type TagDelete struct {
+	TagID int `json:"tagId"`
+}
+
+func NewTagDelete(tagID int) *TagDelete {
+	return &TagDelete{TagID: tagID}
+}
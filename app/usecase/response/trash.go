@@ -0,0 +1,43 @@
+package response
+
+import (
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+)
+
+// TrashGet はゴミ箱の本の一覧（ゴミ箱に入れた新しい順）。
+type TrashGet struct {
+	Books []TrashedBook `json:"books"`
+}
+
+// TrashedBook はゴミ箱の本。ゴミ箱に入っていない本の JSON には deletedAt を出さないので、ここで付ける。
+type TrashedBook struct {
+	*entity.Book
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+func NewTrashGet(books []entity.Book) *TrashGet {
+	bs := make([]TrashedBook, 0, len(books))
+	for i := range books {
+		bs = append(bs, TrashedBook{Book: &books[i], DeletedAt: books[i].DeletedAt})
+	}
+	return &TrashGet{Books: bs}
+}
+
+// TrashRestore はゴミ箱から戻した本。
+type TrashRestore struct {
+	*entity.Book
+}
+
+func NewTrashRestore(book *entity.Book) *TrashRestore {
+	return &TrashRestore{Book: book}
+}
+
+type TrashDelete struct {
+	BookID int `json:"bookId"`
+}
+
+func NewTrashDelete(bookID int) *TrashDelete {
+	return &TrashDelete{BookID: bookID}
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type Review struct {
+	reviewRepo    repository.ReviewRepo
+	reviewService *service.ReviewSvc
+}
+
+func NewReview(repo repository.ReviewRepo, svc *service.ReviewSvc) *Review {
+	return &Review{
+		reviewRepo:    repo,
+		reviewService: svc,
+	}
+}
+
+func (u Review) Get(ctx context.Context, r *request.ReviewGet) (*response.ReviewGet, error) {
+	review, err := u.reviewRepo.FindByBookID(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewReviewGet(review), nil
+}
+
+func (u Review) Put(ctx context.Context, r *request.ReviewPut) (*response.ReviewPut, error) {
+	now := time.Now()
+	review := &entity.Review{
+		Rating:      r.Rating,
+		Text:        r.Text,
+		Spoiler:     r.Spoiler,
+		FinishedOn:  r.FinishedOnDate(),
+		WouldReread: r.WouldReread,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := u.reviewService.PutReview(ctx, r.BookID, review, r.Force); err != nil {
+		return nil, err
+	}
+	return response.NewReviewPut(review), nil
+}
+
+func (u Review) Delete(ctx context.Context, r *request.ReviewDelete) (*response.ReviewDelete, error) {
+	if err := u.reviewRepo.Delete(ctx, r.BookID); err != nil {
+		return nil, err
+	}
+	return response.NewReviewDelete(r.BookID), nil
+}
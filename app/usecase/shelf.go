@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// Shelf は本棚の作成・改名・削除。本を棚に入れるのは本の作成・更新（shelves）で行う。
+type Shelf struct {
+	shelfRepo repository.ShelfRepo
+}
+
+func NewShelf(repo repository.ShelfRepo) *Shelf {
+	return &Shelf{shelfRepo: repo}
+}
+
+func (u Shelf) Get(ctx context.Context, r *request.ShelfGet) (*response.ShelfGet, error) {
+	shelves, err := u.shelfRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewShelfGet(shelves), nil
+}
+
+func (u Shelf) GetByID(ctx context.Context, r *request.ShelfGetByID) (*response.ShelfGetByID, error) {
+	shelf, err := u.shelfRepo.FindByID(ctx, r.ShelfID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewShelfGetByID(shelf), nil
+}
+
+func (u Shelf) Create(ctx context.Context, r *request.ShelfCreate) (*response.ShelfCreate, error) {
+	now := time.Now()
+	shelf := &entity.Shelf{
+		Name:        r.Name,
+		Description: r.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := u.shelfRepo.Create(ctx, shelf); err != nil {
+		return nil, err
+	}
+	return response.NewShelfCreate(shelf), nil
+}
+
+func (u Shelf) Update(ctx context.Context, r *request.ShelfUpdate) (*response.ShelfUpdate, error) {
+	shelf, err := u.shelfRepo.FindByID(ctx, r.ShelfID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Name != nil {
+		shelf.Name = *r.Name
+	}
+	if r.Description != nil {
+		shelf.Description = *r.Description
+	}
+	shelf.UpdatedAt = time.Now()
+	if err := u.shelfRepo.Update(ctx, shelf); err != nil {
+		return nil, err
+	}
+	return response.NewShelfUpdate(shelf), nil
+}
+
+func (u Shelf) Delete(ctx context.Context, r *request.ShelfDelete) (*response.ShelfDelete, error) {
+	if err := u.shelfRepo.Delete(ctx, r.ShelfID); err != nil {
+		return nil, err
+	}
+	return response.NewShelfDelete(r.ShelfID), nil
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// Stats は読書の統計。集計は StatsSvc が行い、ここでは本・status の履歴・読書記録をまとめて読むだけ。
+type Stats struct {
+	bookRepo     repository.BookRepo
+	statusRepo   repository.StatusChangeRepo
+	sessionRepo  repository.ReadingSessionRepo
+	statsService *service.StatsSvc
+}
+
+func NewStats(bookRepo repository.BookRepo, statusRepo repository.StatusChangeRepo, sessionRepo repository.ReadingSessionRepo, svc *service.StatsSvc) *Stats {
+	return &Stats{
+		bookRepo:     bookRepo,
+		statusRepo:   statusRepo,
+		sessionRepo:  sessionRepo,
+		statsService: svc,
+	}
+}
+
+func (u Stats) Get(ctx context.Context, r *request.StatsGet) (*response.StatsGet, error) {
+	books, err := u.bookRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := u.statusRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.sessionRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	st, err := u.statsService.Stats(books, changes, sessions, r.From, r.To, time.Now(), r.Location)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewStatsGet(st), nil
+}
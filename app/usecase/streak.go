@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/domain/service"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+type Streak struct {
+	sessionRepo   repository.ReadingSessionRepo
+	streakService *service.StreakSvc
+}
+
+func NewStreak(sessionRepo repository.ReadingSessionRepo, svc *service.StreakSvc) *Streak {
+	return &Streak{sessionRepo: sessionRepo, streakService: svc}
+}
+
+func (u Streak) Get(ctx context.Context, r *request.StreakGet) (*response.StreakGet, error) {
+	loc := r.Location
+	if loc == nil {
+		user, _ := auth.UserFromContext(ctx)
+		loc = service.UserLocation(user)
+	}
+	sessions, err := u.sessionRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	st := u.streakService.Streak(sessions, time.Now(), loc)
+	return response.NewStreakGet(loc.String(), st), nil
+}
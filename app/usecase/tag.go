@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sora-00/booktracker-api/app/domain/entity"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// Tag はタグの作成・改名・削除。本の作成・更新（tags）で知らないタグを付けたときも自動で作られる。
+type Tag struct {
+	tagRepo repository.TagRepo
+}
+
+func NewTag(repo repository.TagRepo) *Tag {
+	return &Tag{tagRepo: repo}
+}
+
+func (u Tag) Get(ctx context.Context, r *request.TagGet) (*response.TagGet, error) {
+	tags, err := u.tagRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewTagGet(tags), nil
+}
+
+func (u Tag) GetByID(ctx context.Context, r *request.TagGetByID) (*response.TagGetByID, error) {
+	tag, err := u.tagRepo.FindByID(ctx, r.TagID)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewTagGetByID(tag), nil
+}
+
+func (u Tag) Create(ctx context.Context, r *request.TagCreate) (*response.TagCreate, error) {
+	now := time.Now()
+	tag := &entity.Tag{Name: r.Name, CreatedAt: now, UpdatedAt: now}
+	if err := u.tagRepo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+	return response.NewTagCreate(tag), nil
+}
+
+func (u Tag) Update(ctx context.Context, r *request.TagUpdate) (*response.TagUpdate, error) {
+	tag, err := u.tagRepo.FindByID(ctx, r.TagID)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name = r.Name
+	tag.UpdatedAt = time.Now()
+	if err := u.tagRepo.Update(ctx, tag); err != nil {
+		return nil, err
+	}
+	return response.NewTagUpdate(tag), nil
+}
+
+func (u Tag) Delete(ctx context.Context, r *request.TagDelete) (*response.TagDelete, error) {
+	if err := u.tagRepo.Delete(ctx, r.TagID); err != nil {
+		return nil, err
+	}
+	return response.NewTagDelete(r.TagID), nil
+}
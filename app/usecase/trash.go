@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+	"github.com/sora-00/booktracker-api/app/usecase/response"
+)
+
+// Trash はゴミ箱の本の一覧を返す。
+func (b Book) Trash(ctx context.Context, r *request.TrashGet) (*response.TrashGet, error) {
+	books, err := b.bookService.FindTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.NewTrashGet(books), nil
+}
+
+// Restore はゴミ箱の本を戻し、検索インデックスにも戻す。
+func (b Book) Restore(ctx context.Context, r *request.TrashRestore) (*response.TrashRestore, error) {
+	book, err := b.bookService.RestoreBook(ctx, r.BookID)
+	if err != nil {
+		return nil, err
+	}
+	b.indexBook(ctx, book)
+	return response.NewTrashRestore(book), nil
+}
+
+// Purge はゴミ箱の本を完全に消す（検索インデックスからはゴミ箱に入れたときに外している）。
+func (b Book) Purge(ctx context.Context, r *request.TrashDelete) (*response.TrashDelete, error) {
+	if err := b.bookService.PurgeBook(ctx, r.BookID); err != nil {
+		return nil, err
+	}
+	return response.NewTrashDelete(r.BookID), nil
+}
@@ -0,0 +1,60 @@
+// reindex は Datastore の全ユーザーの本から全文検索のインデックスを作り直す管理コマンド。
+// API サーバーは起動時に SEARCH_INDEX_PATH のスナップショットを読むので、サーバーを止めてから実行し、終わったら起動し直す。
+// スナップショットはインスタンスごとのローカルファイルなので、インスタンスが複数あればそれぞれの SEARCH_INDEX_PATH に作る。
+//
+//	go run ./cmd/reindex
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	"github.com/sora-00/booktracker-api/app/domain/repository"
+	dsclient "github.com/sora-00/booktracker-api/app/infra/datastore"
+	"github.com/sora-00/booktracker-api/app/infra/search"
+	"github.com/sora-00/booktracker-api/app/usecase"
+)
+
+func main() {
+	ctx := context.Background()
+	ds, err := dsclient.NewClient(ctx)
+	if err != nil {
+		log.Fatalf("failed to connect datastore: %v", err)
+	}
+	defer ds.Close()
+	ctx = dsclient.WithContext(ctx, ds)
+
+	path := os.Getenv("SEARCH_INDEX_PATH")
+	if path == "" {
+		path = "data/search-index.gob"
+	}
+
+	userRepo := repository.NewUserRepo()
+	bookRepo := repository.NewBookRepo()
+	users, err := userRepo.FindAll(ctx)
+	if err != nil {
+		log.Fatalf("failed to list users: %v", err)
+	}
+	docs := map[int][]search.Document{}
+	total := 0
+	for i := range users {
+		// 本はユーザーの Key の子孫なので、そのユーザーとして読む
+		books, err := bookRepo.FindAll(auth.WithUser(ctx, &users[i]))
+		if err != nil {
+			log.Fatalf("failed to list books of user %d: %v", users[i].ID, err)
+		}
+		for j := range books {
+			docs[users[i].ID] = append(docs[users[i].ID], usecase.BookSearchDocument(&books[j]))
+		}
+		total += len(books)
+	}
+
+	idx := search.NewIndex(path, 0)
+	idx.ReplaceAll(docs)
+	if err := idx.Save(); err != nil {
+		log.Fatalf("failed to save index: %v", err)
+	}
+	log.Printf("reindexed %d books of %d users into %s", total, len(users), path)
+}
@@ -5,87 +5,204 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
+	_ "time/tzdata" // ?tz= の IANA タイムゾーンを tzdata のない alpine イメージでも読めるようにする
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	"cloud.google.com/go/datastore"
 
 	"github.com/sora-00/booktracker-api/app/controller"
 	"github.com/sora-00/booktracker-api/app/domain/repository"
 	"github.com/sora-00/booktracker-api/app/domain/service"
 	dsclient "github.com/sora-00/booktracker-api/app/infra/datastore"
+	"github.com/sora-00/booktracker-api/app/infra/lookup"
+	"github.com/sora-00/booktracker-api/app/infra/notify"
+	"github.com/sora-00/booktracker-api/app/infra/search"
+	"github.com/sora-00/booktracker-api/app/infra/storage"
 	"github.com/sora-00/booktracker-api/app/usecase"
 )
 
 func main() {
 	ctx := context.Background()
-	// Cloud Datastore 接続
-	ds, err := dsclient.NewClient(ctx)
+
+	// 依存関係の注入（repository: interface + 実装。ds は middleware で context に載せる）
+	// BOOK_REPO=memory のときは Datastore に接続せずインメモリ実装で動かす（テスト・オフライン開発用）
+	var ds *datastore.Client
+	var bookRepo repository.BookRepo
+	var sessionRepo repository.ReadingSessionRepo
+	var statusRepo repository.StatusChangeRepo
+	var userRepo repository.UserRepo
+	var authTokenRepo repository.AuthTokenRepo
+	var thumbnailRepo repository.ThumbnailRepo
+	var shelfRepo repository.ShelfRepo
+	var tagRepo repository.TagRepo
+	var reviewRepo repository.ReviewRepo
+	var highlightRepo repository.HighlightRepo
+	var goalRepo repository.ReadingGoalRepo
+	var importJobRepo repository.ImportJobRepo
+	if os.Getenv("BOOK_REPO") == "memory" {
+		log.Printf("using in-memory book repository")
+		bookRepo = repository.NewMemoryBookRepo()
+		sessionRepo = repository.NewMemoryReadingSessionRepo(bookRepo)
+		statusRepo = repository.NewMemoryStatusChangeRepo(bookRepo)
+		userRepo = repository.NewMemoryUserRepo()
+		authTokenRepo = repository.NewMemoryAuthTokenRepo()
+		thumbnailRepo = repository.NewMemoryThumbnailRepo()
+		shelfRepo = repository.NewMemoryShelfRepo(bookRepo)
+		tagRepo = repository.NewMemoryTagRepo(bookRepo)
+		reviewRepo = repository.NewMemoryReviewRepo(bookRepo)
+		highlightRepo = repository.NewMemoryHighlightRepo(bookRepo)
+		goalRepo = repository.NewMemoryReadingGoalRepo()
+		importJobRepo = repository.NewMemoryImportJobRepo()
+	} else {
+		// Cloud Datastore 接続
+		var err error
+		ds, err = dsclient.NewClient(ctx)
+		if err != nil {
+			log.Fatalf("failed to connect datastore: %v", err)
+		}
+		defer ds.Close()
+		bookRepo = repository.NewBookRepo()
+		sessionRepo = repository.NewReadingSessionRepo()
+		statusRepo = repository.NewStatusChangeRepo()
+		userRepo = repository.NewUserRepo()
+		authTokenRepo = repository.NewAuthTokenRepo()
+		thumbnailRepo = repository.NewThumbnailRepo()
+		shelfRepo = repository.NewShelfRepo()
+		tagRepo = repository.NewTagRepo()
+		reviewRepo = repository.NewReviewRepo()
+		highlightRepo = repository.NewHighlightRepo()
+		goalRepo = repository.NewReadingGoalRepo()
+		importJobRepo = repository.NewImportJobRepo()
+	}
+
+	// 表紙画像の保存先（THUMBNAIL_STORE=local / s3）
+	thumbnailStore, err := storage.NewThumbnailStore(ctx)
 	if err != nil {
-		log.Fatalf("failed to connect datastore: %v", err)
+		log.Fatalf("failed to set up thumbnail store: %v", err)
 	}
-	defer ds.Close()
 
-	// 依存関係の注入（repository: interface + 実装。ds は middleware で context に載せる）
-	bookRepo := repository.NewBookRepo()
+	// 本の全文検索インデックス。SEARCH_INDEX_PATH にスナップショットを書き、起動時に読み込む
+	// （なければ各ユーザーの初回検索で作る。作り直すときは go run ./cmd/reindex）
+	// インデックスはインスタンスごとのメモリとローカルファイルなので、ほかのインスタンスでの本の変更は見えない。
+	// そのため SEARCH_INDEX_MAX_AGE を過ぎたユーザーのインデックスは、次の検索で Datastore から作り直す
+	// （インスタンスが1つだけなら長くしてよい）
+	// インメモリ実装のときは本もプロセスと一緒に消え、ほかのインスタンスとも共有しないので、書かず作り直さない
+	searchIndexPath := ""
+	var searchIndexMaxAge time.Duration
+	if ds != nil {
+		searchIndexPath = os.Getenv("SEARCH_INDEX_PATH")
+		if searchIndexPath == "" {
+			searchIndexPath = "data/search-index.gob"
+		}
+		searchIndexMaxAge = durationEnv("SEARCH_INDEX_MAX_AGE", time.Minute)
+	}
+	searchIndex := search.NewIndex(searchIndexPath, searchIndexMaxAge)
+	if err := searchIndex.Load(); err != nil {
+		log.Printf("failed to load search index (rebuilt on first search): %v", err)
+	}
+	go searchIndex.RunAutoSave(ctx, 10*time.Second)
+
+	// リマインダーの送り先（REMINDER_NOTIFIER=log / webhook / smtp）
+	notifier, err := notify.NewNotifier()
+	if err != nil {
+		log.Fatalf("failed to set up notifier: %v", err)
+	}
+
+	// ISBN から書誌情報を引く外部サービス（METADATA_PROVIDERS=openlibrary,googlebooks）
+	metadataProvider, err := lookup.NewMetadataProvider()
+	if err != nil {
+		log.Fatalf("failed to set up metadata provider: %v", err)
+	}
 
 	// domain層（ビジネスロジック）
-	bookService := service.NewService(bookRepo)
+	thumbnailService := service.NewThumbnailService(thumbnailRepo, thumbnailStore)
+	labelService := service.NewLabelService(shelfRepo, tagRepo)
+	// REQUIRE_IF_MATCH=true なら本の PUT / DELETE に If-Match を必須にする（ないと 428）
+	bookService := service.NewService(bookRepo, sessionRepo, statusRepo, thumbnailService, labelService, os.Getenv("REQUIRE_IF_MATCH") == "true")
+	forecastService := service.NewForecastService()
+	reviewService := service.NewReviewService(reviewRepo, statusRepo)
+	highlightService := service.NewHighlightService(highlightRepo)
+	goalService := service.NewGoalService()
+	statsService := service.NewStatsService()
+	streakService := service.NewStreakService()
+	lookupService := service.NewLookupService(metadataProvider, thumbnailService)
+	reminderService := service.NewReminderService(userRepo, bookRepo, sessionRepo, notifier, intEnv("REMINDER_HOUR", 21))
+	authService := service.NewAuthService(userRepo, authTokenRepo)
+	importService := service.NewImportService(bookService, reviewService, importJobRepo)
 
 	// usecase層（アプリケーションロジック）
-	book := usecase.NewBook(bookRepo, bookService)
+	authUsecase := usecase.NewAuth(authService)
+	book := usecase.NewBook(bookRepo, bookService, reviewService, importService, searchIndex)
+	readingSession := usecase.NewReadingSession(sessionRepo, bookService)
+	bookStatus := usecase.NewBookStatus(statusRepo, bookService)
+	bookForecast := usecase.NewBookForecast(bookRepo, sessionRepo, forecastService)
+	shelf := usecase.NewShelf(shelfRepo)
+	tag := usecase.NewTag(tagRepo)
+	review := usecase.NewReview(reviewRepo, reviewService)
+	highlight := usecase.NewHighlight(highlightRepo, bookRepo, highlightService)
+	readingGoal := usecase.NewReadingGoal(goalRepo, statusRepo, sessionRepo, goalService)
+	stats := usecase.NewStats(bookRepo, statusRepo, sessionRepo, statsService)
+	streak := usecase.NewStreak(sessionRepo, streakService)
+	isbnLookup := usecase.NewISBNLookup(lookupService)
 
 	// controller層（HTTPハンドラ）
+	authController := controller.NewAuthController(authUsecase)
 	bookController := controller.NewBookController(book)
-	bookThumbnailController := controller.NewBookThumbnailController()
+	bookThumbnailController := controller.NewBookThumbnailController(thumbnailStore, thumbnailService)
+	readingSessionController := controller.NewReadingSessionController(readingSession)
+	bookStatusController := controller.NewBookStatusController(bookStatus)
+	bookForecastController := controller.NewBookForecastController(bookForecast)
+	shelfController := controller.NewShelfController(shelf)
+	tagController := controller.NewTagController(tag)
+	reviewController := controller.NewReviewController(review)
+	highlightController := controller.NewHighlightController(highlight)
+	readingGoalController := controller.NewReadingGoalController(readingGoal)
+	statsController := controller.NewStatsController(stats)
+	streakController := controller.NewStreakController(streak)
+	isbnLookupController := controller.NewISBNLookupController(isbnLookup)
+	openAPIController := controller.NewOpenAPIController()
+
+	// 本に付けられないままの表紙画像を定期的に消す（THUMBNAIL_GC_GRACE: アップロードからの猶予、THUMBNAIL_GC_INTERVAL: 実行間隔）
+	gcGrace := durationEnv("THUMBNAIL_GC_GRACE", 24*time.Hour)
+	gcInterval := durationEnv("THUMBNAIL_GC_INTERVAL", time.Hour)
+	gcCtx := ctx
+	if ds != nil {
+		gcCtx = dsclient.WithContext(ctx, ds)
+	}
+	go thumbnailService.RunGC(gcCtx, gcInterval, gcGrace)
+
+	// 目標ページ数/日に届いていない本のリマインダー（REMINDER_HOUR: ユーザーのタイムゾーンで何時以降に送るか、REMINDER_INTERVAL: 確かめる間隔）
+	go reminderService.RunReminders(gcCtx, durationEnv("REMINDER_INTERVAL", 10*time.Minute))
+
+	// ゴミ箱の本を期限が過ぎたら完全に消す（TRASH_RETENTION_DAYS: ゴミ箱に残す日数、TRASH_PURGE_INTERVAL: 実行間隔）
+	trashRetention := time.Duration(intEnv("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+	go bookService.RunTrashPurge(gcCtx, durationEnv("TRASH_PURGE_INTERVAL", time.Hour), trashRetention)
 
 	// ルーティング設定
-	r := chi.NewRouter()
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	// 各リクエストの context に Datastore クライアントを入れる（repository で FromContext する前提）
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := dsclient.WithContext(r.Context(), ds)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
-	})
-
-	// 404/405を可視化
-	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("404 Not Found: %s %s", r.Method, r.URL.Path)
-		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-	})
-	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("405 Method Not Allowed: %s %s", r.Method, r.URL.Path)
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-	})
-
-	// GET / … ルートは 200 で返す（ブラウザで開いても 404 にしない）
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"ok":true,"message":"BookTracker API"}`))
-	})
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("ok"))
-	})
-	// ブラウザが自動で叩く favicon は 204 で返して 404 ログを出さない
-	r.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusNoContent)
-	})
-
-	// /api/books（末尾なし）も直に受ける
-	r.Route("/api", func(r chi.Router) {
-		r.Route("/books", func(r chi.Router) {
-			// 本の表紙画像アップロード（/{id} より前に登録すること）
-			r.Post("/thumbnails", bookThumbnailController.PostThumbnail)
-			r.Get("/thumbnails/{id}", bookThumbnailController.GetThumbnail)
-			r.Get("/", bookController.GetBooks)
-			r.Get("/{id}", bookController.GetBookByID)
-			r.Post("/", bookController.CreateBook)
-			r.Put("/{id}", bookController.UpdateBook)
-			r.Delete("/{id}", bookController.DeleteBook)
-		})
-	})
+	r := newRouter(handlers{
+		authUsecase:    authUsecase,
+		auth:           authController,
+		book:           bookController,
+		bookThumbnail:  bookThumbnailController,
+		readingSession: readingSessionController,
+		bookStatus:     bookStatusController,
+		bookForecast:   bookForecastController,
+		shelf:          shelfController,
+		tag:            tagController,
+		review:         reviewController,
+		highlight:      highlightController,
+		readingGoal:    readingGoalController,
+		stats:          statsController,
+		streak:         streakController,
+		isbnLookup:     isbnLookupController,
+		openAPI:        openAPIController,
+	}, ds)
+
+	// 仕様に書いていないルート・仕様にしかないルートがあれば知らせる
+	for _, d := range openAPIController.CheckRoutes(r) {
+		log.Printf("openapi: %s", d)
+	}
 
 	// サーバー起動
 	port := os.Getenv("PORT")
@@ -98,3 +215,29 @@ func main() {
 		log.Fatalf("server failed: %v", err)
 	}
 }
+
+// durationEnv は環境変数を time.ParseDuration の形式（例: 24h, 30m）で読む。未設定なら def。
+func durationEnv(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("invalid %s: %q", name, v)
+	}
+	return d
+}
+
+// intEnv は環境変数を整数で読む。未設定なら def。
+func intEnv(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %q", name, v)
+	}
+	return n
+}
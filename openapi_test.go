@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sora-00/booktracker-api/app/controller"
+)
+
+// TestRoutesMatchOpenAPI は登録したルートと /openapi.json のルートが食い違っていれば落ちる。
+// ルートを組み立てるだけでハンドラは呼ばないので、controller は中身のないままでよい。
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	openAPI := controller.NewOpenAPIController()
+	r := newRouter(handlers{openAPI: openAPI}, nil)
+	if diff := openAPI.CheckRoutes(r); len(diff) > 0 {
+		for _, d := range diff {
+			t.Error(d)
+		}
+		t.Fatal("routes and apiOperations in app/controller/openapi.go do not match")
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"cloud.google.com/go/datastore"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/sora-00/booktracker-api/app/controller"
+	"github.com/sora-00/booktracker-api/app/domain/auth"
+	dsclient "github.com/sora-00/booktracker-api/app/infra/datastore"
+	"github.com/sora-00/booktracker-api/app/usecase"
+	"github.com/sora-00/booktracker-api/app/usecase/request"
+)
+
+// handlers は newRouter でルートに割り当てる HTTP ハンドラ。
+type handlers struct {
+	authUsecase    *usecase.Auth // ログイン必須のルートで Bearer トークンのユーザーを引く
+	auth           *controller.AuthController
+	book           *controller.BookController
+	bookThumbnail  *controller.BookThumbnailController
+	readingSession *controller.ReadingSessionController
+	bookStatus     *controller.BookStatusController
+	bookForecast   *controller.BookForecastController
+	shelf          *controller.ShelfController
+	tag            *controller.TagController
+	review         *controller.ReviewController
+	highlight      *controller.HighlightController
+	readingGoal    *controller.ReadingGoalController
+	stats          *controller.StatsController
+	streak         *controller.StreakController
+	isbnLookup     *controller.ISBNLookupController
+	openAPI        *controller.OpenAPIController
+}
+
+// newRouter はルーティングを組み立てる。ds が nil（インメモリ実装）なら context に Datastore クライアントを入れない。
+// ルートを追加・変更したら controller の apiOperations も直すこと（openapi_test.go で食い違いを見る）。
+func newRouter(h handlers, ds *datastore.Client) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+	// 各リクエストの context に Datastore クライアントを入れる（repository で FromContext する前提）
+	if ds != nil {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := dsclient.WithContext(r.Context(), ds)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		})
+	}
+
+	// ログイン必須のルートで使う。Authorization: Bearer <token> のユーザーを context に入れる（repository で本の持ち主に使う）
+	requireLogin := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := h.authUsecase.Authenticate(r.Context(), request.BearerToken(r))
+			if err != nil {
+				// ErrUnauthenticated は WWW-Authenticate 付きの 401
+				controller.WriteError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		})
+	}
+
+	// 404/405を可視化
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("404 Not Found: %s %s", r.Method, r.URL.Path)
+		controller.WriteProblem(w, r, http.StatusNotFound, controller.CodeRouteNotFound, "no route for "+r.Method+" "+r.URL.Path)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("405 Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		controller.WriteProblem(w, r, http.StatusMethodNotAllowed, controller.CodeMethodNotAllowed, r.Method+" is not allowed for "+r.URL.Path)
+	})
+
+	// GET / … ルートは 200 で返す（ブラウザで開いても 404 にしない）
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"message":"BookTracker API"}`))
+	})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	// ブラウザが自動で叩く favicon は 204 で返して 404 ログを出さない
+	r.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	// API の仕様（request / response の構造体から作った OpenAPI 3.1）と閲覧ページ
+	r.Get("/openapi.json", h.openAPI.GetSpec)
+	r.Get("/docs", h.openAPI.GetDocs)
+
+	// /api/books（末尾なし）も直に受ける
+	r.Route("/api", func(r chi.Router) {
+		// ユーザー登録・ログイン
+		r.Route("/auth", func(r chi.Router) {
+			r.Post("/signup", h.auth.SignUp)
+			r.Post("/login", h.auth.Login)
+			r.With(requireLogin).Post("/logout", h.auth.Logout)
+			r.With(requireLogin).Get("/me", h.auth.Me)
+			r.With(requireLogin).Put("/me", h.auth.UpdateMe)
+		})
+
+		// 本の一括登録・更新・削除（1件ずつの API と同じ扱いで、項目ごとの結果を返す）
+		r.With(requireLogin).Post("/books:batchCreate", h.book.BatchCreateBooks)
+		r.With(requireLogin).Post("/books:batchUpdate", h.book.BatchUpdateBooks)
+		r.With(requireLogin).Post("/books:batchDelete", h.book.BatchDeleteBooks)
+
+		r.Route("/books", func(r chi.Router) {
+			// 表紙画像は <img> から直接読まれるので配信だけはログイン不要
+			r.Get("/thumbnails/{id}", h.bookThumbnail.GetThumbnail)
+
+			// ここから下はログイン必須。本はログイン中のユーザーのものだけが見える
+			r.Group(func(r chi.Router) {
+				r.Use(requireLogin)
+				// 本の表紙画像アップロード（/{id} より前に登録すること）
+				r.Post("/thumbnails", h.bookThumbnail.PostThumbnail)
+				r.Get("/", h.book.GetBooks)
+				// 全文検索（title / author / publisher / encounterNote）
+				r.Get("/search", h.book.SearchBooks)
+				r.Get("/{id}", h.book.GetBookByID)
+				r.Post("/", h.book.CreateBook)
+				// 重複して登録した本を1冊にまとめる（/{id} より前に登録すること）
+				r.Post("/merge", h.book.MergeBooks)
+				r.Put("/{id}", h.book.UpdateBook)
+				// 部分更新（merge-patch / json-patch）
+				r.Patch("/{id}", h.book.PatchBook)
+				r.Delete("/{id}", h.book.DeleteBook)
+				// 読書記録（追加・削除で readPages と status を再計算する）
+				r.Get("/{id}/sessions", h.readingSession.GetSessions)
+				r.Post("/{id}/sessions", h.readingSession.CreateSession)
+				r.Delete("/{id}/sessions/{sessionId}", h.readingSession.DeleteSession)
+				// status の遷移（start / pause / finish / abandon / reread）と履歴
+				r.Post("/{id}/status", h.bookStatus.ChangeStatus)
+				r.Get("/{id}/status-history", h.bookStatus.GetStatusHistory)
+				// 目標日・目標ページ数/日からの読了見込み（?tz= で「今日」のタイムゾーンを指定）
+				r.Get("/{id}/forecast", h.bookForecast.GetForecast)
+				// 読み終えた本のレビュー（1冊に1つ。?force=true で completed でない本にも書ける）
+				r.Get("/{id}/review", h.review.GetReview)
+				r.Put("/{id}/review", h.review.PutReview)
+				r.Delete("/{id}/review", h.review.DeleteReview)
+				// ハイライト（引用）
+				r.Get("/{id}/highlights", h.highlight.GetHighlights)
+				r.Post("/{id}/highlights", h.highlight.CreateHighlight)
+				r.Get("/{id}/highlights/{highlightId}", h.highlight.GetHighlightByID)
+				r.Put("/{id}/highlights/{highlightId}", h.highlight.UpdateHighlight)
+				r.Delete("/{id}/highlights/{highlightId}", h.highlight.DeleteHighlight)
+			})
+		})
+
+		// 本をまたいだハイライトの一覧と、日替わりで見返す1件
+		r.Route("/highlights", func(r chi.Router) {
+			r.Use(requireLogin)
+			r.Get("/", h.highlight.GetHighlightFeed)
+			r.Get("/daily", h.highlight.GetDailyHighlight)
+		})
+
+		// ゴミ箱。DELETE /api/books/{id} で入れた本を戻す・完全に消す
+		r.Route("/trash", func(r chi.Router) {
+			r.Use(requireLogin)
+			r.Get("/", h.book.GetTrash)
+			r.Post("/{id}/restore", h.book.RestoreBook)
+			r.Delete("/{id}", h.book.PurgeBook)
+		})
+
+		// CSV（Goodreads のエクスポート・列を指定した CSV）の取り込み。取り込みは非同期で、進み具合は jobs/{id} で見る
+		r.Route("/import", func(r chi.Router) {
+			r.Use(requireLogin)
+			r.Post("/csv", h.book.ImportCSV)
+			r.Get("/jobs/{id}", h.book.GetImportJob)
+		})
+
+		// 本棚とタグ。本を入れる・付けるのは本の作成・更新で行い、改名・削除は本の側にも反映する
+		r.Route("/shelves", func(r chi.Router) {
+			r.Use(requireLogin)
+			r.Get("/", h.shelf.GetShelves)
+			r.Post("/", h.shelf.CreateShelf)
+			r.Get("/{id}", h.shelf.GetShelfByID)
+			r.Put("/{id}", h.shelf.UpdateShelf)
+			r.Delete("/{id}", h.shelf.DeleteShelf)
+		})
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(requireLogin)
+			r.Get("/", h.tag.GetTags)
+			r.Post("/", h.tag.CreateTag)
+			r.Get("/{id}", h.tag.GetTagByID)
+			r.Put("/{id}", h.tag.UpdateTag)
+			r.Delete("/{id}", h.tag.DeleteTag)
+		})
+
+		// 読書目標。進み具合は読了の履歴（books）・読書記録（pages）から数える
+		r.Route("/goals", func(r chi.Router) {
+			r.Use(requireLogin)
+			r.Get("/", h.readingGoal.GetGoals)
+			r.Post("/", h.readingGoal.CreateGoal)
+			r.Get("/{id}", h.readingGoal.GetGoalByID)
+			r.Put("/{id}", h.readingGoal.UpdateGoal)
+			r.Delete("/{id}", h.readingGoal.DeleteGoal)
+			r.Get("/{id}/progress", h.readingGoal.GetGoalProgress)
+		})
+
+		// 読書の統計（本・status の履歴・読書記録から期間を決めて集計する）
+		r.With(requireLogin).Get("/stats", h.stats.GetStats)
+		r.With(requireLogin).Get("/streak", h.streak.GetStreak)
+
+		// ISBN から本の作成フォームを埋める（?cover=true で表紙画像も取り込む）
+		r.With(requireLogin).Get("/lookup/isbn/{isbn}", h.isbnLookup.GetISBN)
+	})
+	return r
+}